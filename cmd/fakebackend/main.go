@@ -0,0 +1,31 @@
+// Command fakebackend runs a local stand-in of the pets, treatments, users and notifications services, so the
+// bot can run fully offline. Start it and run the bot with REQUESTER_ENV=local
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"telegram-bot/internal/fakebackend"
+)
+
+func main() {
+	port := flag.Int("port", 8090, "port where the fake services listen")
+	owner := flag.String("owner", "", "telegram ID of the owner whose sample pets, treatments and vaccines are seeded")
+	autoRegister := flag.Bool("auto-register", true, "register any telegram user that is requested to the users service")
+	flag.Parse()
+
+	store := fakebackend.NewStore()
+	store.SetAutoRegister(*autoRegister)
+	if *owner != "" {
+		store.Seed(*owner)
+	}
+
+	address := fmt.Sprintf(":%d", *port)
+	logrus.Infof("fake backend listening on %s", address)
+	err := http.ListenAndServe(address, fakebackend.NewHandler(store))
+	if err != nil {
+		logrus.Errorf("fake backend stopped: %v", err)
+	}
+}
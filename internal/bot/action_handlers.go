@@ -8,7 +8,11 @@ import (
 	"time"
 )
 
-const editTTL = 3 * time.Minute
+const (
+	editTTL = 3 * time.Minute
+
+	unknownInputMessage = "I don't understand your input, execute /help to check what can I do for you"
+)
 
 // help sends the commands that botName supports
 func (tb *TelegramBot) help(c tele.Context) error {
@@ -30,6 +34,12 @@ func (tb *TelegramBot) editMessageHandler(c tele.Context) error {
 // textHandler handles text input from the user. If the text contains a specific endpoint, it forwards the message to the corresponding handler
 func (tb *TelegramBot) textHandler(c tele.Context) error {
 	message := c.Message().Text
+	if c.Sender() != nil && !strings.HasPrefix(message, "/") {
+		if treatmentID, found := tb.pendingComments.pop(c.Sender().ID); found {
+			return tb.addTreatmentComment(c, treatmentID)
+		}
+	}
+
 	if strings.Contains(message, registerPetEndpoint) {
 		return tb.createPetRecord(c)
 	}
@@ -38,5 +48,16 @@ func (tb *TelegramBot) textHandler(c tele.Context) error {
 		return tb.registerNotification(c)
 	}
 
-	return c.Send("I don't understand your input, execute /help to check what can I do for you")
+	return c.Send(unknownInputMessage)
+}
+
+// photoHandler handles photos sent by the user. Photos are only expected as part of a treatment comment
+func (tb *TelegramBot) photoHandler(c tele.Context) error {
+	if c.Sender() != nil {
+		if treatmentID, found := tb.pendingComments.pop(c.Sender().ID); found {
+			return tb.addTreatmentComment(c, treatmentID)
+		}
+	}
+
+	return c.Send("Nice pic! But I don't know what to do with it, execute /help to check what can I do for you")
 }
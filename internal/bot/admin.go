@@ -0,0 +1,224 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	tele "gopkg.in/telebot.v3"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"telegram-bot/internal/health"
+	"telegram-bot/internal/utils/formatter"
+	"time"
+)
+
+// AdminIDsEnv comma separated telegram IDs of the users allowed to execute the admin commands
+const AdminIDsEnv = "ADMIN_TELEGRAM_IDS"
+
+var errInvalidAdminID = errors.New("error invalid admin telegram ID")
+
+// AdminBroadcaster queues a broadcast to all the users and returns its ID
+type AdminBroadcaster interface {
+	BroadcastToAll(message string) (string, error)
+}
+
+// HealthReporter checks the dependencies of telegramer, see health.Checker
+type HealthReporter interface {
+	Ready(ctx context.Context) health.Report
+}
+
+// AdminConfig users allowed to execute the admin commands and the dependencies of the commands. Without
+// Broadcaster or Health, their commands answer that they are not available
+type AdminConfig struct {
+	TelegramIDs []int64
+	Broadcaster AdminBroadcaster
+	Health      HealthReporter
+}
+
+// AdminIDsFromEnv returns the telegram IDs of AdminIDsEnv, none if it is not set
+func AdminIDsFromEnv() ([]int64, error) {
+	var adminIDs []int64
+	for _, value := range strings.Split(os.Getenv(AdminIDsEnv), ",") {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+
+		telegramID, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", errInvalidAdminID, value)
+		}
+		adminIDs = append(adminIDs, telegramID)
+	}
+
+	return adminIDs, nil
+}
+
+// SetAdmin enables the admin commands for the given users. Must be called before StartBot
+func (tb *TelegramBot) SetAdmin(config AdminConfig) {
+	tb.admin = config
+}
+
+func (tb *TelegramBot) isAdmin(telegramID int64) bool {
+	for _, adminID := range tb.admin.TelegramIDs {
+		if adminID == telegramID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// adminOnly middleware of the admin commands. The other users get the same answer as for an unknown command,
+// so the admin commands are not disclosed
+func (tb *TelegramBot) adminOnly(next tele.HandlerFunc) tele.HandlerFunc {
+	return func(c tele.Context) error {
+		if c.Sender() == nil || !tb.isAdmin(c.Sender().ID) {
+			logger(c).Warn("admin command rejected: user is not an admin")
+			return c.Send(unknownInputMessage)
+		}
+
+		logger(c).Infof("admin command executed: %s", c.Text())
+		return next(c)
+	}
+}
+
+// adminStats sends the known users and the updates handled in the last days, with the failure rate per command
+// of today
+func (tb *TelegramBot) adminStats(c tele.Context) error {
+	message := fmt.Sprintf("*Known users:* %d\n\n", len(tb.knownUsers.list()))
+
+	days := tb.usageStats.lastDays()
+	if len(days) == 0 {
+		return c.Send(message + "No updates handled yet")
+	}
+
+	var dayLines []string
+	for _, day := range days {
+		dayLines = append(dayLines, fmt.Sprintf("%s: %s", day.Day, usageText(day.handlerUsage)))
+	}
+	message += fmt.Sprintf("*Updates per day*\n%s", formatter.UnorderedList(dayLines))
+
+	today := days[0]
+	handlers := make([]string, 0, len(today.Handlers))
+	for handler := range today.Handlers {
+		handlers = append(handlers, handler)
+	}
+	sort.Slice(handlers, func(i, j int) bool {
+		if today.Handlers[handlers[i]].Handled != today.Handlers[handlers[j]].Handled {
+			return today.Handlers[handlers[i]].Handled > today.Handlers[handlers[j]].Handled
+		}
+		return handlers[i] < handlers[j]
+	})
+
+	var handlerLines []string
+	for _, handler := range handlers {
+		handlerLines = append(handlerLines, fmt.Sprintf("%s: %s", formatter.EscapeMarkdown(handler), usageText(today.Handlers[handler])))
+	}
+	message += fmt.Sprintf("*Updates of %s*\n%s", today.Day, formatter.UnorderedList(handlerLines))
+
+	return c.Send(message)
+}
+
+func usageText(usage handlerUsage) string {
+	return fmt.Sprintf("%d, %.1f%% failed", usage.Handled, usage.failureRate()*100)
+}
+
+// adminBroadcast queues a broadcast of the message after the command to all the users
+func (tb *TelegramBot) adminBroadcast(c tele.Context) error {
+	message := strings.TrimSpace(c.Message().Payload)
+	if message == "" {
+		return c.Send(formatter.EscapeMarkdown(fmt.Sprintf("Usage: %s <message>", adminBroadcastEndpoint)))
+	}
+
+	if tb.admin.Broadcaster == nil {
+		return c.Send("Broadcasts are not available")
+	}
+
+	broadcastID, err := tb.admin.Broadcaster.BroadcastToAll(message)
+	if err != nil {
+		logger(c).Errorf("error queueing broadcast: %v", err)
+		return c.Send(formatter.EscapeMarkdown(fmt.Sprintf("The broadcast could not be queued: %v", err)))
+	}
+
+	return c.Send(fmt.Sprintf("Broadcast queued for all the users, its ID is `%s`", broadcastID))
+}
+
+// adminUser sends the information and the pets of the user whose telegram ID is after the command
+func (tb *TelegramBot) adminUser(c tele.Context) error {
+	telegramID, err := strconv.ParseInt(strings.TrimSpace(c.Message().Payload), 10, 64)
+	if err != nil {
+		return c.Send(formatter.EscapeMarkdown(fmt.Sprintf("Usage: %s <telegram ID>", adminUserEndpoint)))
+	}
+
+	ctx := requestContext(c)
+	message := fmt.Sprintf("*User %d*\nKnown by the bot: %t\n", telegramID, tb.knownUsers.contains(telegramID))
+
+	userInfo, err := tb.users.GetUserData(ctx, telegramID)
+	switch {
+	case isNotFound(err):
+		return c.Send(message + "Not registered")
+	case err != nil:
+		logger(c).Errorf("error fetching user %d: %v", telegramID, err)
+		return c.Send(message + formatter.EscapeMarkdown(fmt.Sprintf("Error fetching the user: %v", err)))
+	}
+
+	message += formatter.EscapeMarkdown(fmt.Sprintf(
+		"ID: %s\nName: %s\nEmail: %s\nCity: %s\n\n",
+		userInfo.UserID,
+		userInfo.FullName,
+		userInfo.Email,
+		userInfo.City,
+	))
+
+	pets, err := tb.pets.GetPetsByOwnerID(ctx, telegramID)
+	switch {
+	case isNotFound(err):
+		return c.Send(message + "No pets registered")
+	case err != nil:
+		logger(c).Errorf("error fetching pets of user %d: %v", telegramID, err)
+		return c.Send(message + formatter.EscapeMarkdown(fmt.Sprintf("Error fetching the pets: %v", err)))
+	}
+
+	var petLines []string
+	for _, pet := range pets {
+		petLines = append(petLines, formatter.EscapeMarkdown(fmt.Sprintf(
+			"%s (%s), ID %d, born %s",
+			pet.Name,
+			pet.Type,
+			pet.ID,
+			pet.BirthDate.Format(time.DateOnly),
+		)))
+	}
+
+	return c.Send(message + fmt.Sprintf("*Pets*\n%s", formatter.UnorderedList(petLines)))
+}
+
+// adminHealth sends the status of each dependency, as the readiness probe
+func (tb *TelegramBot) adminHealth(c tele.Context) error {
+	if tb.admin.Health == nil {
+		return c.Send("Health checks are not available")
+	}
+
+	report := tb.admin.Health.Ready(requestContext(c))
+
+	checks := make([]string, 0, len(report.Checks))
+	for check := range report.Checks {
+		checks = append(checks, check)
+	}
+	sort.Strings(checks)
+
+	var checkLines []string
+	for _, check := range checks {
+		result := report.Checks[check]
+		line := fmt.Sprintf("%s: %s", check, result.Status)
+		if result.Error != "" {
+			line += fmt.Sprintf(" (%s)", result.Error)
+		}
+		checkLines = append(checkLines, formatter.EscapeMarkdown(line))
+	}
+
+	return c.Send(fmt.Sprintf("*Status:* %s\n\n%s", report.Status, formatter.UnorderedList(checkLines)))
+}
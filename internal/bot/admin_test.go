@@ -0,0 +1,237 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"telegram-bot/internal/bot/internal/mock"
+	"telegram-bot/internal/domain"
+	"telegram-bot/internal/health"
+	"telegram-bot/internal/requester"
+	"testing"
+	"time"
+)
+
+type fakeBroadcaster struct {
+	messages []string
+	err      error
+}
+
+func (fb *fakeBroadcaster) BroadcastToAll(message string) (string, error) {
+	if fb.err != nil {
+		return "", fb.err
+	}
+
+	fb.messages = append(fb.messages, message)
+	return "69", nil
+}
+
+type fakeHealthReporter health.Report
+
+func (fhr fakeHealthReporter) Ready(context.Context) health.Report {
+	return health.Report(fhr)
+}
+
+func TestAdminIDsFromEnv(t *testing.T) {
+	t.Setenv(AdminIDsEnv, "")
+	adminIDs, err := AdminIDsFromEnv()
+	require.NoError(t, err)
+	assert.Empty(t, adminIDs)
+
+	t.Setenv(AdminIDsEnv, "911, 420,")
+	adminIDs, err = AdminIDsFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, []int64{911, 420}, adminIDs)
+
+	t.Setenv(AdminIDsEnv, "911,lionel")
+	_, err = AdminIDsFromEnv()
+	assert.ErrorIs(t, err, errInvalidAdminID)
+}
+
+func TestAdminCommandsAreOnlyForAdmins(t *testing.T) {
+	telegramBot, telegram := startConversationTest(t, Requesters{})
+	telegramBot.SetAdmin(AdminConfig{TelegramIDs: []int64{registeredUser.ID}})
+
+	for _, command := range []string{adminStatsEndpoint, adminBroadcastEndpoint + " Hi", adminUserEndpoint + " 911", adminHealthEndpoint} {
+		telegram.SendText(unregisteredUser, command)
+
+		message := nextMessage(t, telegram)
+		assert.Equal(t, unknownInputMessage, message.Text)
+	}
+}
+
+func TestAdminStats(t *testing.T) {
+	telegramBot, telegram := startConversationTest(t, Requesters{})
+	telegramBot.SetAdmin(AdminConfig{TelegramIDs: []int64{registeredUser.ID}})
+
+	telegram.SendText(unregisteredUser, helpEndpoint)
+	nextMessage(t, telegram)
+	for _, junkCommand := range []string{"/dQw4w9WgXcQ", "/sudo rm -rf"} {
+		telegram.SendText(unregisteredUser, junkCommand)
+		nextMessage(t, telegram)
+	}
+	// The updates are recorded after their handler returns, so they may not be recorded yet when their reply is received
+	require.Eventually(t, func() bool {
+		days := telegramBot.usageStats.lastDays()
+		return len(days) > 0 && days[0].Handled == 3
+	}, replyTimeout, 10*time.Millisecond)
+
+	telegram.SendText(registeredUser, adminStatsEndpoint)
+	message := nextMessage(t, telegram)
+	assert.Contains(t, message.Text, "*Known users:* 2")
+	assert.Contains(t, message.Text, fmt.Sprintf("%s: 3, 0.0%% failed", time.Now().Format(time.DateOnly)))
+	assert.Contains(t, message.Text, "/help: 1, 0.0% failed")
+	assert.Contains(t, message.Text, `unknown\_command: 2, 0.0% failed`)
+	assert.NotContains(t, message.Text, "dQw4w9WgXcQ")
+}
+
+func TestAdminBroadcast(t *testing.T) {
+	testCases := []struct {
+		Name             string
+		Command          string
+		Broadcaster      *fakeBroadcaster
+		ExpectedMessage  string
+		ExpectedMessages []string
+	}{
+		{
+			Name:             "Broadcast is queued",
+			Command:          adminBroadcastEndpoint + " *Pet Place* is turning 1!",
+			Broadcaster:      &fakeBroadcaster{},
+			ExpectedMessage:  "Broadcast queued for all the users, its ID is `69`",
+			ExpectedMessages: []string{"*Pet Place* is turning 1!"},
+		},
+		{
+			Name:            "Missing message",
+			Command:         adminBroadcastEndpoint,
+			Broadcaster:     &fakeBroadcaster{},
+			ExpectedMessage: `Usage: /admin\_broadcast <message>`,
+		},
+		{
+			Name:            "Broadcast cannot be queued",
+			Command:         adminBroadcastEndpoint + " Hi",
+			Broadcaster:     &fakeBroadcaster{err: errors.New("queue is full")},
+			ExpectedMessage: "The broadcast could not be queued: queue is full",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			telegramBot, telegram := startConversationTest(t, Requesters{})
+			telegramBot.SetAdmin(AdminConfig{
+				TelegramIDs: []int64{registeredUser.ID},
+				Broadcaster: testCase.Broadcaster,
+			})
+
+			telegram.SendText(registeredUser, testCase.Command)
+
+			message := nextMessage(t, telegram)
+			assert.Equal(t, testCase.ExpectedMessage, message.Text)
+			assert.Equal(t, testCase.ExpectedMessages, testCase.Broadcaster.messages)
+		})
+	}
+}
+
+func TestAdminUser(t *testing.T) {
+	notFoundErr := requester.NewRequestError(fmt.Errorf("not found"), http.StatusNotFound, "")
+	userInfo := domain.UserInfo{UserID: "69", FullName: "Lionel Andrés", Email: "lionel_messi@pet.place", City: "Rosario"}
+
+	testCases := []struct {
+		Name             string
+		Command          string
+		SetRequesters    func(usersMock *mock.MockUsersRequester, petsMock *mock.MockPetsRequester)
+		ExpectedContains []string
+	}{
+		{
+			Name:    "User with pets",
+			Command: adminUserEndpoint + " 911",
+			SetRequesters: func(usersMock *mock.MockUsersRequester, petsMock *mock.MockPetsRequester) {
+				usersMock.EXPECT().GetUserData(gomock.Any(), int64(911)).Return(userInfo, nil)
+				petsMock.EXPECT().GetPetsByOwnerID(gomock.Any(), int64(911)).Return([]domain.PetData{
+					{
+						PetDataIdentifier: domain.PetDataIdentifier{ID: 1, Name: "Bachicha", Type: "dog"},
+						BirthDate:         time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC),
+					},
+				}, nil)
+			},
+			ExpectedContains: []string{
+				"*User 911*",
+				"Known by the bot: true",
+				`Email: lionel\_messi@pet.place`,
+				"City: Rosario",
+				"Bachicha (dog), ID 1, born 2020-01-02",
+			},
+		},
+		{
+			Name:    "User without pets",
+			Command: adminUserEndpoint + " 420",
+			SetRequesters: func(usersMock *mock.MockUsersRequester, petsMock *mock.MockPetsRequester) {
+				usersMock.EXPECT().GetUserData(gomock.Any(), int64(420)).Return(userInfo, nil)
+				petsMock.EXPECT().GetPetsByOwnerID(gomock.Any(), int64(420)).Return(nil, notFoundErr)
+			},
+			ExpectedContains: []string{"Known by the bot: false", "No pets registered"},
+		},
+		{
+			Name:    "Unregistered user",
+			Command: adminUserEndpoint + " 420",
+			SetRequesters: func(usersMock *mock.MockUsersRequester, petsMock *mock.MockPetsRequester) {
+				usersMock.EXPECT().GetUserData(gomock.Any(), int64(420)).Return(domain.UserInfo{}, notFoundErr)
+			},
+			ExpectedContains: []string{"Not registered"},
+		},
+		{
+			Name:             "Invalid telegram ID",
+			Command:          adminUserEndpoint + " lionel",
+			SetRequesters:    func(*mock.MockUsersRequester, *mock.MockPetsRequester) {},
+			ExpectedContains: []string{`Usage: /admin\_user <telegram ID>`},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			usersMock := mock.NewMockUsersRequester(ctrl)
+			petsMock := mock.NewMockPetsRequester(ctrl)
+			testCase.SetRequesters(usersMock, petsMock)
+
+			telegramBot, telegram := startConversationTest(t, Requesters{Users: usersMock, Pets: petsMock})
+			telegramBot.SetAdmin(AdminConfig{TelegramIDs: []int64{registeredUser.ID}})
+
+			telegram.SendText(registeredUser, testCase.Command)
+
+			message := nextMessage(t, telegram)
+			for _, expected := range testCase.ExpectedContains {
+				assert.Contains(t, message.Text, expected)
+			}
+		})
+	}
+}
+
+func TestAdminHealth(t *testing.T) {
+	telegramBot, telegram := startConversationTest(t, Requesters{})
+	telegramBot.SetAdmin(AdminConfig{TelegramIDs: []int64{registeredUser.ID}})
+
+	telegram.SendText(registeredUser, adminHealthEndpoint)
+	message := nextMessage(t, telegram)
+	assert.Equal(t, "Health checks are not available", message.Text)
+
+	telegramBot.SetAdmin(AdminConfig{
+		TelegramIDs: []int64{registeredUser.ID},
+		Health: fakeHealthReporter{
+			Status: health.StatusDown,
+			Checks: map[string]health.CheckResult{
+				"telegram": {Status: health.StatusUp},
+				"pets":     {Status: health.StatusDown, Error: "error service unreachable"},
+			},
+		},
+	})
+
+	telegram.SendText(registeredUser, adminHealthEndpoint)
+	message = nextMessage(t, telegram)
+	assert.Contains(t, message.Text, "*Status:* down")
+	assert.Contains(t, message.Text, "pets: down (error service unreachable)")
+	assert.Contains(t, message.Text, "telegram: up")
+}
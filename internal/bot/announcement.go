@@ -0,0 +1,43 @@
+package bot
+
+import (
+	"context"
+	tele "gopkg.in/telebot.v3"
+)
+
+// Announcement message sent to many users at once. The text is Markdown, if it has an image the text is its caption
+type Announcement struct {
+	Text     string
+	ImageURL string
+	Buttons  []AnnouncementButton
+}
+
+// AnnouncementButton inline button that opens the URL, each button is shown in its own row
+type AnnouncementButton struct {
+	Text string
+	URL  string
+}
+
+// SendAnnouncement sends the announcement to the user, without fetching its chat first
+func (tb *TelegramBot) SendAnnouncement(ctx context.Context, telegramID int64, announcement Announcement) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	options := &tele.SendOptions{ParseMode: tele.ModeMarkdown}
+	if len(announcement.Buttons) > 0 {
+		markup := &tele.ReplyMarkup{}
+		for _, button := range announcement.Buttons {
+			markup.InlineKeyboard = append(markup.InlineKeyboard, []tele.InlineButton{{Text: button.Text, URL: button.URL}})
+		}
+		options.ReplyMarkup = markup
+	}
+
+	var content any = announcement.Text
+	if announcement.ImageURL != "" {
+		content = &tele.Photo{File: tele.FromURL(announcement.ImageURL), Caption: announcement.Text}
+	}
+
+	_, err := tb.bot.Send(tele.ChatID(telegramID), content, options)
+	return err
+}
@@ -1,11 +1,14 @@
 package bot
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"github.com/enescakir/emoji"
 	tele "gopkg.in/telebot.v3"
+	"sync"
+	"sync/atomic"
 	"telegram-bot/internal/bot/internal/button"
-	"telegram-bot/internal/requester"
 )
 
 const (
@@ -23,6 +26,12 @@ const (
 	setNotificationEndpoint      = "/setNotification"
 	registerNotificationEndpoint = "/notification"
 	getVetsEndpoint              = "/getVets"
+
+	// Admin endpoints, see adminOnly
+	adminStatsEndpoint     = "/admin_stats"
+	adminBroadcastEndpoint = "/admin_broadcast"
+	adminUserEndpoint      = "/admin_user"
+	adminHealthEndpoint    = "/admin_health"
 )
 
 // TelegramBot handles requests from telegram. Is in charge to interact with different services
@@ -30,66 +39,158 @@ const (
 //
 // What this bot can do is defined in DefineHandlers
 type TelegramBot struct {
-	bot       *tele.Bot
-	usersDB   map[int64]bool
-	requester *requester.Requester
+	// ctx base context of every update, is cancelled when the bot is shutting down
+	ctx               context.Context
+	bot               *tele.Bot
+	knownUsers        *knownUsers
+	pets              PetsRequester
+	treatments        TreatmentsRequester
+	users             UsersRequester
+	notifications     NotificationsRequester
+	nextDoseReminders *nextDoseReminders
+	// reminderRefreshes serializes the refreshes of the next dose reminders, see refreshNextDoseReminder
+	reminderRefreshes sync.Mutex
+	pendingComments   *pendingComments
+	usageStats        *usageStats
+	admin             AdminConfig
+	// endpoints registered in DefineHandlers, the other commands and callbacks are unknown
+	endpoints map[string]bool
+	// polling is true while the bot is polling updates, see StartBot
+	polling atomic.Bool
+	// updateHooks are called with the sender of each update, see OnUpdateFrom
+	updateHooks []func(telegramID int64)
 }
 
-func NewTelegramBot(bot *tele.Bot, requester *requester.Requester) *TelegramBot {
-	usersDB := make(map[int64]bool)
+func NewTelegramBot(bot *tele.Bot, requesters Requesters) *TelegramBot {
 	return &TelegramBot{
-		bot:       bot,
-		requester: requester,
-		usersDB:   usersDB,
+		ctx:               context.Background(),
+		bot:               bot,
+		pets:              requesters.Pets,
+		treatments:        requesters.Treatments,
+		users:             requesters.Users,
+		notifications:     requesters.Notifications,
+		knownUsers:        newKnownUsers(),
+		nextDoseReminders: newNextDoseReminders(),
+		pendingComments:   newPendingComments(),
+		usageStats:        newUsageStats(),
+		endpoints:         make(map[string]bool),
 	}
 }
 
 // DefineHandlers defines all methods that  TelegramBot can handle, is a not-blocking function
 func (tb *TelegramBot) DefineHandlers() {
+	// Middlewares must be defined before the handlers
+	tb.bot.Use(tb.withMetrics, tb.withUpdateContext, tb.withTracing, tb.withLogger, tb.withKnownUser)
+
+	// The forms are sent as text, so their commands are handled by textHandler
+	tb.endpoints[registerPetEndpoint] = true
+	tb.endpoints[registerNotificationEndpoint] = true
+
 	// Endpoints handlers
-	tb.bot.Handle(helpEndpoint, tb.help)
+	tb.handle(helpEndpoint, tb.help)
+
+	tb.handle(startEndpoint, tb.start)
+
+	tb.handle(createPetEndpoint, tb.createPet)
+
+	tb.handle(getPets, tb.getPets)
+
+	tb.handle(salchiFactEndpoint, tb.getSalchiFact)
 
-	tb.bot.Handle(startEndpoint, tb.start)
+	tb.handle(getVetsEndpoint, tb.getVets)
 
-	tb.bot.Handle(createPetEndpoint, tb.createPet)
+	tb.handle(setNotificationEndpoint, tb.setAlarm)
 
-	tb.bot.Handle(getPets, tb.getPets)
+	tb.handle(adminStatsEndpoint, tb.adminStats, tb.adminOnly)
 
-	tb.bot.Handle(salchiFactEndpoint, tb.getSalchiFact)
+	tb.handle(adminBroadcastEndpoint, tb.adminBroadcast, tb.adminOnly)
 
-	tb.bot.Handle(getVetsEndpoint, tb.getVets)
+	tb.handle(adminUserEndpoint, tb.adminUser, tb.adminOnly)
 
-	tb.bot.Handle(setNotificationEndpoint, tb.setAlarm)
+	tb.handle(adminHealthEndpoint, tb.adminHealth, tb.adminOnly)
 
 	// Button handlers
-	tb.bot.Handle(&button.CreateAccount, tb.createAccount)
+	tb.handle(&button.CreateAccount, tb.createAccount)
 
-	tb.bot.Handle(&button.DontCreateAccount, tb.omitAccountCreation)
+	tb.handle(&button.DontCreateAccount, tb.omitAccountCreation)
 
-	tb.bot.Handle(&button.PetInfo, tb.getPetInfo)
+	tb.handle(&button.PetInfo, tb.getPetInfo)
 
-	tb.bot.Handle(&button.MedicalHistory, tb.medicalHistory)
+	tb.handle(&button.MedicalHistory, tb.medicalHistory)
 
-	tb.bot.Handle(&button.Vaccines, tb.showVaccines)
+	tb.handle(&button.Vaccines, tb.showVaccines)
 
-	tb.bot.Handle(&button.Treatment, tb.getTreatment)
+	tb.handle(&button.Treatment, tb.getTreatment)
+
+	tb.handle(&button.NextDose, tb.setNextDoseReminder)
+
+	tb.handle(&button.AddComment, tb.startTreatmentComment)
 
 	// Action handlers
-	tb.bot.Handle(tele.OnText, tb.textHandler)
+	tb.handle(tele.OnText, tb.textHandler)
+
+	tb.handle(tele.OnEdited, tb.editMessageHandler)
+
+	tb.handle(tele.OnLocation, tb.searchVets)
 
-	tb.bot.Handle(tele.OnEdited, tb.editMessageHandler)
+	tb.handle(tele.OnPhoto, tb.photoHandler)
+}
+
+// handle registers the handler of the endpoint and keeps track of it, see handlerName
+func (tb *TelegramBot) handle(endpoint interface{}, handler tele.HandlerFunc, middlewares ...tele.MiddlewareFunc) {
+	switch end := endpoint.(type) {
+	case string:
+		tb.endpoints[end] = true
+	case tele.CallbackEndpoint:
+		tb.endpoints[end.CallbackUnique()] = true
+	}
 
-	tb.bot.Handle(tele.OnLocation, tb.searchVets)
+	tb.bot.Handle(endpoint, handler, middlewares...)
 }
 
-func (tb *TelegramBot) StartBot() {
+// StartBot starts polling updates from Telegram and syncing the next dose reminders, is a blocking function.
+// When the given context is done, the bot stops polling and the updates that are being handled are cancelled
+func (tb *TelegramBot) StartBot(ctx context.Context) {
+	tb.ctx = ctx
+	go tb.runNextDoseRemindersSync(ctx)
+	go func() {
+		<-ctx.Done()
+		tb.bot.Stop()
+	}()
+
+	tb.polling.Store(true)
+	defer tb.polling.Store(false)
 	tb.bot.Start()
 }
 
-func (tb *TelegramBot) SendNotification(telegramID int64, messageBody string) error {
+// NotificationAttachment photo or document sent after the text of a notification. The file is downloaded by
+// Telegram from URL, otherwise it is uploaded from Data
+type NotificationAttachment struct {
+	IsDocument bool
+	URL        string
+	Data       []byte
+	FileName   string
+	Caption    string
+}
+
+func (na NotificationAttachment) sendable() tele.Sendable {
+	file := tele.FromURL(na.URL)
+	if na.URL == "" {
+		file = tele.FromReader(bytes.NewReader(na.Data))
+	}
+
+	if na.IsDocument {
+		return &tele.Document{File: file, FileName: na.FileName, Caption: na.Caption}
+	}
+
+	return &tele.Photo{File: file, Caption: na.Caption}
+}
+
+// SendNotification sends the message to the user and, if it is not nil, the attachment after it
+func (tb *TelegramBot) SendNotification(telegramID int64, messageBody string, attachment *NotificationAttachment) error {
 	chat, err := tb.bot.ChatByID(telegramID)
 	if err != nil {
-		return fmt.Errorf("error fetching chat of user %d: %v", telegramID, err)
+		return fmt.Errorf("error fetching chat of user %d: %w", telegramID, err)
 	}
 	message := fmt.Sprintf("Scheduled notification %s\n%s", emoji.AlarmClock, messageBody)
 	_, err = tb.bot.Send(chat, message)
@@ -97,5 +198,14 @@ func (tb *TelegramBot) SendNotification(telegramID int64, messageBody string) er
 		return err
 	}
 
+	if attachment == nil {
+		return nil
+	}
+
+	_, err = tb.bot.Send(chat, attachment.sendable())
+	if err != nil {
+		return fmt.Errorf("error sending attachment: %w", err)
+	}
+
 	return nil
 }
@@ -0,0 +1,227 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tele "gopkg.in/telebot.v3"
+	"net/http"
+	"strings"
+	"telegram-bot/internal/bot/internal/button"
+	"telegram-bot/internal/fakebackend"
+	"telegram-bot/internal/requester"
+	"telegram-bot/internal/telegramsim"
+	"testing"
+	"time"
+)
+
+const replyTimeout = 5 * time.Second
+
+var (
+	registeredUser   = tele.User{ID: 911, FirstName: "Lionel", LastName: "Andrés", Username: "lionel"}
+	unregisteredUser = tele.User{ID: 420, FirstName: "Ricardo", Username: "ricardo"}
+)
+
+// newConversationTest starts a TelegramBot that polls updates from a Telegram simulator and performs the requests
+// against the fake backend, which is seeded with the data of registeredUser
+func newConversationTest(t *testing.T) (*TelegramBot, *telegramsim.Server, *fakebackend.Store) {
+	store := fakebackend.NewStore()
+	store.Seed(fmt.Sprint(registeredUser.ID))
+
+	backend := fakebackend.NewServer(store)
+	t.Cleanup(backend.Close)
+
+	t.Setenv("REQUESTER_CONFIG_PATH", "../requester/internal/config/config.json")
+	t.Setenv("REQUESTER_ENV", "")
+	for envVar, baseURL := range backend.ServiceURLs() {
+		t.Setenv(envVar, baseURL)
+	}
+
+	serviceRequester, err := requester.NewRequester(&http.Client{Timeout: replyTimeout})
+	require.NoError(t, err)
+
+	telegramBot, telegram := startConversationTest(t, NewRequesters(serviceRequester))
+	return telegramBot, telegram, store
+}
+
+// startConversationTest starts a TelegramBot that polls updates from a Telegram simulator and performs the requests
+// with the given requesters
+func startConversationTest(t *testing.T, requesters Requesters) (*TelegramBot, *telegramsim.Server) {
+	telegram := telegramsim.NewServer()
+	t.Cleanup(telegram.Close)
+
+	botInstance, err := tele.NewBot(telegram.Settings())
+	require.NoError(t, err)
+
+	telegramBot := NewTelegramBot(botInstance, requesters)
+	telegramBot.DefineHandlers()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		telegramBot.StartBot(ctx)
+		close(stopped)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-stopped
+	})
+	// If the bot is stopped before it starts, the long polling in flight is not cancelled
+	require.NoError(t, telegram.WaitForPolling(replyTimeout))
+
+	return telegramBot, telegram
+}
+
+func nextMessage(t *testing.T, telegram *telegramsim.Server) telegramsim.SentMessage {
+	message, err := telegram.NextMessage(replyTimeout)
+	require.NoError(t, err)
+	return message
+}
+
+func TestConversationStart(t *testing.T) {
+	_, telegram, _ := newConversationTest(t)
+
+	t.Run("Registered user is welcomed", func(t *testing.T) {
+		telegram.SendText(registeredUser, startEndpoint)
+
+		message := nextMessage(t, telegram)
+		assert.Equal(t, registeredUser.ID, message.ChatID)
+		assert.Contains(t, message.Text, "Welcome to Pet Place, Lionel Andrés!")
+	})
+
+	t.Run("Unregistered user is asked to create an account", func(t *testing.T) {
+		telegram.SendText(unregisteredUser, startEndpoint)
+
+		message := nextMessage(t, telegram)
+		assert.Equal(t, unregisteredUser.ID, message.ChatID)
+		assert.Contains(t, message.Text, "You are not registered")
+
+		createAccount, found := message.Button(button.CreateAccount.Unique)
+		require.True(t, found)
+
+		telegram.PressButton(unregisteredUser, message, createAccount)
+		signUp := nextMessage(t, telegram)
+		assert.Contains(t, signUp.Text, "Click below to sign up")
+		require.Len(t, signUp.Buttons(), 1)
+		assert.NotEmpty(t, signUp.Buttons()[0].URL)
+
+		afterSignUp := nextMessage(t, telegram)
+		assert.Contains(t, afterSignUp.Text, "perform /start again")
+	})
+}
+
+// openFirstTreatment goes from /getPets to the most recent treatment of the first pet of registeredUser
+func openFirstTreatment(t *testing.T, telegram *telegramsim.Server) telegramsim.SentMessage {
+	telegram.SendText(registeredUser, getPets)
+	petsMessage := nextMessage(t, telegram)
+	require.NotEmpty(t, petsMessage.Buttons())
+
+	telegram.PressButton(registeredUser, petsMessage, petsMessage.Buttons()[0])
+	petInfo := nextMessage(t, telegram)
+	medicalHistory, found := petInfo.Button(button.MedicalHistory.Unique)
+	require.True(t, found)
+
+	telegram.PressButton(registeredUser, petInfo, medicalHistory)
+	treatmentsMessage := nextMessage(t, telegram)
+	require.NotEmpty(t, treatmentsMessage.Buttons())
+
+	telegram.PressButton(registeredUser, treatmentsMessage, treatmentsMessage.Buttons()[0])
+	return nextMessage(t, telegram)
+}
+
+func TestConversationMedicalHistory(t *testing.T) {
+	_, telegram, _ := newConversationTest(t)
+
+	telegram.SendText(registeredUser, getPets)
+	petsMessage := nextMessage(t, telegram)
+	assert.Equal(t, "Select a pet", petsMessage.Text)
+	require.Len(t, petsMessage.Buttons(), 2)
+	assert.True(t, strings.HasPrefix(petsMessage.Buttons()[0].Text, "Bachicha"))
+	assert.True(t, strings.HasPrefix(petsMessage.Buttons()[1].Text, "Michi"))
+
+	telegram.PressButton(registeredUser, petsMessage, petsMessage.Buttons()[0])
+	petInfo := nextMessage(t, telegram)
+	assert.Contains(t, petInfo.Text, "Bachicha")
+	assert.Contains(t, petInfo.Text, "Age: 3")
+
+	medicalHistory, found := petInfo.Button(button.MedicalHistory.Unique)
+	require.True(t, found)
+
+	telegram.PressButton(registeredUser, petInfo, medicalHistory)
+	treatmentsMessage := nextMessage(t, telegram)
+	assert.Equal(t, "Select a treatment:", treatmentsMessage.Text)
+	require.Len(t, treatmentsMessage.Buttons(), 2)
+	assert.True(t, strings.HasPrefix(treatmentsMessage.Buttons()[0].Text, "Medical appointment"))
+
+	telegram.PressButton(registeredUser, treatmentsMessage, treatmentsMessage.Buttons()[0])
+	treatment := nextMessage(t, telegram)
+	assert.Contains(t, treatment.Text, "Medical appointment")
+	assert.Contains(t, treatment.Text, "Back pain, needs rest")
+
+	_, found = treatment.Button(button.AddComment.Unique)
+	assert.True(t, found)
+}
+
+func TestConversationNotifications(t *testing.T) {
+	telegramBot, telegram, store := newConversationTest(t)
+
+	t.Run("Next dose reminder is registered", func(t *testing.T) {
+		treatment := openFirstTreatment(t, telegram)
+		reminderButton, found := treatment.Button(button.NextDose.Unique)
+		require.True(t, found)
+
+		telegram.PressButton(registeredUser, treatment, reminderButton)
+		message := nextMessage(t, telegram)
+		assert.Contains(t, message.Text, "I will remind you about the next dose")
+		assert.Len(t, store.GetNotifications(fmt.Sprint(registeredUser.ID)), 1)
+	})
+
+	t.Run("Scheduled notification is sent to the user", func(t *testing.T) {
+		err := telegramBot.SendNotification(registeredUser.ID, "Give Bachicha its pill", nil)
+		require.NoError(t, err)
+
+		message := nextMessage(t, telegram)
+		assert.Equal(t, registeredUser.ID, message.ChatID)
+		assert.Contains(t, message.Text, "Scheduled notification")
+		assert.Contains(t, message.Text, "Give Bachicha its pill")
+	})
+
+	t.Run("Scheduled notification to a user that blocked the bot", func(t *testing.T) {
+		telegram.BlockBot(registeredUser)
+		defer telegram.UnblockBot(registeredUser)
+
+		err := telegramBot.SendNotification(registeredUser.ID, "Give Bachicha its pill", nil)
+		assert.Equal(t, ReasonBlocked, UnreachableReason(err))
+		assert.Empty(t, UnreachableReason(errors.New("timeout")))
+	})
+
+	t.Run("Scheduled notification with attachments", func(t *testing.T) {
+		err := telegramBot.SendNotification(registeredUser.ID, "Lab results of Bachicha", &NotificationAttachment{
+			IsDocument: true,
+			Data:       []byte("%PDF-1.4"),
+			FileName:   "results.pdf",
+			Caption:    "Blood test",
+		})
+		require.NoError(t, err)
+
+		nextMessage(t, telegram)
+		document := nextMessage(t, telegram)
+		assert.Equal(t, "sendDocument", document.Method)
+		assert.Equal(t, "results.pdf", document.File)
+		assert.Equal(t, "Blood test", document.Text)
+
+		err = telegramBot.SendNotification(registeredUser.ID, "Prescription of Bachicha", &NotificationAttachment{
+			URL:     "https://pet.place/prescription.jpg",
+			Caption: "Prescription",
+		})
+		require.NoError(t, err)
+
+		nextMessage(t, telegram)
+		photo := nextMessage(t, telegram)
+		assert.Equal(t, "sendPhoto", photo.Method)
+		assert.Equal(t, "https://pet.place/prescription.jpg", photo.File)
+		assert.Equal(t, "Prescription", photo.Text)
+	})
+}
@@ -1,10 +1,52 @@
 package bot
 
-import "errors"
+import (
+	"errors"
+	tele "gopkg.in/telebot.v3"
+	"telegram-bot/internal/requester"
+)
+
+// Reasons why a chat cannot be reached, see UnreachableReason
+const (
+	ReasonBlocked      = "blocked"
+	ReasonChatNotFound = "chat_not_found"
+	ReasonDeactivated  = "deactivated"
+	ReasonNotStarted   = "not_started"
+)
 
 var (
 	errUserInfoNotFound  = errors.New("error user info not found")
 	errSendingSignUpLink = errors.New("error sending sing up link")
 	errInvalidForm       = errors.New("error invalid form")
 	errMissingFormField  = errors.New("missing form field")
+	errPhotoTooLarge     = errors.New("error photo is too large")
 )
+
+// isServiceUnavailable returns true if the request could not be performed because the service is temporarily down
+func isServiceUnavailable(err error) bool {
+	var requestError requester.RequestError
+	return errors.As(err, &requestError) && requestError.IsServiceUnavailable()
+}
+
+// isNotFound returns true if the service responded that the resource does not exist
+func isNotFound(err error) bool {
+	var requestError requester.RequestError
+	return errors.As(err, &requestError) && requestError.IsNotFound()
+}
+
+// UnreachableReason returns why the chat cannot be reached if the error of a send means that no message will be
+// delivered to it until the user does something, eg: unblocks the bot. Otherwise, it returns an empty reason
+func UnreachableReason(err error) string {
+	switch {
+	case errors.Is(err, tele.ErrBlockedByUser):
+		return ReasonBlocked
+	case errors.Is(err, tele.ErrChatNotFound):
+		return ReasonChatNotFound
+	case errors.Is(err, tele.ErrUserIsDeactivated):
+		return ReasonDeactivated
+	case errors.Is(err, tele.ErrNotStartedByUser):
+		return ReasonNotStarted
+	default:
+		return ""
+	}
+}
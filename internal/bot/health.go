@@ -0,0 +1,67 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	tele "gopkg.in/telebot.v3"
+)
+
+var (
+	errBotNotPolling = errors.New("error bot is not polling updates")
+	errWebhookIsSet  = errors.New("error a webhook is set, updates cannot be polled")
+)
+
+// CheckTelegram returns an error if the Telegram Bot API cannot be reached with the token of the bot
+func (tb *TelegramBot) CheckTelegram(ctx context.Context) error {
+	_, err := callWithContext(ctx, func() ([]byte, error) {
+		return tb.bot.Raw("getMe", nil)
+	})
+	return err
+}
+
+// CheckPoller returns an error if the bot is not receiving updates: it was not started, it was stopped or it
+// polls the updates while a webhook is set, in which case Telegram rejects the polling
+func (tb *TelegramBot) CheckPoller(ctx context.Context) error {
+	if !tb.polling.Load() {
+		return errBotNotPolling
+	}
+
+	if _, isLongPoller := tb.bot.Poller.(*tele.LongPoller); !isLongPoller {
+		return nil
+	}
+
+	webhook, err := callWithContext(ctx, tb.bot.Webhook)
+	if err != nil {
+		return err
+	}
+
+	if webhook.Listen != "" {
+		return fmt.Errorf("%w: %s", errWebhookIsSet, webhook.Listen)
+	}
+
+	return nil
+}
+
+// callWithContext performs a call to the Telegram Bot API, which does not receive a context, and returns as soon
+// as the context is done
+func callWithContext[T any](ctx context.Context, call func() (T, error)) (T, error) {
+	type result struct {
+		value T
+		err   error
+	}
+
+	results := make(chan result, 1)
+	go func() {
+		value, err := call()
+		results <- result{value: value, err: err}
+	}()
+
+	select {
+	case callResult := <-results:
+		return callResult.value, callResult.err
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
@@ -0,0 +1,62 @@
+package bot
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tele "gopkg.in/telebot.v3"
+	"telegram-bot/internal/telegramsim"
+	"testing"
+	"time"
+)
+
+func TestCheckTelegram(t *testing.T) {
+	telegram := telegramsim.NewServer()
+	defer telegram.Close()
+
+	botInstance, err := tele.NewBot(telegram.Settings())
+	require.NoError(t, err)
+	telegramBot := NewTelegramBot(botInstance, Requesters{})
+
+	assert.NoError(t, telegramBot.CheckTelegram(context.Background()))
+
+	telegram.Close()
+	assert.Error(t, telegramBot.CheckTelegram(context.Background()))
+}
+
+func TestCheckPoller(t *testing.T) {
+	telegram := telegramsim.NewServer()
+	defer telegram.Close()
+
+	botInstance, err := tele.NewBot(telegram.Settings())
+	require.NoError(t, err)
+	telegramBot := NewTelegramBot(botInstance, Requesters{})
+
+	t.Run("Bot is not started", func(t *testing.T) {
+		assert.ErrorIs(t, telegramBot.CheckPoller(context.Background()), errBotNotPolling)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		telegramBot.StartBot(ctx)
+		close(stopped)
+	}()
+
+	t.Run("Bot is polling", func(t *testing.T) {
+		assert.Eventually(t, func() bool {
+			return telegramBot.CheckPoller(context.Background()) == nil
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("Webhook is set", func(t *testing.T) {
+		require.NoError(t, botInstance.SetWebhook(&tele.Webhook{Listen: "https://pet.place/telegram"}))
+		assert.ErrorIs(t, telegramBot.CheckPoller(context.Background()), errWebhookIsSet)
+	})
+
+	t.Run("Bot is stopped", func(t *testing.T) {
+		cancel()
+		<-stopped
+		assert.ErrorIs(t, telegramBot.CheckPoller(context.Background()), errBotNotPolling)
+	})
+}
@@ -15,6 +15,8 @@ const (
 	medicalHistoryEndpoint    = "medical-history"
 	setAlarmEndpoint          = "set-alarm"
 	treatmentInfoEndpoint     = "treatment-info"
+	nextDoseReminderEndpoint  = "next-dose-reminder"
+	addCommentEndpoint        = "add-comment"
 )
 
 var (
@@ -27,6 +29,8 @@ var (
 	Vaccines       = Menu.Data(fmt.Sprintf("Vaccines %s", emoji.Syringe), vaccinesEndpoint)
 	MedicalHistory = Menu.Data(fmt.Sprintf("Medical history %v", emoji.OrangeBook), medicalHistoryEndpoint)
 	Treatment      = Menu.Data("", treatmentInfoEndpoint)
+	NextDose       = Menu.Data(fmt.Sprintf("Remind me about next dose %s", emoji.AlarmClock), nextDoseReminderEndpoint)
+	AddComment     = Menu.Data(fmt.Sprintf("Add comment %s", emoji.Memo), addCommentEndpoint)
 	Location       = Menu.Location("Location")
 )
 
@@ -57,3 +61,13 @@ func TreatmentSummaryButton(treatmentSummary string, treatmentID string) tele.Bt
 	markup := &tele.ReplyMarkup{}
 	return markup.Data(treatmentSummary, Treatment.Unique, treatmentID)
 }
+
+func NextDoseReminderButton(treatmentID string) tele.Btn {
+	markup := &tele.ReplyMarkup{}
+	return markup.Data(NextDose.Text, NextDose.Unique, treatmentID)
+}
+
+func AddCommentButton(treatmentID string) tele.Btn {
+	markup := &tele.ReplyMarkup{}
+	return markup.Data(AddComment.Text, AddComment.Unique, treatmentID)
+}
@@ -0,0 +1,252 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: requesters.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+	domain "telegram-bot/internal/domain"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockPetsRequester is a mock of PetsRequester interface.
+type MockPetsRequester struct {
+	ctrl     *gomock.Controller
+	recorder *MockPetsRequesterMockRecorder
+}
+
+// MockPetsRequesterMockRecorder is the mock recorder for MockPetsRequester.
+type MockPetsRequesterMockRecorder struct {
+	mock *MockPetsRequester
+}
+
+// NewMockPetsRequester creates a new mock instance.
+func NewMockPetsRequester(ctrl *gomock.Controller) *MockPetsRequester {
+	mock := &MockPetsRequester{ctrl: ctrl}
+	mock.recorder = &MockPetsRequesterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPetsRequester) EXPECT() *MockPetsRequesterMockRecorder {
+	return m.recorder
+}
+
+// GetPetData mocks base method.
+func (m *MockPetsRequester) GetPetData(ctx context.Context, petID int) (domain.PetData, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPetData", ctx, petID)
+	ret0, _ := ret[0].(domain.PetData)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPetData indicates an expected call of GetPetData.
+func (mr *MockPetsRequesterMockRecorder) GetPetData(ctx, petID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPetData", reflect.TypeOf((*MockPetsRequester)(nil).GetPetData), ctx, petID)
+}
+
+// GetPetsByOwnerID mocks base method.
+func (m *MockPetsRequester) GetPetsByOwnerID(ctx context.Context, ownerID int64) ([]domain.PetData, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPetsByOwnerID", ctx, ownerID)
+	ret0, _ := ret[0].([]domain.PetData)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPetsByOwnerID indicates an expected call of GetPetsByOwnerID.
+func (mr *MockPetsRequesterMockRecorder) GetPetsByOwnerID(ctx, ownerID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPetsByOwnerID", reflect.TypeOf((*MockPetsRequester)(nil).GetPetsByOwnerID), ctx, ownerID)
+}
+
+// RegisterPet mocks base method.
+func (m *MockPetsRequester) RegisterPet(ctx context.Context, petDataRequest domain.PetRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RegisterPet", ctx, petDataRequest)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RegisterPet indicates an expected call of RegisterPet.
+func (mr *MockPetsRequesterMockRecorder) RegisterPet(ctx, petDataRequest interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterPet", reflect.TypeOf((*MockPetsRequester)(nil).RegisterPet), ctx, petDataRequest)
+}
+
+// MockTreatmentsRequester is a mock of TreatmentsRequester interface.
+type MockTreatmentsRequester struct {
+	ctrl     *gomock.Controller
+	recorder *MockTreatmentsRequesterMockRecorder
+}
+
+// MockTreatmentsRequesterMockRecorder is the mock recorder for MockTreatmentsRequester.
+type MockTreatmentsRequesterMockRecorder struct {
+	mock *MockTreatmentsRequester
+}
+
+// NewMockTreatmentsRequester creates a new mock instance.
+func NewMockTreatmentsRequester(ctrl *gomock.Controller) *MockTreatmentsRequester {
+	mock := &MockTreatmentsRequester{ctrl: ctrl}
+	mock.recorder = &MockTreatmentsRequesterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTreatmentsRequester) EXPECT() *MockTreatmentsRequesterMockRecorder {
+	return m.recorder
+}
+
+// AddTreatmentComment mocks base method.
+func (m *MockTreatmentsRequester) AddTreatmentComment(ctx context.Context, treatmentID string, commentRequest domain.CommentRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddTreatmentComment", ctx, treatmentID, commentRequest)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddTreatmentComment indicates an expected call of AddTreatmentComment.
+func (mr *MockTreatmentsRequesterMockRecorder) AddTreatmentComment(ctx, treatmentID, commentRequest interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddTreatmentComment", reflect.TypeOf((*MockTreatmentsRequester)(nil).AddTreatmentComment), ctx, treatmentID, commentRequest)
+}
+
+// GetTreatment mocks base method.
+func (m *MockTreatmentsRequester) GetTreatment(ctx context.Context, treatmentID string) (domain.Treatment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTreatment", ctx, treatmentID)
+	ret0, _ := ret[0].(domain.Treatment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTreatment indicates an expected call of GetTreatment.
+func (mr *MockTreatmentsRequesterMockRecorder) GetTreatment(ctx, treatmentID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTreatment", reflect.TypeOf((*MockTreatmentsRequester)(nil).GetTreatment), ctx, treatmentID)
+}
+
+// GetTreatmentsByPetID mocks base method.
+func (m *MockTreatmentsRequester) GetTreatmentsByPetID(ctx context.Context, petID int) ([]domain.Treatment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTreatmentsByPetID", ctx, petID)
+	ret0, _ := ret[0].([]domain.Treatment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTreatmentsByPetID indicates an expected call of GetTreatmentsByPetID.
+func (mr *MockTreatmentsRequesterMockRecorder) GetTreatmentsByPetID(ctx, petID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTreatmentsByPetID", reflect.TypeOf((*MockTreatmentsRequester)(nil).GetTreatmentsByPetID), ctx, petID)
+}
+
+// GetVaccines mocks base method.
+func (m *MockTreatmentsRequester) GetVaccines(ctx context.Context, petID int) ([]domain.Vaccine, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVaccines", ctx, petID)
+	ret0, _ := ret[0].([]domain.Vaccine)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVaccines indicates an expected call of GetVaccines.
+func (mr *MockTreatmentsRequesterMockRecorder) GetVaccines(ctx, petID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVaccines", reflect.TypeOf((*MockTreatmentsRequester)(nil).GetVaccines), ctx, petID)
+}
+
+// MockUsersRequester is a mock of UsersRequester interface.
+type MockUsersRequester struct {
+	ctrl     *gomock.Controller
+	recorder *MockUsersRequesterMockRecorder
+}
+
+// MockUsersRequesterMockRecorder is the mock recorder for MockUsersRequester.
+type MockUsersRequesterMockRecorder struct {
+	mock *MockUsersRequester
+}
+
+// NewMockUsersRequester creates a new mock instance.
+func NewMockUsersRequester(ctrl *gomock.Controller) *MockUsersRequester {
+	mock := &MockUsersRequester{ctrl: ctrl}
+	mock.recorder = &MockUsersRequesterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUsersRequester) EXPECT() *MockUsersRequesterMockRecorder {
+	return m.recorder
+}
+
+// GetUserData mocks base method.
+func (m *MockUsersRequester) GetUserData(ctx context.Context, telegramID int64) (domain.UserInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserData", ctx, telegramID)
+	ret0, _ := ret[0].(domain.UserInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserData indicates an expected call of GetUserData.
+func (mr *MockUsersRequesterMockRecorder) GetUserData(ctx, telegramID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserData", reflect.TypeOf((*MockUsersRequester)(nil).GetUserData), ctx, telegramID)
+}
+
+// MockNotificationsRequester is a mock of NotificationsRequester interface.
+type MockNotificationsRequester struct {
+	ctrl     *gomock.Controller
+	recorder *MockNotificationsRequesterMockRecorder
+}
+
+// MockNotificationsRequesterMockRecorder is the mock recorder for MockNotificationsRequester.
+type MockNotificationsRequesterMockRecorder struct {
+	mock *MockNotificationsRequester
+}
+
+// NewMockNotificationsRequester creates a new mock instance.
+func NewMockNotificationsRequester(ctrl *gomock.Controller) *MockNotificationsRequester {
+	mock := &MockNotificationsRequester{ctrl: ctrl}
+	mock.recorder = &MockNotificationsRequesterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNotificationsRequester) EXPECT() *MockNotificationsRequesterMockRecorder {
+	return m.recorder
+}
+
+// DeleteNotification mocks base method.
+func (m *MockNotificationsRequester) DeleteNotification(ctx context.Context, notificationID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteNotification", ctx, notificationID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteNotification indicates an expected call of DeleteNotification.
+func (mr *MockNotificationsRequesterMockRecorder) DeleteNotification(ctx, notificationID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteNotification", reflect.TypeOf((*MockNotificationsRequester)(nil).DeleteNotification), ctx, notificationID)
+}
+
+// RegisterNotifications mocks base method.
+func (m *MockNotificationsRequester) RegisterNotifications(ctx context.Context, notificationRequest domain.NotificationRequest) ([]domain.NotificationResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RegisterNotifications", ctx, notificationRequest)
+	ret0, _ := ret[0].([]domain.NotificationResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RegisterNotifications indicates an expected call of RegisterNotifications.
+func (mr *MockNotificationsRequesterMockRecorder) RegisterNotifications(ctx, notificationRequest interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterNotifications", reflect.TypeOf((*MockNotificationsRequester)(nil).RegisterNotifications), ctx, notificationRequest)
+}
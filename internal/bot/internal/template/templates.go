@@ -61,3 +61,16 @@ func Commands() string {
 func TryAgainMessage() string {
 	return "Something went wrong, please try again"
 }
+
+// Names of the services as they are shown to the users
+const (
+	PetsService           = "pets"
+	MedicalRecordsService = "medical records"
+	UsersService          = "users"
+	NotificationsService  = "notifications"
+)
+
+// ServiceUnavailableMessage message for the users when a service is down and their request cannot be performed
+func ServiceUnavailableMessage(service string) string {
+	return fmt.Sprintf("Sorry, the %s service is temporarily unavailable, please try again in a few minutes", service)
+}
@@ -0,0 +1,119 @@
+package bot
+
+import (
+	"github.com/sirupsen/logrus"
+	tele "gopkg.in/telebot.v3"
+	"sort"
+	"sync"
+	"telegram-bot/internal/utils/filestore"
+)
+
+// KnownUsersFile name of the file, in the data dir, where the known users are persisted
+const KnownUsersFile = "known_users.json"
+
+// knownUsers telegram IDs of the users that sent an update to the bot, they are the audience of the broadcasts.
+// If it has a path, the users are persisted in it
+type knownUsers struct {
+	mu   sync.Mutex
+	ids  map[int64]bool
+	path string
+}
+
+func newKnownUsers() *knownUsers {
+	return &knownUsers{
+		ids: make(map[int64]bool),
+	}
+}
+
+// load adds the users persisted in the file, and persists the new ones in it from now on
+func (ku *knownUsers) load(path string) error {
+	var ids []int64
+	err := filestore.Load(path, &ids)
+	if err != nil {
+		return err
+	}
+
+	ku.mu.Lock()
+	defer ku.mu.Unlock()
+
+	for _, telegramID := range ids {
+		ku.ids[telegramID] = true
+	}
+	ku.path = path
+
+	return nil
+}
+
+// add registers the user, if it is new it is persisted
+func (ku *knownUsers) add(telegramID int64) {
+	ku.mu.Lock()
+	defer ku.mu.Unlock()
+
+	if ku.ids[telegramID] {
+		return
+	}
+
+	ku.ids[telegramID] = true
+	err := filestore.Save(ku.path, ku.sortedLocked())
+	if err != nil {
+		logrus.Errorf("error persisting known users: %v", err)
+	}
+}
+
+// contains returns true if the user has sent an update to the bot
+func (ku *knownUsers) contains(telegramID int64) bool {
+	ku.mu.Lock()
+	defer ku.mu.Unlock()
+
+	return ku.ids[telegramID]
+}
+
+// list returns the users sorted by telegram ID
+func (ku *knownUsers) list() []int64 {
+	ku.mu.Lock()
+	defer ku.mu.Unlock()
+
+	return ku.sortedLocked()
+}
+
+func (ku *knownUsers) sortedLocked() []int64 {
+	ids := make([]int64, 0, len(ku.ids))
+	for telegramID := range ku.ids {
+		ids = append(ids, telegramID)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i] < ids[j]
+	})
+
+	return ids
+}
+
+// withKnownUser middleware that registers the sender of each update as a known user and calls the update hooks
+func (tb *TelegramBot) withKnownUser(next tele.HandlerFunc) tele.HandlerFunc {
+	return func(c tele.Context) error {
+		if sender := c.Sender(); sender != nil && !sender.IsBot {
+			tb.knownUsers.add(sender.ID)
+			for _, hook := range tb.updateHooks {
+				hook(sender.ID)
+			}
+		}
+
+		return next(c)
+	}
+}
+
+// OnUpdateFrom registers a hook that is called with the telegram ID of the user of each update, eg: to know that
+// the chat is reachable again. It must be called before StartBot
+func (tb *TelegramBot) OnUpdateFrom(hook func(telegramID int64)) {
+	tb.updateHooks = append(tb.updateHooks, hook)
+}
+
+// KnownUsers returns the telegram IDs of the users that sent an update to the bot
+func (tb *TelegramBot) KnownUsers() []int64 {
+	return tb.knownUsers.list()
+}
+
+// PersistKnownUsers loads the known users from the file and persists the new ones in it
+func (tb *TelegramBot) PersistKnownUsers(path string) error {
+	return tb.knownUsers.load(path)
+}
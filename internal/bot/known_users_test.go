@@ -0,0 +1,61 @@
+package bot
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tele "gopkg.in/telebot.v3"
+	"path/filepath"
+	"testing"
+)
+
+func TestKnownUsers(t *testing.T) {
+	users := newKnownUsers()
+	assert.Empty(t, users.list())
+
+	users.add(911)
+	users.add(69)
+	users.add(911)
+	assert.Equal(t, []int64{69, 911}, users.list())
+}
+
+func TestKnownUsersArePersisted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), KnownUsersFile)
+
+	users := newKnownUsers()
+	require.NoError(t, users.load(path))
+	users.add(911)
+	users.add(69)
+
+	restarted := newKnownUsers()
+	require.NoError(t, restarted.load(path))
+	assert.Equal(t, []int64{69, 911}, restarted.list())
+
+	restarted.add(420)
+	assert.Equal(t, []int64{69, 420, 911}, restarted.list())
+}
+
+func TestWithKnownUser(t *testing.T) {
+	botInstance, err := tele.NewBot(tele.Settings{Offline: true})
+	require.NoError(t, err)
+
+	telegramBot := NewTelegramBot(botInstance, Requesters{})
+	var updatesFrom []int64
+	telegramBot.OnUpdateFrom(func(telegramID int64) {
+		updatesFrom = append(updatesFrom, telegramID)
+	})
+	handler := telegramBot.withKnownUser(func(tele.Context) error {
+		return nil
+	})
+
+	updates := []tele.Update{
+		{Message: &tele.Message{Sender: &tele.User{ID: 911}, Text: "/start"}},
+		{Message: &tele.Message{Sender: &tele.User{ID: 69, IsBot: true}, Text: "/start"}},
+		{Callback: &tele.Callback{Sender: &tele.User{ID: 420}}},
+	}
+	for _, update := range updates {
+		require.NoError(t, handler(botInstance.NewContext(update)))
+	}
+
+	assert.Equal(t, []int64{911, 420}, updatesFrom)
+	assert.Equal(t, []int64{420, 911}, telegramBot.KnownUsers())
+}
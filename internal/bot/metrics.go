@@ -0,0 +1,161 @@
+package bot
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	tele "gopkg.in/telebot.v3"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+const (
+	metricsNamespace = "telegramer"
+	metricsSubsystem = "bot"
+
+	// unknownCommand and unknownCallback names of the commands and buttons that are not registered
+	unknownCommand  = "unknown_command"
+	unknownCallback = "unknown_callback"
+
+	// getUpdatesMethod is not counted as an outbound call, it is performed continuously by the poller
+	getUpdatesMethod = "getUpdates"
+	// fileDownloadMethod and otherMethod methods of the downloads of files and of the other requests, which are
+	// not calls to a method of the Bot API
+	fileDownloadMethod = "getFile_download"
+	otherMethod        = "other"
+)
+
+var (
+	// botMethodPath path of the calls to the Bot API: /bot<token>/<method>
+	botMethodPath = regexp.MustCompile(`/bot[^/]+/([A-Za-z]+)$`)
+	// fileDownloadPath path of the downloads of files: /file/bot<token>/<file path>
+	fileDownloadPath = regexp.MustCompile(`/file/bot[^/]+/`)
+)
+
+var (
+	// handledUpdates updates handled by handler name, eg: /start, callback:pet-info, text
+	handledUpdates = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "handled_updates_total",
+		Help:      "Commands, callbacks and events handled by name.",
+	}, []string{"handler"})
+
+	// handlerErrors updates whose handler returned an error, by handler name
+	handlerErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "handler_errors_total",
+		Help:      "Handlers that returned an error by name.",
+	}, []string{"handler"})
+
+	// telegramRequests calls to the Telegram Bot API by method and outcome
+	telegramRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "telegram_requests_total",
+		Help:      "Outbound calls to the Telegram Bot API by method and outcome (ok or error).",
+	}, []string{"method", "outcome"})
+)
+
+// withMetrics middleware that counts the handled updates and the handlers that fail, both in the metrics
+// and in the usage stats
+func (tb *TelegramBot) withMetrics(next tele.HandlerFunc) tele.HandlerFunc {
+	return func(c tele.Context) error {
+		handler := tb.handlerName(c)
+		handledUpdates.WithLabelValues(handler).Inc()
+
+		err := next(c)
+		if err != nil {
+			handlerErrors.WithLabelValues(handler).Inc()
+		}
+		tb.usageStats.record(handler, err != nil)
+
+		return err
+	}
+}
+
+// handlerName returns the name used in the metrics for the update: the command, the unique of the pressed button
+// or the type of the event. The commands and buttons that are not registered are sent by the users, so they are
+// named unknownCommand and unknownCallback to keep the names bounded
+func (tb *TelegramBot) handlerName(c tele.Context) string {
+	if callback := c.Callback(); callback != nil {
+		if !tb.endpoints["\f"+callback.Unique] {
+			return unknownCallback
+		}
+		return "callback:" + callback.Unique
+	}
+
+	message := c.Message()
+	switch {
+	case message == nil:
+		return "other"
+	case strings.HasPrefix(message.Text, "/"):
+		command, _, _ := strings.Cut(strings.Fields(message.Text)[0], "@")
+		if !tb.endpoints[command] {
+			return unknownCommand
+		}
+		return command
+	case message.Location != nil:
+		return "location"
+	case message.Photo != nil:
+		return "photo"
+	case c.Update().EditedMessage != nil:
+		return "edited"
+	case message.Text != "":
+		return "text"
+	default:
+		return "other"
+	}
+}
+
+// instrumentedTransport counts the outbound calls to the Telegram Bot API. A call fails if no response is received
+// or if Telegram rejects it, eg: the user blocked the bot
+type instrumentedTransport struct {
+	next http.RoundTripper
+}
+
+func (it instrumentedTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	response, err := it.next.RoundTrip(request)
+
+	method := telegramMethod(request.URL.Path)
+	if method == getUpdatesMethod {
+		return response, err
+	}
+
+	outcome := "ok"
+	if err != nil || response.StatusCode != http.StatusOK {
+		outcome = "error"
+	}
+	telegramRequests.WithLabelValues(method, outcome).Inc()
+
+	return response, err
+}
+
+// telegramMethod returns the Bot API method called in the path. The paths of the downloads have the path of the
+// file, so they are all named fileDownloadMethod to keep the names bounded
+func telegramMethod(requestPath string) string {
+	if fileDownloadPath.MatchString(requestPath) {
+		return fileDownloadMethod
+	}
+
+	if match := botMethodPath.FindStringSubmatch(requestPath); match != nil {
+		return match[1]
+	}
+
+	return otherMethod
+}
+
+// InstrumentClient returns a copy of the client that counts the calls to the Telegram Bot API. Must be used as
+// the client of the bot, see tele.Settings
+func InstrumentClient(client *http.Client) *http.Client {
+	transport := client.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	instrumentedClient := *client
+	instrumentedClient.Transport = instrumentedTransport{next: transport}
+
+	return &instrumentedClient
+}
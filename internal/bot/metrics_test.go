@@ -0,0 +1,144 @@
+package bot
+
+import (
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tele "gopkg.in/telebot.v3"
+	"net/http"
+	"net/http/httptest"
+	"telegram-bot/internal/telegramsim"
+	"testing"
+)
+
+func TestHandlerName(t *testing.T) {
+	botInstance, err := tele.NewBot(tele.Settings{Offline: true})
+	require.NoError(t, err)
+	telegramBot := NewTelegramBot(botInstance, Requesters{})
+	telegramBot.DefineHandlers()
+
+	testCases := []struct {
+		Name         string
+		Update       tele.Update
+		ExpectedName string
+	}{
+		{
+			Name:         "Command",
+			Update:       tele.Update{Message: &tele.Message{Text: "/start"}},
+			ExpectedName: "/start",
+		},
+		{
+			Name:         "Command with the bot username and arguments",
+			Update:       tele.Update{Message: &tele.Message{Text: "/notification@pet_place_bot Message: pill"}},
+			ExpectedName: "/notification",
+		},
+		{
+			Name:         "Form command",
+			Update:       tele.Update{Message: &tele.Message{Text: "/addPetRecord\n\nName: Bachicha"}},
+			ExpectedName: "/addPetRecord",
+		},
+		{
+			Name:         "Unknown command",
+			Update:       tele.Update{Message: &tele.Message{Text: "/dQw4w9WgXcQ"}},
+			ExpectedName: unknownCommand,
+		},
+		{
+			Name:         "Callback",
+			Update:       tele.Update{Callback: &tele.Callback{Unique: "pet-info", Data: "1"}},
+			ExpectedName: "callback:pet-info",
+		},
+		{
+			Name:         "Unknown callback",
+			Update:       tele.Update{Callback: &tele.Callback{Unique: "dQw4w9WgXcQ", Data: "1"}},
+			ExpectedName: unknownCallback,
+		},
+		{
+			Name:         "Location",
+			Update:       tele.Update{Message: &tele.Message{Location: &tele.Location{Lat: -34.6, Lng: -58.4}}},
+			ExpectedName: "location",
+		},
+		{
+			Name:         "Photo",
+			Update:       tele.Update{Message: &tele.Message{Photo: &tele.Photo{}, Caption: "Bachicha"}},
+			ExpectedName: "photo",
+		},
+		{
+			Name:         "Edited message",
+			Update:       tele.Update{EditedMessage: &tele.Message{Text: "Name: Bachicha"}},
+			ExpectedName: "edited",
+		},
+		{
+			Name:         "Text",
+			Update:       tele.Update{Message: &tele.Message{Text: "Back pain, needs rest"}},
+			ExpectedName: "text",
+		},
+		{
+			Name:         "Other update",
+			Update:       tele.Update{},
+			ExpectedName: "other",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			assert.Equal(t, testCase.ExpectedName, telegramBot.handlerName(botInstance.NewContext(testCase.Update)))
+		})
+	}
+}
+
+func TestInstrumentClient(t *testing.T) {
+	telegram := telegramsim.NewServer()
+	defer telegram.Close()
+
+	settings := telegram.Settings()
+	settings.Client = InstrumentClient(&http.Client{})
+	botInstance, err := tele.NewBot(settings)
+	require.NoError(t, err)
+
+	sent := telegramRequests.WithLabelValues("sendMessage", "ok")
+	sentBefore := testutil.ToFloat64(sent)
+
+	_, err = botInstance.Send(&registeredUser, "Give Bachicha its pill")
+	require.NoError(t, err)
+	assert.Equal(t, sentBefore+1, testutil.ToFloat64(sent))
+}
+
+func TestInstrumentClientFileDownloads(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("photo"))
+	}))
+	defer server.Close()
+
+	client := InstrumentClient(server.Client())
+	downloads := telegramRequests.WithLabelValues(fileDownloadMethod, "ok")
+	downloadsBefore := testutil.ToFloat64(downloads)
+
+	for _, filePath := range []string{"photos/file_1.jpg", "photos/file_2.jpg"} {
+		response, err := client.Get(fmt.Sprintf("%s/file/bot%s/%s", server.URL, telegramsim.Token, filePath))
+		require.NoError(t, err)
+		_ = response.Body.Close()
+	}
+
+	assert.Equal(t, downloadsBefore+2, testutil.ToFloat64(downloads))
+	assert.Zero(t, testutil.ToFloat64(telegramRequests.WithLabelValues("file_1.jpg", "ok")))
+}
+
+func TestTelegramMethod(t *testing.T) {
+	testCases := []struct {
+		Name           string
+		Path           string
+		ExpectedMethod string
+	}{
+		{Name: "Bot API method", Path: "/bot123456:token/sendMessage", ExpectedMethod: "sendMessage"},
+		{Name: "Bot API method with a prefix", Path: "/telegram/bot123456:token/getMe", ExpectedMethod: "getMe"},
+		{Name: "File download", Path: "/file/bot123456:token/photos/file_1.jpg", ExpectedMethod: fileDownloadMethod},
+		{Name: "Other path", Path: "/photos/file_1.jpg", ExpectedMethod: otherMethod},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			assert.Equal(t, testCase.ExpectedMethod, telegramMethod(testCase.Path))
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package bot
+
+import (
+	"context"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	tele "gopkg.in/telebot.v3"
+	"telegram-bot/internal/utils/logging"
+)
+
+const (
+	updateContextKey = "update-context"
+	tracerName       = "telegram-bot/internal/bot"
+)
+
+// withUpdateContext middleware that creates a context for each update. The context is derived from the one
+// received in StartBot, so the requests in flight are cancelled when the bot is shutting down
+func (tb *TelegramBot) withUpdateContext(next tele.HandlerFunc) tele.HandlerFunc {
+	return func(c tele.Context) error {
+		ctx, cancel := context.WithCancel(tb.ctx)
+		defer cancel()
+
+		c.Set(updateContextKey, ctx)
+		return next(c)
+	}
+}
+
+// withTracing middleware that creates a span for each update, the requests performed while handling the update
+// are traced as its children. Must be used after withUpdateContext
+func (tb *TelegramBot) withTracing(next tele.HandlerFunc) tele.HandlerFunc {
+	return func(c tele.Context) error {
+		attributes := []attribute.KeyValue{
+			attribute.Int("telegram.update_id", c.Update().ID),
+		}
+		if chat := c.Chat(); chat != nil {
+			attributes = append(attributes, attribute.Int64("telegram.chat_id", chat.ID))
+		}
+
+		ctx, span := otel.Tracer(tracerName).Start(
+			requestContext(c),
+			"update "+tb.handlerName(c),
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(attributes...),
+		)
+		defer span.End()
+
+		c.Set(updateContextKey, ctx)
+		err := next(c)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+
+		return err
+	}
+}
+
+// withLogger middleware that attaches to the update context a request ID and a logger with the data of the update:
+// update ID, chat ID, command, request ID and trace ID if the update is traced. Must be used after withUpdateContext.
+// The request ID is sent to the services in each request, so their logs can be correlated with the update
+func (tb *TelegramBot) withLogger(next tele.HandlerFunc) tele.HandlerFunc {
+	return func(c tele.Context) error {
+		requestID := logging.NewRequestID()
+		fields := logrus.Fields{
+			logging.RequestIDField: requestID,
+			logging.UpdateIDField:  c.Update().ID,
+			logging.CommandField:   tb.handlerName(c),
+		}
+		if chat := c.Chat(); chat != nil {
+			fields[logging.ChatIDField] = chat.ID
+		}
+		if spanContext := trace.SpanContextFromContext(requestContext(c)); spanContext.HasTraceID() {
+			fields[logging.TraceIDField] = spanContext.TraceID().String()
+		}
+
+		updateLogger := logrus.WithFields(fields)
+		ctx := logging.WithLogger(logging.WithRequestID(requestContext(c), requestID), updateLogger)
+		c.Set(updateContextKey, ctx)
+
+		updateLogger.Debug("handling update")
+		err := next(c)
+		if err != nil {
+			updateLogger.Errorf("error handling update: %v", err)
+		}
+
+		return err
+	}
+}
+
+// logger returns the logger of the update that is being handled
+func logger(c tele.Context) *logrus.Entry {
+	return logging.FromContext(requestContext(c))
+}
+
+// requestContext returns the context of the update that is being handled. Must be used in each
+// call to the requester
+func requestContext(c tele.Context) context.Context {
+	ctx, ok := c.Get(updateContextKey).(context.Context)
+	if !ok {
+		return context.Background()
+	}
+
+	return ctx
+}
@@ -0,0 +1,113 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	tele "gopkg.in/telebot.v3"
+	"telegram-bot/internal/utils/logging"
+	"testing"
+)
+
+func TestWithUpdateContext(t *testing.T) {
+	botInstance, err := tele.NewBot(tele.Settings{Offline: true})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	telegramBot := NewTelegramBot(botInstance, Requesters{})
+	telegramBot.ctx = ctx
+
+	t.Run("Context is missing", func(t *testing.T) {
+		c := botInstance.NewContext(tele.Update{})
+		assert.Equal(t, context.Background(), requestContext(c))
+	})
+
+	t.Run("Update context is derived from the bot context", func(t *testing.T) {
+		handler := telegramBot.withUpdateContext(func(c tele.Context) error {
+			updateCtx := requestContext(c)
+			assert.NoError(t, updateCtx.Err())
+
+			cancel()
+			assert.ErrorIs(t, updateCtx.Err(), context.Canceled)
+			return nil
+		})
+
+		err := handler(botInstance.NewContext(tele.Update{}))
+		assert.NoError(t, err)
+	})
+}
+
+func TestWithLogger(t *testing.T) {
+	botInstance, err := tele.NewBot(tele.Settings{Offline: true})
+	require.NoError(t, err)
+
+	telegramBot := NewTelegramBot(botInstance, Requesters{})
+	telegramBot.DefineHandlers()
+	update := tele.Update{
+		ID: 69,
+		Message: &tele.Message{
+			Chat: &tele.Chat{ID: 911},
+			Text: "/start",
+		},
+	}
+
+	var requestIDs []string
+	handler := telegramBot.withUpdateContext(telegramBot.withLogger(func(c tele.Context) error {
+		requestID := logging.RequestID(requestContext(c))
+		assert.NotEmpty(t, requestID)
+		requestIDs = append(requestIDs, requestID)
+
+		fields := logger(c).Data
+		assert.Equal(t, requestID, fields[logging.RequestIDField])
+		assert.Equal(t, 69, fields[logging.UpdateIDField])
+		assert.Equal(t, int64(911), fields[logging.ChatIDField])
+		assert.Equal(t, "/start", fields[logging.CommandField])
+		return nil
+	}))
+
+	require.NoError(t, handler(botInstance.NewContext(update)))
+	require.NoError(t, handler(botInstance.NewContext(update)))
+	require.Len(t, requestIDs, 2)
+	assert.NotEqual(t, requestIDs[0], requestIDs[1], "each update must have its own request ID")
+}
+
+func TestWithTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previousProvider := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+	})
+
+	botInstance, err := tele.NewBot(tele.Settings{Offline: true})
+	require.NoError(t, err)
+
+	telegramBot := NewTelegramBot(botInstance, Requesters{})
+	telegramBot.DefineHandlers()
+	update := tele.Update{
+		ID: 69,
+		Message: &tele.Message{
+			Chat: &tele.Chat{ID: 911},
+			Text: "/start",
+		},
+	}
+
+	errHandler := errors.New("pum")
+	handler := telegramBot.withUpdateContext(telegramBot.withTracing(telegramBot.withLogger(func(c tele.Context) error {
+		assert.NotEmpty(t, logger(c).Data[logging.TraceIDField])
+		return errHandler
+	})))
+
+	err = handler(botInstance.NewContext(update))
+	assert.ErrorIs(t, err, errHandler)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "update /start", spans[0].Name())
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+}
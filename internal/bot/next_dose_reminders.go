@@ -0,0 +1,149 @@
+package bot
+
+import (
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"sort"
+	"sync"
+	"telegram-bot/internal/domain"
+	"telegram-bot/internal/utils/filestore"
+	"time"
+)
+
+const (
+	// NextDoseRemindersFile name of the file, in the data dir, where the next dose reminders are persisted
+	NextDoseRemindersFile = "next_dose_reminders.json"
+	// nextDoseReminderHour hour of the day in which the reminder about the next dose is sent
+	nextDoseReminderHour = "09:00"
+	// nextDoseRemindersSyncInterval frequency with which the reminders are synced with their treatments
+	nextDoseRemindersSyncInterval = time.Hour
+	// nextDoseReminderRefreshTimeout bounds the refreshes of the reminders made in the background of an update
+	nextDoseReminderRefreshTimeout = 10 * time.Second
+)
+
+// nextDoseReminder holds the notifications scheduled for the next dose of a treatment
+type nextDoseReminder struct {
+	TelegramID      int64     `json:"telegram_id"`
+	TreatmentID     string    `json:"treatment_id"`
+	NotificationIDs []string  `json:"notification_ids"`
+	NextTurn        time.Time `json:"next_turn"`
+}
+
+// nextDoseReminders keeps track of the reminders that each user asked for. Each reminder is identified
+// by the telegramID of the user and the ID of the treatment. If it has a path, the reminders are persisted in it
+type nextDoseReminders struct {
+	mu        sync.Mutex
+	reminders map[string]nextDoseReminder
+	path      string
+}
+
+func newNextDoseReminders() *nextDoseReminders {
+	return &nextDoseReminders{
+		reminders: make(map[string]nextDoseReminder),
+	}
+}
+
+// load adds the reminders persisted in the file, and persists the changes in it from now on
+func (ndr *nextDoseReminders) load(path string) error {
+	var reminders []nextDoseReminder
+	err := filestore.Load(path, &reminders)
+	if err != nil {
+		return err
+	}
+
+	ndr.mu.Lock()
+	defer ndr.mu.Unlock()
+
+	for _, reminder := range reminders {
+		ndr.reminders[reminderKey(reminder.TelegramID, reminder.TreatmentID)] = reminder
+	}
+	ndr.path = path
+
+	return nil
+}
+
+func (ndr *nextDoseReminders) get(telegramID int64, treatmentID string) (nextDoseReminder, bool) {
+	ndr.mu.Lock()
+	defer ndr.mu.Unlock()
+
+	reminder, found := ndr.reminders[reminderKey(telegramID, treatmentID)]
+	return reminder, found
+}
+
+// set stores the reminder of its user and treatment, replacing the previous one
+func (ndr *nextDoseReminders) set(reminder nextDoseReminder) {
+	ndr.mu.Lock()
+	defer ndr.mu.Unlock()
+
+	ndr.reminders[reminderKey(reminder.TelegramID, reminder.TreatmentID)] = reminder
+	ndr.saveLocked()
+}
+
+func (ndr *nextDoseReminders) remove(telegramID int64, treatmentID string) {
+	ndr.mu.Lock()
+	defer ndr.mu.Unlock()
+
+	delete(ndr.reminders, reminderKey(telegramID, treatmentID))
+	ndr.saveLocked()
+}
+
+// list returns the reminders sorted by telegram ID and treatment ID
+func (ndr *nextDoseReminders) list() []nextDoseReminder {
+	ndr.mu.Lock()
+	defer ndr.mu.Unlock()
+
+	return ndr.sortedLocked()
+}
+
+func (ndr *nextDoseReminders) sortedLocked() []nextDoseReminder {
+	reminders := make([]nextDoseReminder, 0, len(ndr.reminders))
+	for _, reminder := range ndr.reminders {
+		reminders = append(reminders, reminder)
+	}
+	sort.Slice(reminders, func(i, j int) bool {
+		if reminders[i].TelegramID != reminders[j].TelegramID {
+			return reminders[i].TelegramID < reminders[j].TelegramID
+		}
+		return reminders[i].TreatmentID < reminders[j].TreatmentID
+	})
+
+	return reminders
+}
+
+func (ndr *nextDoseReminders) saveLocked() {
+	err := filestore.Save(ndr.path, ndr.sortedLocked())
+	if err != nil {
+		logrus.Errorf("error persisting next dose reminders: %v", err)
+	}
+}
+
+func reminderKey(telegramID int64, treatmentID string) string {
+	return fmt.Sprintf("%d|%s", telegramID, treatmentID)
+}
+
+// newNextDoseNotificationRequest creates a notification request that is sent only once, on the day of the next dose
+func newNextDoseNotificationRequest(telegramID int64, treatment domain.Treatment) domain.NotificationRequest {
+	year, month, day := treatment.NextTurn.Date()
+	doseDate := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	message := fmt.Sprintf("Today is the next dose of %s", treatment.GetName())
+
+	return domain.NewNotificationRequest(
+		fmt.Sprint(telegramID),
+		message,
+		doseDate,
+		&doseDate,
+		[]string{nextDoseReminderHour},
+	)
+}
+
+// isUpcomingDate returns true if the given date is today or in the future
+func isUpcomingDate(date time.Time) bool {
+	year, month, day := time.Now().Date()
+	today := time.Date(year, month, day, 0, 0, 0, 0, date.Location())
+	return !date.Before(today)
+}
+
+// PersistNextDoseReminders loads the next dose reminders from the file and persists their changes in it
+func (tb *TelegramBot) PersistNextDoseReminders(path string) error {
+	return tb.nextDoseReminders.load(path)
+}
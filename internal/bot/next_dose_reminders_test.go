@@ -0,0 +1,155 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"github.com/enescakir/emoji"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tele "gopkg.in/telebot.v3"
+	"net/http"
+	"path/filepath"
+	"telegram-bot/internal/bot/internal/button"
+	"telegram-bot/internal/bot/internal/mock"
+	"telegram-bot/internal/domain"
+	"telegram-bot/internal/requester"
+	"telegram-bot/internal/telegramsim"
+	"testing"
+	"time"
+)
+
+func TestNewNextDoseNotificationRequest(t *testing.T) {
+	nextTurn, err := time.Parse(time.RFC3339, "2024-02-20T15:30:00-03:00")
+	require.NoError(t, err)
+	treatment := domain.Treatment{
+		ID:        "69",
+		Type:      "Antiparasitic",
+		DateStart: nextTurn.AddDate(0, -1, 0),
+		NextTurn:  &nextTurn,
+	}
+
+	notificationRequest := newNextDoseNotificationRequest(911, treatment)
+
+	expectedDate := time.Date(2024, time.February, 20, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, "911", notificationRequest.TelegramID)
+	assert.Equal(t, expectedDate, notificationRequest.StartDate)
+	require.NotNil(t, notificationRequest.EndDate)
+	assert.Equal(t, expectedDate, *notificationRequest.EndDate)
+	assert.Equal(t, []string{nextDoseReminderHour}, notificationRequest.Hours)
+	assert.Contains(t, notificationRequest.Message, treatment.GetName())
+}
+
+func TestIsUpcomingDate(t *testing.T) {
+	assert.True(t, isUpcomingDate(time.Now()))
+	assert.True(t, isUpcomingDate(time.Now().AddDate(0, 0, 1)))
+	assert.False(t, isUpcomingDate(time.Now().AddDate(0, 0, -1)))
+}
+
+func TestNextDoseReminders(t *testing.T) {
+	reminders := newNextDoseReminders()
+	reminder := nextDoseReminder{TelegramID: 911, TreatmentID: "69", NotificationIDs: []string{"1"}, NextTurn: time.Now()}
+
+	reminders.set(reminder)
+	storedReminder, found := reminders.get(911, "69")
+	assert.True(t, found)
+	assert.Equal(t, reminder, storedReminder)
+
+	_, found = reminders.get(912, "69")
+	assert.False(t, found)
+
+	reminders.remove(911, "69")
+	_, found = reminders.get(911, "69")
+	assert.False(t, found)
+}
+
+func TestNextDoseRemindersArePersisted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), NextDoseRemindersFile)
+	nextTurn := time.Date(2024, time.February, 20, 0, 0, 0, 0, time.UTC)
+	reminder := nextDoseReminder{TelegramID: 911, TreatmentID: "69", NotificationIDs: []string{"1"}, NextTurn: nextTurn}
+
+	reminders := newNextDoseReminders()
+	require.NoError(t, reminders.load(path))
+	reminders.set(reminder)
+	reminders.set(nextDoseReminder{TelegramID: 911, TreatmentID: "420", NextTurn: nextTurn})
+	reminders.remove(911, "420")
+
+	restarted := newNextDoseReminders()
+	require.NoError(t, restarted.load(path))
+	assert.Equal(t, []nextDoseReminder{reminder}, restarted.list())
+}
+
+func TestSyncNextDoseReminders(t *testing.T) {
+	notFoundErr := requester.NewRequestError(fmt.Errorf("not found"), http.StatusNotFound, "")
+	today := time.Now()
+	nextTurn := today.AddDate(0, 0, 7)
+	newNextTurn := today.AddDate(0, 0, 14)
+
+	ctrl := gomock.NewController(t)
+	treatmentsMock := mock.NewMockTreatmentsRequester(ctrl)
+	notificationsMock := mock.NewMockNotificationsRequester(ctrl)
+	telegramBot := NewTelegramBot(nil, Requesters{Treatments: treatmentsMock, Notifications: notificationsMock})
+
+	// Unchanged
+	telegramBot.nextDoseReminders.set(nextDoseReminder{TelegramID: 911, TreatmentID: "1", NotificationIDs: []string{"n1"}, NextTurn: nextTurn})
+	treatmentsMock.EXPECT().GetTreatment(gomock.Any(), "1").Return(domain.Treatment{ID: "1", NextTurn: &nextTurn}, nil)
+
+	// Next dose changed outside the bot
+	telegramBot.nextDoseReminders.set(nextDoseReminder{TelegramID: 911, TreatmentID: "2", NotificationIDs: []string{"n2"}, NextTurn: nextTurn})
+	treatmentsMock.EXPECT().GetTreatment(gomock.Any(), "2").Return(domain.Treatment{ID: "2", NextTurn: &newNextTurn}, nil)
+	notificationsMock.EXPECT().DeleteNotification(gomock.Any(), "n2").Return(nil)
+	notificationsMock.EXPECT().RegisterNotifications(gomock.Any(), gomock.Any()).Return([]domain.NotificationResponse{{ID: "n2-new"}}, nil)
+
+	// Treatment deleted
+	telegramBot.nextDoseReminders.set(nextDoseReminder{TelegramID: 911, TreatmentID: "3", NotificationIDs: []string{"n3"}, NextTurn: nextTurn})
+	treatmentsMock.EXPECT().GetTreatment(gomock.Any(), "3").Return(domain.Treatment{}, notFoundErr)
+	notificationsMock.EXPECT().DeleteNotification(gomock.Any(), "n3").Return(nil)
+
+	// Dose already passed
+	telegramBot.nextDoseReminders.set(nextDoseReminder{TelegramID: 911, TreatmentID: "4", NotificationIDs: []string{"n4"}, NextTurn: today.AddDate(0, 0, -1)})
+
+	telegramBot.syncNextDoseReminders(context.Background())
+
+	assert.Equal(t, []nextDoseReminder{
+		{TelegramID: 911, TreatmentID: "1", NotificationIDs: []string{"n1"}, NextTurn: nextTurn},
+		{TelegramID: 911, TreatmentID: "2", NotificationIDs: []string{"n2-new"}, NextTurn: newNextTurn},
+	}, telegramBot.nextDoseReminders.list())
+}
+
+func TestTreatmentRefreshesReminderInBackground(t *testing.T) {
+	nextTurn := time.Now().AddDate(0, 0, 7)
+	newNextTurn := time.Now().AddDate(0, 0, 14)
+
+	ctrl := gomock.NewController(t)
+	treatmentsMock := mock.NewMockTreatmentsRequester(ctrl)
+	notificationsMock := mock.NewMockNotificationsRequester(ctrl)
+	treatmentsMock.EXPECT().
+		GetTreatment(gomock.Any(), "2").
+		Return(domain.Treatment{ID: "2", Type: "Antiparasitic", NextTurn: &newNextTurn}, nil).
+		AnyTimes()
+
+	// The notifications service is slow, the treatment must be shown without waiting for it
+	release := make(chan struct{})
+	notificationsMock.EXPECT().DeleteNotification(gomock.Any(), "n2").DoAndReturn(func(context.Context, string) error {
+		<-release
+		return nil
+	})
+	notificationsMock.EXPECT().RegisterNotifications(gomock.Any(), gomock.Any()).Return([]domain.NotificationResponse{{ID: "n2-new"}}, nil)
+
+	telegramBot, telegram := startConversationTest(t, Requesters{Treatments: treatmentsMock, Notifications: notificationsMock})
+	telegramBot.nextDoseReminders.set(nextDoseReminder{TelegramID: registeredUser.ID, TreatmentID: "2", NotificationIDs: []string{"n2"}, NextTurn: nextTurn})
+
+	treatmentButton := tele.InlineButton{Data: "\f" + button.Treatment.Unique + "|2"}
+	telegram.PressButton(registeredUser, telegramsim.SentMessage{ChatID: registeredUser.ID}, treatmentButton)
+	treatment := nextMessage(t, telegram)
+	assert.Contains(t, treatment.Text, emoji.AlarmClock.String())
+	_, found := treatment.Button(button.NextDose.Unique)
+	assert.False(t, found)
+
+	close(release)
+	require.Eventually(t, func() bool {
+		reminder, _ := telegramBot.nextDoseReminders.get(registeredUser.ID, "2")
+		return reminder.NextTurn.Equal(newNextTurn)
+	}, replyTimeout, 10*time.Millisecond)
+	assert.Equal(t, []string{"n2-new"}, telegramBot.nextDoseReminders.list()[0].NotificationIDs)
+}
@@ -0,0 +1,66 @@
+package bot
+
+import (
+	"sync"
+	"time"
+)
+
+// commentTTL time that the user has to send the comment after clicking the add comment button
+const commentTTL = 10 * time.Minute
+
+type pendingComment struct {
+	treatmentID string
+	expiresAt   time.Time
+}
+
+// pendingComments keeps track of the users that are writing a comment for a treatment
+type pendingComments struct {
+	mu       sync.Mutex
+	comments map[int64]pendingComment
+}
+
+func newPendingComments() *pendingComments {
+	return &pendingComments{
+		comments: make(map[int64]pendingComment),
+	}
+}
+
+// start registers that the user is going to send a comment for the given treatment. If the user already had
+// a pending comment it is replaced. The comments of the users that never sent them are evicted once expired
+func (pc *pendingComments) start(telegramID int64, treatmentID string) {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+
+	now := time.Now()
+	pc.deleteExpiredLocked(now)
+	pc.comments[telegramID] = pendingComment{
+		treatmentID: treatmentID,
+		expiresAt:   now.Add(commentTTL),
+	}
+}
+
+func (pc *pendingComments) deleteExpiredLocked(now time.Time) {
+	for telegramID, comment := range pc.comments {
+		if now.After(comment.expiresAt) {
+			delete(pc.comments, telegramID)
+		}
+	}
+}
+
+// pop returns the treatmentID of the pending comment of the user and removes it. Expired comments are ignored
+func (pc *pendingComments) pop(telegramID int64) (string, bool) {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+
+	comment, found := pc.comments[telegramID]
+	if !found {
+		return "", false
+	}
+
+	delete(pc.comments, telegramID)
+	if time.Now().After(comment.expiresAt) {
+		return "", false
+	}
+
+	return comment.treatmentID, true
+}
@@ -0,0 +1,54 @@
+package bot
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestPendingComments(t *testing.T) {
+	comments := newPendingComments()
+
+	_, found := comments.pop(911)
+	assert.False(t, found)
+
+	comments.start(911, "69")
+	treatmentID, found := comments.pop(911)
+	assert.True(t, found)
+	assert.Equal(t, "69", treatmentID)
+
+	// pop removes the pending comment
+	_, found = comments.pop(911)
+	assert.False(t, found)
+}
+
+func TestPendingCommentsExpired(t *testing.T) {
+	comments := newPendingComments()
+	comments.comments[911] = pendingComment{
+		treatmentID: "69",
+		expiresAt:   time.Now().Add(-time.Second),
+	}
+
+	_, found := comments.pop(911)
+	assert.False(t, found)
+}
+
+func TestPendingCommentsExpiredAreEvicted(t *testing.T) {
+	comments := newPendingComments()
+	comments.comments[911] = pendingComment{
+		treatmentID: "69",
+		expiresAt:   time.Now().Add(-time.Second),
+	}
+	comments.comments[420] = pendingComment{
+		treatmentID: "13",
+		expiresAt:   time.Now().Add(time.Minute),
+	}
+
+	comments.start(69, "7")
+	assert.NotContains(t, comments.comments, int64(911))
+	assert.Len(t, comments.comments, 2)
+
+	treatmentID, found := comments.pop(420)
+	assert.True(t, found)
+	assert.Equal(t, "13", treatmentID)
+}
@@ -4,7 +4,6 @@ import (
 	"errors"
 	"fmt"
 	"github.com/enescakir/emoji"
-	"github.com/sirupsen/logrus"
 	tele "gopkg.in/telebot.v3"
 	"regexp"
 	"strconv"
@@ -92,9 +91,13 @@ func (tb *TelegramBot) createPetRecord(c tele.Context) error {
 
 	petRequest := NewPetRequest(petData, senderInfo.ID)
 
-	err = tb.requester.RegisterPet(petRequest)
+	err = tb.pets.RegisterPet(requestContext(c), petRequest)
+	if isServiceUnavailable(err) {
+		return c.Send(template.ServiceUnavailableMessage(template.PetsService))
+	}
+
 	if err != nil {
-		logrus.Errorf("error creating pet: %v", err)
+		logger(c).Errorf("error creating pet: %v", err)
 		return c.Send("Oops, something went wrong creating a record for your pet. Please, try again")
 	}
 
@@ -109,7 +112,7 @@ func (tb *TelegramBot) getPets(c tele.Context) error {
 		return errUserInfoNotFound
 	}
 
-	petsData, err := tb.requester.GetPetsByOwnerID(senderInfo.ID)
+	petsData, err := tb.pets.GetPetsByOwnerID(requestContext(c), senderInfo.ID)
 
 	var requestError requester.RequestError
 	ok := errors.As(err, &requestError)
@@ -117,8 +120,12 @@ func (tb *TelegramBot) getPets(c tele.Context) error {
 		return c.Send("You don't have any pet registered yet")
 	}
 
+	if isServiceUnavailable(err) {
+		return c.Send(template.ServiceUnavailableMessage(template.PetsService))
+	}
+
 	if err != nil {
-		logrus.Errorf("error gettins pets: %v", err)
+		logger(c).Errorf("error gettins pets: %v", err)
 		return c.Send("error searching your pets. Please, try again")
 	}
 
@@ -149,11 +156,11 @@ func (tb *TelegramBot) getPetInfo(c tele.Context) error {
 	petID := params[0]
 	petIDInt, err := strconv.Atoi(petID)
 	if err != nil {
-		logrus.Errorf("invalid petID: %s", petID)
+		logger(c).Errorf("invalid petID: %s", petID)
 		return c.Send(template.TryAgainMessage())
 	}
 
-	petData, err := tb.requester.GetPetData(petIDInt)
+	petData, err := tb.pets.GetPetData(requestContext(c), petIDInt)
 
 	var requestError requester.RequestError
 	ok := errors.As(err, &requestError)
@@ -161,8 +168,12 @@ func (tb *TelegramBot) getPetInfo(c tele.Context) error {
 		return c.Send("Cannot find information about the selected pet")
 	}
 
+	if isServiceUnavailable(err) {
+		return c.Send(template.ServiceUnavailableMessage(template.PetsService))
+	}
+
 	if err != nil {
-		logrus.Errorf("error fetching pet data: petID: %s - error: %v", petID, err)
+		logger(c).Errorf("error fetching pet data: petID: %s - error: %v", petID, err)
 		return c.Send(template.TryAgainMessage())
 	}
 
@@ -1,8 +1,15 @@
 package bot
 
 import (
+	"fmt"
+	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"net/http"
+	"telegram-bot/internal/bot/internal/mock"
+	"telegram-bot/internal/bot/internal/template"
+	"telegram-bot/internal/domain"
+	"telegram-bot/internal/requester"
 	"testing"
 )
 
@@ -134,3 +141,51 @@ func TestExtractPetDataCorrectly(t *testing.T) {
 		})
 	}
 }
+
+func TestGetPets(t *testing.T) {
+	pets := []domain.PetData{
+		{PetDataIdentifier: domain.PetDataIdentifier{ID: 1, Name: "Bachicha", Type: "dog"}},
+		{PetDataIdentifier: domain.PetDataIdentifier{ID: 2, Name: "Michi", Type: "cat"}},
+	}
+
+	testCases := []struct {
+		Name            string
+		Pets            []domain.PetData
+		RequesterErr    error
+		ExpectedMessage string
+		ExpectedButtons int
+	}{
+		{
+			Name:            "Pets are listed as buttons",
+			Pets:            pets,
+			ExpectedMessage: "Select a pet",
+			ExpectedButtons: 2,
+		},
+		{
+			Name:            "User without pets",
+			RequesterErr:    requester.NewRequestError(fmt.Errorf("pets not found"), http.StatusNotFound, ""),
+			ExpectedMessage: "You don't have any pet registered yet",
+		},
+		{
+			Name:            "Pets service is unavailable",
+			RequesterErr:    requester.NewRequestError(fmt.Errorf("circuit open"), http.StatusServiceUnavailable, ""),
+			ExpectedMessage: template.ServiceUnavailableMessage(template.PetsService),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			petsMock := mock.NewMockPetsRequester(gomock.NewController(t))
+			petsMock.EXPECT().
+				GetPetsByOwnerID(gomock.Any(), registeredUser.ID).
+				Return(testCase.Pets, testCase.RequesterErr)
+
+			_, telegram := startConversationTest(t, Requesters{Pets: petsMock})
+			telegram.SendText(registeredUser, getPets)
+
+			message := nextMessage(t, telegram)
+			assert.Equal(t, testCase.ExpectedMessage, message.Text)
+			assert.Len(t, message.Buttons(), testCase.ExpectedButtons)
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package bot
+
+import (
+	"context"
+	"telegram-bot/internal/domain"
+	"telegram-bot/internal/requester"
+)
+
+// PetsRequester fetches and registers the pets of the users
+type PetsRequester interface {
+	GetPetsByOwnerID(ctx context.Context, ownerID int64) ([]domain.PetData, error)
+	RegisterPet(ctx context.Context, petDataRequest domain.PetRequest) error
+	GetPetData(ctx context.Context, petID int) (domain.PetData, error)
+}
+
+// TreatmentsRequester fetches the medical records of the pets: treatments and vaccines
+type TreatmentsRequester interface {
+	GetTreatmentsByPetID(ctx context.Context, petID int) ([]domain.Treatment, error)
+	GetTreatment(ctx context.Context, treatmentID string) (domain.Treatment, error)
+	AddTreatmentComment(ctx context.Context, treatmentID string, commentRequest domain.CommentRequest) error
+	GetVaccines(ctx context.Context, petID int) ([]domain.Vaccine, error)
+}
+
+// UsersRequester fetches the information of the users
+type UsersRequester interface {
+	GetUserData(ctx context.Context, telegramID int64) (domain.UserInfo, error)
+}
+
+// NotificationsRequester registers and deletes the scheduled notifications of the users
+type NotificationsRequester interface {
+	RegisterNotifications(ctx context.Context, notificationRequest domain.NotificationRequest) ([]domain.NotificationResponse, error)
+	DeleteNotification(ctx context.Context, notificationID string) error
+}
+
+// Requesters services that TelegramBot uses. Each of them can be provided by a different implementation,
+// eg: a cached or an offline one
+type Requesters struct {
+	Pets          PetsRequester
+	Treatments    TreatmentsRequester
+	Users         UsersRequester
+	Notifications NotificationsRequester
+}
+
+// NewRequesters returns the Requesters that perform all the requests with serviceRequester
+func NewRequesters(serviceRequester *requester.Requester) Requesters {
+	return Requesters{
+		Pets:          serviceRequester,
+		Treatments:    serviceRequester,
+		Users:         serviceRequester,
+		Notifications: serviceRequester,
+	}
+}
@@ -1,20 +1,29 @@
 package bot
 
 import (
+	"context"
+	"encoding/base64"
 	"errors"
 	"fmt"
-	"github.com/sirupsen/logrus"
+	"github.com/enescakir/emoji"
 	tele "gopkg.in/telebot.v3"
+	"io"
 	"strconv"
 	"strings"
 	"telegram-bot/internal/bot/internal/button"
 	"telegram-bot/internal/bot/internal/template"
+	"telegram-bot/internal/domain"
 	"telegram-bot/internal/requester"
 	"telegram-bot/internal/utils"
 	"telegram-bot/internal/utils/formatter"
+	"telegram-bot/internal/utils/logging"
+	"time"
 )
 
-const treatmentsThreshold = 5
+const (
+	treatmentsThreshold = 5
+	maxCommentPhotoSize = 5 * 1024 * 1024
+)
 
 // showVaccines shows all the vaccines that were applied to the pet. The vaccines are ordered from most recent to oldest
 func (tb *TelegramBot) showVaccines(c tele.Context) error {
@@ -31,7 +40,7 @@ func (tb *TelegramBot) showVaccines(c tele.Context) error {
 		return c.Send(template.TryAgainMessage())
 	}
 
-	vaccines, err := tb.requester.GetVaccines(petIDInt)
+	vaccines, err := tb.treatments.GetVaccines(requestContext(c), petIDInt)
 
 	var requestError requester.RequestError
 	ok := errors.As(err, &requestError)
@@ -39,8 +48,12 @@ func (tb *TelegramBot) showVaccines(c tele.Context) error {
 		return c.Send("Cannot find vaccines for selected pet")
 	}
 
+	if isServiceUnavailable(err) {
+		return c.Send(template.ServiceUnavailableMessage(template.MedicalRecordsService))
+	}
+
 	if err != nil {
-		logrus.Errorf("error fetching vaccines: petID: %s - error: %v", petID, err)
+		logger(c).Errorf("error fetching vaccines: petID: %s - error: %v", petID, err)
 		return c.Send(template.TryAgainMessage())
 	}
 
@@ -66,18 +79,18 @@ func (tb *TelegramBot) medicalHistory(c tele.Context) error {
 	params := strings.Split(c.Data(), "|")
 
 	if len(params) != 1 {
-		logrus.Errorf("invalid amount of params in medicalHistory: %s", params)
+		logger(c).Errorf("invalid amount of params in medicalHistory: %s", params)
 		return c.Send(template.TryAgainMessage())
 	}
 
 	petID := params[0]
 	petIDInt, err := strconv.Atoi(petID)
 	if err != nil {
-		logrus.Errorf("invalid petID: %s", petID)
+		logger(c).Errorf("invalid petID: %s", petID)
 		return c.Send(template.TryAgainMessage())
 	}
 
-	allPetTreatments, err := tb.requester.GetTreatmentsByPetID(petIDInt)
+	allPetTreatments, err := tb.treatments.GetTreatmentsByPetID(requestContext(c), petIDInt)
 
 	var requestError requester.RequestError
 	ok := errors.As(err, &requestError)
@@ -85,8 +98,12 @@ func (tb *TelegramBot) medicalHistory(c tele.Context) error {
 		return c.Send("Cannot find treatments for selected pet")
 	}
 
+	if isServiceUnavailable(err) {
+		return c.Send(template.ServiceUnavailableMessage(template.MedicalRecordsService))
+	}
+
 	if err != nil {
-		logrus.Errorf("error fetching treatments: petID: %s - error: %v", petID, err)
+		logger(c).Errorf("error fetching treatments: petID: %s - error: %v", petID, err)
 		return c.Send(template.TryAgainMessage())
 	}
 
@@ -129,12 +146,16 @@ func (tb *TelegramBot) getTreatment(c tele.Context) error {
 	params := strings.Split(c.Data(), "|")
 
 	if len(params) != 1 {
-		logrus.Errorf("receive invalid amount of data in getTreatment: %v", params)
+		logger(c).Errorf("receive invalid amount of data in getTreatment: %v", params)
 		return c.Send(template.TryAgainMessage())
 	}
 
-	treatmentID := params[0]
-	treatment, err := tb.requester.GetTreatment(treatmentID)
+	return tb.sendTreatment(c, params[0])
+}
+
+// sendTreatment fetches the treatment and sends its information with the actions that can be performed on it
+func (tb *TelegramBot) sendTreatment(c tele.Context, treatmentID string) error {
+	treatment, err := tb.treatments.GetTreatment(requestContext(c), treatmentID)
 
 	var requestError requester.RequestError
 	ok := errors.As(err, &requestError)
@@ -142,8 +163,12 @@ func (tb *TelegramBot) getTreatment(c tele.Context) error {
 		return c.Send("Cannot find info about selected treatment")
 	}
 
+	if isServiceUnavailable(err) {
+		return c.Send(template.ServiceUnavailableMessage(template.MedicalRecordsService))
+	}
+
 	if err != nil {
-		logrus.Errorf("error fetching treatment: treatmentID: %s - error: %v\n", treatmentID, err)
+		logger(c).Errorf("error fetching treatment: treatmentID: %s - error: %v\n", treatmentID, err)
 		return c.Send(template.TryAgainMessage())
 	}
 
@@ -157,6 +182,22 @@ func (tb *TelegramBot) getTreatment(c tele.Context) error {
 		nextTurn = utils.DateToString(*treatment.NextTurn)
 	}
 
+	// If the next dose changed, the reminder is rescheduled in the background. It is kept while the treatment has
+	// an upcoming dose
+	senderInfo := c.Sender()
+	hasReminder := false
+	if senderInfo != nil {
+		reminder, found := tb.nextDoseReminders.get(senderInfo.ID, treatment.ID)
+		hasReminder = found && treatment.NextTurn != nil && isUpcomingDate(*treatment.NextTurn)
+		if found && (treatment.NextTurn == nil || !reminder.NextTurn.Equal(*treatment.NextTurn)) {
+			tb.refreshNextDoseReminderInBackground(requestContext(c), senderInfo.ID, treatment)
+		}
+	}
+
+	if hasReminder {
+		nextTurn += fmt.Sprintf(" %s", emoji.AlarmClock)
+	}
+
 	message := fmt.Sprintf(
 		"%s\n\nNext Turn: %s \nDate End: %s \nComments:\n",
 		formatter.Bold(treatment.GetName()),
@@ -170,5 +211,295 @@ func (tb *TelegramBot) getTreatment(c tele.Context) error {
 	}
 
 	message += formatter.UnorderedList(commentMessages)
-	return c.Send(message)
+
+	treatmentMenu := tb.bot.NewMarkup()
+	treatmentRows := []tele.Row{
+		treatmentMenu.Row(button.AddCommentButton(treatment.ID)),
+	}
+
+	if !hasReminder && treatment.NextTurn != nil && isUpcomingDate(*treatment.NextTurn) {
+		treatmentRows = append(treatmentRows, treatmentMenu.Row(button.NextDoseReminderButton(treatment.ID)))
+	}
+
+	treatmentMenu.Inline(treatmentRows...)
+
+	return c.Send(message, treatmentMenu)
+}
+
+// startTreatmentComment asks the user for the comment of the selected treatment. The next text or photo
+// that the user sends is used as the comment
+func (tb *TelegramBot) startTreatmentComment(c tele.Context) error {
+	senderInfo := c.Sender()
+	if senderInfo == nil {
+		_ = c.Send(errUserInfoNotFound.Error())
+		return errUserInfoNotFound
+	}
+
+	params := strings.Split(c.Data(), "|")
+	if len(params) != 1 {
+		logger(c).Errorf("receive invalid amount of data in startTreatmentComment: %v", params)
+		return c.Send(template.TryAgainMessage())
+	}
+
+	tb.pendingComments.start(senderInfo.ID, params[0])
+
+	return c.Send(fmt.Sprintf(
+		"Tell me how your pet is doing %s You can send a text or a photo with a caption",
+		emoji.Memo,
+	))
+}
+
+// addTreatmentComment adds the message of the user as a comment of the treatment. If the message contains a photo
+// it is attached to the comment and the caption is used as the comment information
+func (tb *TelegramBot) addTreatmentComment(c tele.Context, treatmentID string) error {
+	senderInfo := c.Sender()
+	message := c.Message()
+
+	commentRequest := domain.CommentRequest{
+		Information: strings.TrimSpace(message.Text),
+		Owner:       strings.TrimSpace(fmt.Sprintf("%s %s", senderInfo.FirstName, senderInfo.LastName)),
+	}
+
+	if message.Photo != nil {
+		commentRequest.Information = strings.TrimSpace(message.Caption)
+
+		photo, err := tb.downloadCommentPhoto(message.Photo)
+		if errors.Is(err, errPhotoTooLarge) {
+			tb.pendingComments.start(senderInfo.ID, treatmentID)
+			return c.Send("The photo is too large, please send a smaller one")
+		}
+
+		if err != nil {
+			logger(c).Errorf("error downloading comment photo: treatmentID: %s - error: %v", treatmentID, err)
+			return c.Send(template.TryAgainMessage())
+		}
+		commentRequest.Photo = photo
+	}
+
+	if commentRequest.Information == "" {
+		tb.pendingComments.start(senderInfo.ID, treatmentID)
+		return c.Send("The comment cannot be empty, please send it again")
+	}
+
+	err := tb.treatments.AddTreatmentComment(requestContext(c), treatmentID, commentRequest)
+	if isServiceUnavailable(err) {
+		return c.Send(template.ServiceUnavailableMessage(template.MedicalRecordsService))
+	}
+
+	if err != nil {
+		logger(c).Errorf("error adding comment: treatmentID: %s - error: %v", treatmentID, err)
+		return c.Send("Oops, something went wrong adding your comment. Please, try again")
+	}
+
+	err = c.Send("Comment added correctly")
+	if err != nil {
+		return err
+	}
+
+	return tb.sendTreatment(c, treatmentID)
+}
+
+// downloadCommentPhoto downloads the photo from Telegram and encodes it so it can be sent to the treatments service
+func (tb *TelegramBot) downloadCommentPhoto(photo *tele.Photo) (*domain.CommentPhoto, error) {
+	if photo.FileSize > maxCommentPhotoSize {
+		return nil, errPhotoTooLarge
+	}
+
+	reader, err := tb.bot.File(&photo.File)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+
+	rawPhoto, err := io.ReadAll(io.LimitReader(reader, maxCommentPhotoSize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rawPhoto) > maxCommentPhotoSize {
+		return nil, errPhotoTooLarge
+	}
+
+	return &domain.CommentPhoto{
+		FileName: fmt.Sprintf("%s.jpg", photo.UniqueID),
+		Content:  base64.StdEncoding.EncodeToString(rawPhoto),
+	}, nil
+}
+
+// setNextDoseReminder schedules a notification for the day of the next dose of the selected treatment
+func (tb *TelegramBot) setNextDoseReminder(c tele.Context) error {
+	senderInfo := c.Sender()
+	if senderInfo == nil {
+		_ = c.Send(errUserInfoNotFound.Error())
+		return errUserInfoNotFound
+	}
+
+	params := strings.Split(c.Data(), "|")
+	if len(params) != 1 {
+		logger(c).Errorf("receive invalid amount of data in setNextDoseReminder: %v", params)
+		return c.Send(template.TryAgainMessage())
+	}
+
+	treatmentID := params[0]
+	if _, found := tb.nextDoseReminders.get(senderInfo.ID, treatmentID); found {
+		return c.Send("You already have a reminder for the next dose of this treatment")
+	}
+
+	treatment, err := tb.treatments.GetTreatment(requestContext(c), treatmentID)
+
+	var requestError requester.RequestError
+	ok := errors.As(err, &requestError)
+	if ok && (requestError.IsNotFound() || requestError.IsNoContent()) {
+		return c.Send("Cannot find info about selected treatment")
+	}
+
+	if isServiceUnavailable(err) {
+		return c.Send(template.ServiceUnavailableMessage(template.MedicalRecordsService))
+	}
+
+	if err != nil {
+		logger(c).Errorf("error fetching treatment: treatmentID: %s - error: %v", treatmentID, err)
+		return c.Send(template.TryAgainMessage())
+	}
+
+	if treatment.NextTurn == nil || !isUpcomingDate(*treatment.NextTurn) {
+		return c.Send("This treatment does not have an upcoming dose")
+	}
+
+	err = tb.scheduleNextDoseReminder(requestContext(c), senderInfo.ID, treatment)
+	if isServiceUnavailable(err) {
+		return c.Send(template.ServiceUnavailableMessage(template.NotificationsService))
+	}
+
+	if err != nil {
+		logger(c).Errorf("error scheduling next dose reminder: treatmentID: %s - error: %v", treatmentID, err)
+		return c.Send(template.TryAgainMessage())
+	}
+
+	return c.Send(fmt.Sprintf(
+		"Done! I will remind you about the next dose on %s at %s %s",
+		utils.DateToString(*treatment.NextTurn),
+		nextDoseReminderHour,
+		emoji.AlarmClock,
+	))
+}
+
+// scheduleNextDoseReminder registers the notification for the next dose of the treatment and keeps track of it
+func (tb *TelegramBot) scheduleNextDoseReminder(ctx context.Context, telegramID int64, treatment domain.Treatment) error {
+	notificationRequest := newNextDoseNotificationRequest(telegramID, treatment)
+	notifications, err := tb.notifications.RegisterNotifications(ctx, notificationRequest)
+	if err != nil {
+		return err
+	}
+
+	var notificationIDs []string
+	for _, notification := range notifications {
+		notificationIDs = append(notificationIDs, notification.ID)
+	}
+
+	tb.nextDoseReminders.set(nextDoseReminder{
+		TelegramID:      telegramID,
+		TreatmentID:     treatment.ID,
+		NotificationIDs: notificationIDs,
+		NextTurn:        *treatment.NextTurn,
+	})
+
+	return nil
+}
+
+// refreshNextDoseReminder keeps the reminder of the user up to date with the treatment. If the next dose changed,
+// the old notifications are deleted and a new one is scheduled. If the treatment does not have an upcoming dose
+// anymore the reminder is removed. The refreshes are serialized, so a reminder is not rescheduled twice
+func (tb *TelegramBot) refreshNextDoseReminder(ctx context.Context, telegramID int64, treatment domain.Treatment) {
+	tb.reminderRefreshes.Lock()
+	defer tb.reminderRefreshes.Unlock()
+
+	reminder, found := tb.nextDoseReminders.get(telegramID, treatment.ID)
+	if !found {
+		return
+	}
+
+	if treatment.NextTurn != nil && reminder.NextTurn.Equal(*treatment.NextTurn) {
+		return
+	}
+
+	tb.dropNextDoseReminder(ctx, reminder)
+
+	if treatment.NextTurn == nil || !isUpcomingDate(*treatment.NextTurn) {
+		return
+	}
+
+	err := tb.scheduleNextDoseReminder(ctx, telegramID, treatment)
+	if err != nil {
+		logging.FromContext(ctx).Errorf("error rescheduling next dose reminder: treatmentID: %s - error: %v", treatment.ID, err)
+	}
+}
+
+// refreshNextDoseReminderInBackground refreshes the reminder without making the user wait for the notifications
+// service. The refresh outlives the update, so it is bounded by nextDoseReminderRefreshTimeout
+func (tb *TelegramBot) refreshNextDoseReminderInBackground(ctx context.Context, telegramID int64, treatment domain.Treatment) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), nextDoseReminderRefreshTimeout)
+	go func() {
+		defer cancel()
+		tb.refreshNextDoseReminder(ctx, telegramID, treatment)
+	}()
+}
+
+// dropNextDoseReminder deletes the notifications of the reminder and stops keeping track of it
+func (tb *TelegramBot) dropNextDoseReminder(ctx context.Context, reminder nextDoseReminder) {
+	// Best effort: if a notification cannot be deleted the user may receive an outdated reminder
+	for _, notificationID := range reminder.NotificationIDs {
+		err := tb.notifications.DeleteNotification(ctx, notificationID)
+		if err != nil {
+			logging.FromContext(ctx).Errorf("error deleting outdated next dose notification %s: %v", notificationID, err)
+		}
+	}
+	tb.nextDoseReminders.remove(reminder.TelegramID, reminder.TreatmentID)
+}
+
+// syncNextDoseReminders refreshes every reminder with its treatment, so the changes of the next dose made outside
+// the bot are applied. The reminders whose dose already passed are forgotten, their notifications were sent
+func (tb *TelegramBot) syncNextDoseReminders(ctx context.Context) {
+	for _, reminder := range tb.nextDoseReminders.list() {
+		if ctx.Err() != nil {
+			return
+		}
+
+		if !isUpcomingDate(reminder.NextTurn) {
+			tb.nextDoseReminders.remove(reminder.TelegramID, reminder.TreatmentID)
+			continue
+		}
+
+		treatment, err := tb.treatments.GetTreatment(ctx, reminder.TreatmentID)
+		if isNotFound(err) {
+			tb.dropNextDoseReminder(ctx, reminder)
+			continue
+		}
+
+		if err != nil {
+			logging.FromContext(ctx).Errorf("error syncing next dose reminder: treatmentID: %s - error: %v", reminder.TreatmentID, err)
+			continue
+		}
+
+		tb.refreshNextDoseReminder(ctx, reminder.TelegramID, treatment)
+	}
+}
+
+// runNextDoseRemindersSync syncs the reminders on start and every nextDoseRemindersSyncInterval until the context
+// is done, is a blocking function
+func (tb *TelegramBot) runNextDoseRemindersSync(ctx context.Context) {
+	ticker := time.NewTicker(nextDoseRemindersSyncInterval)
+	defer ticker.Stop()
+
+	for {
+		tb.syncNextDoseReminders(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
 }
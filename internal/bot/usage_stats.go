@@ -0,0 +1,101 @@
+package bot
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// usageStatsDays days of usage kept by usageStats, including today
+const usageStatsDays = 7
+
+// handlerUsage updates handled by a handler and how many of them failed
+type handlerUsage struct {
+	Handled int
+	Failed  int
+}
+
+func (hu handlerUsage) failureRate() float64 {
+	if hu.Handled == 0 {
+		return 0
+	}
+
+	return float64(hu.Failed) / float64(hu.Handled)
+}
+
+// dayUsage updates handled in a day, in total and by handler name
+type dayUsage struct {
+	Day string
+	handlerUsage
+	Handlers map[string]handlerUsage
+}
+
+// usageStats counts the updates handled per day and handler name, see handlerName. The names are bounded, so the
+// commands that the bot does not handle are counted together. Unlike the metrics, they are kept in the bot so the
+// admins can check them from Telegram
+type usageStats struct {
+	mu   sync.Mutex
+	now  func() time.Time
+	days map[string]map[string]handlerUsage
+}
+
+func newUsageStats() *usageStats {
+	return &usageStats{
+		now:  time.Now,
+		days: make(map[string]map[string]handlerUsage),
+	}
+}
+
+// record counts an update of the handler, named by handlerName, handled today. The days older than usageStatsDays are discarded
+func (us *usageStats) record(handler string, failed bool) {
+	us.mu.Lock()
+	defer us.mu.Unlock()
+
+	now := us.now()
+	day := now.Format(time.DateOnly)
+	handlers, ok := us.days[day]
+	if !ok {
+		handlers = make(map[string]handlerUsage)
+		us.days[day] = handlers
+	}
+
+	usage := handlers[handler]
+	usage.Handled++
+	if failed {
+		usage.Failed++
+	}
+	handlers[handler] = usage
+
+	oldestDay := now.AddDate(0, 0, 1-usageStatsDays).Format(time.DateOnly)
+	for day := range us.days {
+		if day < oldestDay {
+			delete(us.days, day)
+		}
+	}
+}
+
+// lastDays returns the usage of the days with updates, the most recent first
+func (us *usageStats) lastDays() []dayUsage {
+	us.mu.Lock()
+	defer us.mu.Unlock()
+
+	days := make([]dayUsage, 0, len(us.days))
+	for day, handlers := range us.days {
+		usage := dayUsage{
+			Day:      day,
+			Handlers: make(map[string]handlerUsage, len(handlers)),
+		}
+		for handler, handlerUsage := range handlers {
+			usage.Handled += handlerUsage.Handled
+			usage.Failed += handlerUsage.Failed
+			usage.Handlers[handler] = handlerUsage
+		}
+		days = append(days, usage)
+	}
+
+	sort.Slice(days, func(i, j int) bool {
+		return days[i].Day > days[j].Day
+	})
+
+	return days
+}
@@ -0,0 +1,41 @@
+package bot
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestUsageStats(t *testing.T) {
+	now := time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC)
+	stats := newUsageStats()
+	stats.now = func() time.Time {
+		return now
+	}
+
+	stats.record("/start", false)
+	stats.record("/start", true)
+	stats.record("/getPets", false)
+
+	now = now.AddDate(0, 0, 1)
+	stats.record("/start", false)
+
+	days := stats.lastDays()
+	require.Len(t, days, 2)
+	assert.Equal(t, "2024-06-11", days[0].Day)
+	assert.Equal(t, handlerUsage{Handled: 1}, days[0].handlerUsage)
+	assert.Equal(t, "2024-06-10", days[1].Day)
+	assert.Equal(t, handlerUsage{Handled: 3, Failed: 1}, days[1].handlerUsage)
+	assert.Equal(t, handlerUsage{Handled: 2, Failed: 1}, days[1].Handlers["/start"])
+	assert.Equal(t, 0.5, days[1].Handlers["/start"].failureRate())
+
+	// The first day is discarded once it is older than usageStatsDays
+	now = now.AddDate(0, 0, usageStatsDays-1)
+	stats.record("/help", false)
+
+	days = stats.lastDays()
+	require.Len(t, days, 2)
+	assert.Equal(t, "2024-06-17", days[0].Day)
+	assert.Equal(t, "2024-06-11", days[1].Day)
+}
@@ -1,10 +1,10 @@
 package bot
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/enescakir/emoji"
-	"github.com/sirupsen/logrus"
 	tele "gopkg.in/telebot.v3"
 	"regexp"
 	"strings"
@@ -15,6 +15,7 @@ import (
 	"telegram-bot/internal/requester"
 	"telegram-bot/internal/utils"
 	"telegram-bot/internal/utils/formatter"
+	"telegram-bot/internal/utils/logging"
 	"time"
 )
 
@@ -38,7 +39,11 @@ func (tb *TelegramBot) start(c tele.Context) error {
 		return errUserInfoNotFound
 	}
 
-	isRegistered, userInfo, err := tb.IsUserRegistered(senderInfo.ID)
+	isRegistered, userInfo, err := tb.IsUserRegistered(requestContext(c), senderInfo.ID)
+
+	if isServiceUnavailable(err) {
+		return c.Send(template.ServiceUnavailableMessage(template.UsersService))
+	}
 
 	if err != nil {
 		return c.Send("Oops, something went wrong searching your info. Please try again")
@@ -121,13 +126,13 @@ func (tb *TelegramBot) setAlarm(c tele.Context) error {
 // + Second: the user information
 //
 // + Third: an error if something occurs requesting the user information
-func (tb *TelegramBot) IsUserRegistered(telegramID int64) (bool, domain.UserInfo, error) {
-	userInfo, err := tb.requester.GetUserData(telegramID)
+func (tb *TelegramBot) IsUserRegistered(ctx context.Context, telegramID int64) (bool, domain.UserInfo, error) {
+	userInfo, err := tb.users.GetUserData(ctx, telegramID)
 
 	var requestError requester.RequestError
 	isRequestError := errors.As(err, &requestError)
 	if isRequestError && requestError.IsNotFound() {
-		logrus.Infof("user with telegramID %v not found", telegramID)
+		logging.FromContext(ctx).Infof("user with telegramID %v not found", telegramID)
 		return false, domain.UserInfo{}, nil
 	}
 
@@ -190,7 +195,11 @@ func (tb *TelegramBot) registerNotification(c tele.Context) error {
 		endDate,
 		hours,
 	)
-	notifications, err := tb.requester.RegisterNotifications(notificationRequest)
+	notifications, err := tb.notifications.RegisterNotifications(requestContext(c), notificationRequest)
+	if isServiceUnavailable(err) {
+		return c.Send(template.ServiceUnavailableMessage(template.NotificationsService))
+	}
+
 	if err != nil {
 		return c.Send(fmt.Sprintf("Oops, something went wrong creating the notifications. %s", tryAgainNotificationMessage))
 	}
@@ -1,8 +1,15 @@
 package bot
 
 import (
+	"context"
+	"fmt"
+	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"net/http"
+	"telegram-bot/internal/bot/internal/mock"
+	"telegram-bot/internal/domain"
+	"telegram-bot/internal/requester"
 	"testing"
 )
 
@@ -176,3 +183,51 @@ func TestExtractAlarmDataCorrectly(t *testing.T) {
 		})
 	}
 }
+
+func TestIsUserRegistered(t *testing.T) {
+	userInfo := domain.UserInfo{UserID: "69", FullName: "Lionel Andrés"}
+
+	testCases := []struct {
+		Name               string
+		UserInfo           domain.UserInfo
+		RequesterErr       error
+		ExpectedRegistered bool
+		ExpectsError       bool
+	}{
+		{
+			Name:               "User is registered",
+			UserInfo:           userInfo,
+			ExpectedRegistered: true,
+		},
+		{
+			Name:               "User is not found",
+			RequesterErr:       requester.NewRequestError(fmt.Errorf("user not found"), http.StatusNotFound, ""),
+			ExpectedRegistered: false,
+		},
+		{
+			Name:         "Error fetching the user",
+			RequesterErr: requester.NewRequestError(fmt.Errorf("internal error"), http.StatusInternalServerError, ""),
+			ExpectsError: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			usersMock := mock.NewMockUsersRequester(gomock.NewController(t))
+			usersMock.EXPECT().
+				GetUserData(gomock.Any(), int64(911)).
+				Return(testCase.UserInfo, testCase.RequesterErr)
+
+			telegramBot := NewTelegramBot(nil, Requesters{Users: usersMock})
+			isRegistered, user, err := telegramBot.IsUserRegistered(context.Background(), 911)
+			if testCase.ExpectsError {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, testCase.ExpectedRegistered, isRegistered)
+			assert.Equal(t, testCase.UserInfo, user)
+		})
+	}
+}
@@ -73,6 +73,19 @@ func (c Comment) GetDate() time.Time {
 	return c.DateAdded
 }
 
+// CommentRequest request body to add a comment to a treatment
+type CommentRequest struct {
+	Information string        `json:"information"`
+	Owner       string        `json:"owner"`
+	Photo       *CommentPhoto `json:"photo,omitempty"`
+}
+
+// CommentPhoto image attached to a comment. Content is encoded in base64
+type CommentPhoto struct {
+	FileName string `json:"file_name"`
+	Content  string `json:"content"`
+}
+
 type Vaccine struct {
 	Name          string
 	AmountOfDoses int
@@ -0,0 +1,33 @@
+package fakebackend
+
+import "github.com/gin-gonic/gin"
+
+// Each service has its own error format
+
+type petServiceError struct {
+	Status  int    `json:"status"`
+	Message string `json:"message"`
+}
+
+type treatmentServiceError struct {
+	Code int    `json:"code"`
+	Msg  string `json:"msg"`
+}
+
+type notificationServiceError struct {
+	StatusCode int    `json:"status_code"`
+	Message    string `json:"message"`
+}
+
+// petsError is also used by the users service
+func petsError(c *gin.Context, statusCode int, message string) {
+	c.JSON(statusCode, petServiceError{Status: statusCode, Message: message})
+}
+
+func treatmentsError(c *gin.Context, statusCode int, message string) {
+	c.JSON(statusCode, treatmentServiceError{Code: statusCode, Msg: message})
+}
+
+func notificationsError(c *gin.Context, statusCode int, message string) {
+	c.JSON(statusCode, notificationServiceError{StatusCode: statusCode, Message: message})
+}
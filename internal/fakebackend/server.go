@@ -0,0 +1,69 @@
+package fakebackend
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"net/http/httptest"
+)
+
+// Prefixes of each service, they match the paths of the base URLs in the config of the requester
+const (
+	PetsPrefix          = "/pets"
+	TreatmentsPrefix    = "/treatments"
+	UsersPrefix         = "/users"
+	NotificationsPrefix = "/notifications"
+)
+
+// Server fake of the pets, treatments, users and notifications services running on an httptest.Server
+type Server struct {
+	*httptest.Server
+	Store *Store
+}
+
+// NewServer starts a fake backend with the data of the store. It must be closed after being used
+func NewServer(store *Store) *Server {
+	return &Server{
+		Server: httptest.NewServer(NewHandler(store)),
+		Store:  store,
+	}
+}
+
+// ServiceURLs returns the env vars that point the requester to the fake services
+func (s *Server) ServiceURLs() map[string]string {
+	return map[string]string{
+		"PETS_SERVICE_URL":          s.URL + PetsPrefix,
+		"TREATMENTS_SERVICE_URL":    s.URL + TreatmentsPrefix,
+		"USERS_SERVICE_URL":         s.URL + UsersPrefix,
+		"NOTIFICATIONS_SERVICE_URL": s.URL + NotificationsPrefix,
+	}
+}
+
+// NewHandler returns the handler of the fake services. Each service is served under its prefix with the
+// endpoints defined in the config of the requester
+func NewHandler(store *Store) http.Handler {
+	gin.SetMode(gin.ReleaseMode)
+	engine := gin.New()
+	engine.Use(gin.Recovery())
+
+	services := fakeServices{store: store}
+
+	pets := engine.Group(PetsPrefix)
+	pets.POST("/pet", services.registerPet)
+	pets.GET("/owner/:ownerID", services.getPetsByOwner)
+	pets.GET("/pet/:petID", services.getPet)
+
+	treatments := engine.Group(TreatmentsPrefix)
+	treatments.GET("/treatment/pet/:petID", services.getTreatmentsByPet)
+	treatments.GET("/treatment/specific/:treatmentID", services.getTreatment)
+	treatments.POST("/treatment/:treatmentID/comment", services.addComment)
+	treatments.GET("/application/pet/:petID", services.getVaccines)
+
+	users := engine.Group(UsersPrefix)
+	users.GET("/telegram_id/:telegramID", services.getUser)
+
+	notifications := engine.Group(NotificationsPrefix)
+	notifications.POST("/notification", services.registerNotifications)
+	notifications.DELETE("/notification/:notificationID", services.deleteNotification)
+
+	return engine
+}
@@ -0,0 +1,207 @@
+package fakebackend
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"telegram-bot/internal/domain"
+	"time"
+)
+
+const (
+	headerTelegramID = "X-Telegram-Id"
+	defaultLimit     = 10
+)
+
+type fakeServices struct {
+	store *Store
+}
+
+// Pets service
+
+func (fs fakeServices) registerPet(c *gin.Context) {
+	var request domain.PetRequest
+	err := c.ShouldBindJSON(&request)
+	if err != nil {
+		petsError(c, http.StatusBadRequest, fmt.Sprintf("invalid pet: %v", err))
+		return
+	}
+
+	ownerID := c.GetHeader(headerTelegramID)
+	if ownerID == "" {
+		ownerID = request.OwnerID
+	}
+
+	if request.Name == "" || ownerID == "" {
+		petsError(c, http.StatusBadRequest, "name and owner are required")
+		return
+	}
+
+	birthDate, err := time.Parse(time.DateOnly, request.BirthDate)
+	if err != nil {
+		petsError(c, http.StatusBadRequest, fmt.Sprintf("invalid birth date %s", request.BirthDate))
+		return
+	}
+
+	pet := fs.store.AddPet(ownerID, domain.PetData{
+		PetDataIdentifier: domain.PetDataIdentifier{Name: request.Name, Type: request.Type},
+		BirthDate:         birthDate,
+	})
+
+	c.JSON(http.StatusCreated, pet)
+}
+
+func (fs fakeServices) getPetsByOwner(c *gin.Context) {
+	pets := fs.store.GetPetsByOwner(c.Param("ownerID"))
+	if len(pets) == 0 {
+		petsError(c, http.StatusNotFound, "owner does not have pets")
+		return
+	}
+
+	offset := queryInt(c, "offset", 0)
+	limit := queryInt(c, "limit", defaultLimit)
+	total := len(pets)
+
+	pets = pets[min(offset, total):min(offset+limit, total)]
+	c.JSON(http.StatusOK, domain.PetsResponse{
+		PetsData: pets,
+		Paging: domain.Paging{
+			Total:  uint(total),
+			Offset: uint(offset),
+			Limit:  uint(limit),
+		},
+	})
+}
+
+func (fs fakeServices) getPet(c *gin.Context) {
+	petID, err := strconv.Atoi(c.Param("petID"))
+	if err != nil {
+		petsError(c, http.StatusBadRequest, fmt.Sprintf("invalid pet ID %s", c.Param("petID")))
+		return
+	}
+
+	pet, found := fs.store.GetPet(petID)
+	if !found {
+		petsError(c, http.StatusNotFound, fmt.Sprintf("pet %d not found", petID))
+		return
+	}
+
+	c.JSON(http.StatusOK, pet)
+}
+
+// Treatments service
+
+func (fs fakeServices) getTreatmentsByPet(c *gin.Context) {
+	petID, err := strconv.Atoi(c.Param("petID"))
+	if err != nil {
+		treatmentsError(c, http.StatusBadRequest, fmt.Sprintf("invalid pet ID %s", c.Param("petID")))
+		return
+	}
+
+	treatments := fs.store.GetTreatmentsByPet(petID)
+	if len(treatments) == 0 {
+		treatmentsError(c, http.StatusNotFound, fmt.Sprintf("pet %d does not have treatments", petID))
+		return
+	}
+
+	c.JSON(http.StatusOK, treatments)
+}
+
+func (fs fakeServices) getTreatment(c *gin.Context) {
+	treatment, found := fs.store.GetTreatment(c.Param("treatmentID"))
+	if !found {
+		treatmentsError(c, http.StatusNotFound, fmt.Sprintf("treatment %s not found", c.Param("treatmentID")))
+		return
+	}
+
+	c.JSON(http.StatusOK, treatment)
+}
+
+func (fs fakeServices) addComment(c *gin.Context) {
+	var request domain.CommentRequest
+	err := c.ShouldBindJSON(&request)
+	if err != nil || request.Information == "" {
+		treatmentsError(c, http.StatusBadRequest, "invalid comment")
+		return
+	}
+
+	comment := domain.Comment{
+		DateAdded:   time.Now(),
+		Information: request.Information,
+		Owner:       request.Owner,
+	}
+	if !fs.store.AddComment(c.Param("treatmentID"), comment) {
+		treatmentsError(c, http.StatusNotFound, fmt.Sprintf("treatment %s not found", c.Param("treatmentID")))
+		return
+	}
+
+	c.JSON(http.StatusCreated, comment)
+}
+
+func (fs fakeServices) getVaccines(c *gin.Context) {
+	petID, err := strconv.Atoi(c.Param("petID"))
+	if err != nil {
+		treatmentsError(c, http.StatusBadRequest, fmt.Sprintf("invalid pet ID %s", c.Param("petID")))
+		return
+	}
+
+	vaccines := fs.store.GetVaccines(petID)
+	if len(vaccines) == 0 {
+		treatmentsError(c, http.StatusNotFound, fmt.Sprintf("pet %d does not have vaccines", petID))
+		return
+	}
+
+	c.JSON(http.StatusOK, vaccines)
+}
+
+// Users service
+
+func (fs fakeServices) getUser(c *gin.Context) {
+	user, found := fs.store.GetUser(c.Param("telegramID"))
+	if !found {
+		petsError(c, http.StatusNotFound, fmt.Sprintf("user %s not found", c.Param("telegramID")))
+		return
+	}
+
+	c.JSON(http.StatusOK, domain.UserServiceResponse{
+		UserData: user,
+		Code:     http.StatusOK,
+	})
+}
+
+// Notifications service
+
+func (fs fakeServices) registerNotifications(c *gin.Context) {
+	var request domain.NotificationRequest
+	err := c.ShouldBindJSON(&request)
+	if err != nil {
+		notificationsError(c, http.StatusBadRequest, fmt.Sprintf("invalid notification: %v", err))
+		return
+	}
+
+	if request.TelegramID == "" || len(request.Hours) == 0 {
+		notificationsError(c, http.StatusBadRequest, "telegram_id and hours are required")
+		return
+	}
+
+	c.JSON(http.StatusCreated, fs.store.AddNotifications(request))
+}
+
+func (fs fakeServices) deleteNotification(c *gin.Context) {
+	if !fs.store.DeleteNotification(c.Param("notificationID")) {
+		notificationsError(c, http.StatusNotFound, fmt.Sprintf("notification %s not found", c.Param("notificationID")))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func queryInt(c *gin.Context, key string, defaultValue int) int {
+	value, err := strconv.Atoi(c.Query(key))
+	if err != nil || value < 0 {
+		return defaultValue
+	}
+
+	return value
+}
@@ -0,0 +1,86 @@
+package fakebackend
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFakeServicesErrorFormats(t *testing.T) {
+	handler := NewHandler(NewStore())
+
+	testCases := []struct {
+		Name          string
+		Method        string
+		Path          string
+		Body          string
+		ExpectedCode  int
+		ExpectedError string
+	}{
+		{
+			Name:          "Pets service error",
+			Method:        http.MethodGet,
+			Path:          "/pets/pet/69",
+			ExpectedCode:  http.StatusNotFound,
+			ExpectedError: `{"status": 404, "message": "pet 69 not found"}`,
+		},
+		{
+			Name:          "Users service error",
+			Method:        http.MethodGet,
+			Path:          "/users/telegram_id/911",
+			ExpectedCode:  http.StatusNotFound,
+			ExpectedError: `{"status": 404, "message": "user 911 not found"}`,
+		},
+		{
+			Name:          "Treatments service error",
+			Method:        http.MethodGet,
+			Path:          "/treatments/treatment/specific/treatment-69",
+			ExpectedCode:  http.StatusNotFound,
+			ExpectedError: `{"code": 404, "msg": "treatment treatment-69 not found"}`,
+		},
+		{
+			Name:          "Notifications service error",
+			Method:        http.MethodPost,
+			Path:          "/notifications/notification",
+			Body:          `{"telegram_id": "911"}`,
+			ExpectedCode:  http.StatusBadRequest,
+			ExpectedError: `{"status_code": 400, "message": "telegram_id and hours are required"}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			request := httptest.NewRequest(testCase.Method, testCase.Path, bytes.NewBufferString(testCase.Body))
+			recorder := httptest.NewRecorder()
+
+			handler.ServeHTTP(recorder, request)
+
+			assert.Equal(t, testCase.ExpectedCode, recorder.Code)
+			assert.JSONEq(t, testCase.ExpectedError, recorder.Body.String())
+		})
+	}
+}
+
+func TestFakeServicesAutoRegister(t *testing.T) {
+	store := NewStore()
+	store.SetAutoRegister(true)
+	server := NewServer(store)
+	defer server.Close()
+
+	response, err := http.Get(server.URL + "/users/telegram_id/911")
+	require.NoError(t, err)
+	defer response.Body.Close()
+
+	var userResponse struct {
+		Data struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(response.Body).Decode(&userResponse))
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "user-911", userResponse.Data.ID)
+}
@@ -0,0 +1,313 @@
+package fakebackend
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"telegram-bot/internal/domain"
+	"time"
+)
+
+// Notification notification registered in the fake notifications service
+type Notification struct {
+	domain.NotificationResponse
+	TelegramID string
+	Message    string
+}
+
+type petRecord struct {
+	domain.PetData
+	ownerID string
+}
+
+type treatmentRecord struct {
+	domain.Treatment
+	petID int
+}
+
+// Store in-memory data of the fake services. It is safe for concurrent use
+type Store struct {
+	mu                 sync.Mutex
+	users              map[string]domain.UserInfo
+	pets               map[int]petRecord
+	treatments         map[string]treatmentRecord
+	vaccines           map[int][]domain.VaccineResponse
+	notifications      map[string]Notification
+	nextPetID          int
+	nextTreatmentID    int
+	nextNotificationID int
+	// autoRegister if true, unknown telegram users are registered when they are requested
+	autoRegister bool
+}
+
+func NewStore() *Store {
+	return &Store{
+		users:              make(map[string]domain.UserInfo),
+		pets:               make(map[int]petRecord),
+		treatments:         make(map[string]treatmentRecord),
+		vaccines:           make(map[int][]domain.VaccineResponse),
+		notifications:      make(map[string]Notification),
+		nextPetID:          1,
+		nextTreatmentID:    1,
+		nextNotificationID: 1,
+	}
+}
+
+// SetAutoRegister if enabled, any telegram user that is requested to the users service is registered, so
+// the bot can be used offline with any Telegram account
+func (s *Store) SetAutoRegister(enabled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.autoRegister = enabled
+}
+
+// AddUser registers a user with the given telegram ID
+func (s *Store) AddUser(telegramID string, user domain.UserInfo) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.users[telegramID] = user
+}
+
+// GetUser returns the user with the given telegram ID
+func (s *Store) GetUser(telegramID string) (domain.UserInfo, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, found := s.users[telegramID]
+	if !found && s.autoRegister {
+		user = domain.UserInfo{
+			UserID:   fmt.Sprintf("user-%s", telegramID),
+			FullName: "Offline Owner",
+			Email:    fmt.Sprintf("%s@petplace.local", telegramID),
+			City:     "Buenos Aires",
+		}
+		s.users[telegramID] = user
+		found = true
+	}
+
+	return user, found
+}
+
+// AddPet stores the pet of the owner and returns it with its ID
+func (s *Store) AddPet(ownerID string, pet domain.PetData) domain.PetData {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pet.ID = s.nextPetID
+	s.nextPetID++
+	s.pets[pet.ID] = petRecord{PetData: pet, ownerID: ownerID}
+
+	return pet
+}
+
+// GetPet returns the pet with the given ID
+func (s *Store) GetPet(petID int) (domain.PetData, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pet, found := s.pets[petID]
+	return pet.PetData, found
+}
+
+// GetPetsByOwner returns the pets of the owner ordered by ID
+func (s *Store) GetPetsByOwner(ownerID string) []domain.PetData {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var pets []domain.PetData
+	for _, pet := range s.pets {
+		if pet.ownerID == ownerID {
+			pets = append(pets, pet.PetData)
+		}
+	}
+
+	sort.Slice(pets, func(i, j int) bool {
+		return pets[i].ID < pets[j].ID
+	})
+
+	return pets
+}
+
+// AddTreatment stores the treatment of the pet and returns it with its ID
+func (s *Store) AddTreatment(petID int, treatment domain.Treatment) domain.Treatment {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	treatment.ID = fmt.Sprintf("treatment-%d", s.nextTreatmentID)
+	s.nextTreatmentID++
+	s.treatments[treatment.ID] = treatmentRecord{Treatment: treatment, petID: petID}
+
+	return treatment
+}
+
+// GetTreatment returns the treatment with the given ID
+func (s *Store) GetTreatment(treatmentID string) (domain.Treatment, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	treatment, found := s.treatments[treatmentID]
+	return treatment.Treatment, found
+}
+
+// GetTreatmentsByPet returns the treatments of the pet ordered by ID
+func (s *Store) GetTreatmentsByPet(petID int) []domain.Treatment {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var treatments []domain.Treatment
+	for _, treatment := range s.treatments {
+		if treatment.petID == petID {
+			treatments = append(treatments, treatment.Treatment)
+		}
+	}
+
+	sort.Slice(treatments, func(i, j int) bool {
+		return treatments[i].ID < treatments[j].ID
+	})
+
+	return treatments
+}
+
+// AddComment adds the comment to the treatment. Returns false if the treatment does not exist
+func (s *Store) AddComment(treatmentID string, comment domain.Comment) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	treatment, found := s.treatments[treatmentID]
+	if !found {
+		return false
+	}
+
+	treatment.Comments = append(treatment.Comments, comment)
+	treatment.LastModified = comment.DateAdded
+	s.treatments[treatmentID] = treatment
+
+	return true
+}
+
+// AddVaccine stores a dose of a vaccine applied to the pet
+func (s *Store) AddVaccine(petID int, vaccine domain.VaccineResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.vaccines[petID] = append(s.vaccines[petID], vaccine)
+}
+
+// GetVaccines returns the doses of vaccines applied to the pet
+func (s *Store) GetVaccines(petID int) []domain.VaccineResponse {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]domain.VaccineResponse(nil), s.vaccines[petID]...)
+}
+
+// AddNotifications registers one notification for each hour of the request
+func (s *Store) AddNotifications(request domain.NotificationRequest) []domain.NotificationResponse {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var responses []domain.NotificationResponse
+	for _, hour := range request.Hours {
+		response := domain.NotificationResponse{
+			ID:        fmt.Sprintf("notification-%d", s.nextNotificationID),
+			StartDate: request.StartDate,
+			EndDate:   request.EndDate,
+			Hour:      hour,
+		}
+		s.nextNotificationID++
+
+		s.notifications[response.ID] = Notification{
+			NotificationResponse: response,
+			TelegramID:           request.TelegramID,
+			Message:              request.Message,
+		}
+		responses = append(responses, response)
+	}
+
+	return responses
+}
+
+// DeleteNotification removes the notification. Returns false if it does not exist
+func (s *Store) DeleteNotification(notificationID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, found := s.notifications[notificationID]
+	delete(s.notifications, notificationID)
+
+	return found
+}
+
+// GetNotifications returns the notifications registered for the telegram user ordered by ID
+func (s *Store) GetNotifications(telegramID string) []Notification {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var notifications []Notification
+	for _, notification := range s.notifications {
+		if notification.TelegramID == telegramID {
+			notifications = append(notifications, notification)
+		}
+	}
+
+	sort.Slice(notifications, func(i, j int) bool {
+		return notifications[i].ID < notifications[j].ID
+	})
+
+	return notifications
+}
+
+// Seed stores sample data for the owner: its user, two pets with treatments and vaccines
+func (s *Store) Seed(ownerTelegramID string) {
+	now := time.Now()
+	nextWeek := now.AddDate(0, 0, 7)
+
+	s.AddUser(ownerTelegramID, domain.UserInfo{
+		UserID:   fmt.Sprintf("user-%s", ownerTelegramID),
+		FullName: "Lionel Andrés",
+		Email:    "lionel@petplace.local",
+		City:     "Rosario",
+	})
+
+	salchicha := s.AddPet(ownerTelegramID, domain.PetData{
+		PetDataIdentifier: domain.PetDataIdentifier{Name: "Bachicha", Type: "dog"},
+		BirthDate:         now.AddDate(-3, -2, 0),
+		Race:              "dachshund",
+	})
+	michi := s.AddPet(ownerTelegramID, domain.PetData{
+		PetDataIdentifier: domain.PetDataIdentifier{Name: "Michi", Type: "cat"},
+		BirthDate:         now.AddDate(-1, -5, 0),
+	})
+
+	s.AddTreatment(salchicha.ID, domain.Treatment{
+		Type: "Medical appointment",
+		Comments: []domain.Comment{
+			{DateAdded: now.AddDate(0, -1, 0), Information: "Back pain, needs rest", Owner: "Dr. Favaloro"},
+		},
+		DateStart:    now.AddDate(0, -1, 0),
+		LastModified: now.AddDate(0, -1, 0),
+		NextTurn:     &nextWeek,
+	})
+	treatmentEnd := now.AddDate(0, -2, 7)
+	s.AddTreatment(salchicha.ID, domain.Treatment{
+		Type: "Antibiotics",
+		Comments: []domain.Comment{
+			{DateAdded: now.AddDate(0, -2, 0), Information: "One pill every 12 hours", Owner: "Dr. Favaloro"},
+		},
+		DateStart:    now.AddDate(0, -2, 0),
+		LastModified: now.AddDate(0, -2, 0),
+		DateEnd:      &treatmentEnd,
+	})
+	s.AddTreatment(michi.ID, domain.Treatment{
+		Type:         "Castration",
+		DateStart:    now.AddDate(0, -3, 0),
+		LastModified: now.AddDate(0, -3, 0),
+	})
+
+	s.AddVaccine(salchicha.ID, domain.VaccineResponse{ID: "vaccine-1", Name: "Rabies", Date: now.AddDate(-1, 0, 0)})
+	s.AddVaccine(salchicha.ID, domain.VaccineResponse{ID: "vaccine-2", Name: "Rabies", Date: now.AddDate(0, -1, 0)})
+	s.AddVaccine(salchicha.ID, domain.VaccineResponse{ID: "vaccine-3", Name: "Parvovirus", Date: now.AddDate(0, -6, 0)})
+	s.AddVaccine(michi.ID, domain.VaccineResponse{ID: "vaccine-4", Name: "Triple feline", Date: now.AddDate(0, -4, 0)})
+}
@@ -0,0 +1,102 @@
+// Package health exposes the liveness and readiness probes of telegramer
+package health
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+// Check returns an error if the dependency is not ready
+type Check func(ctx context.Context) error
+
+// CheckResult result of a single check of the readiness probe
+type CheckResult struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Report result of the readiness probe: it is up only if all the checks are up
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+// Checker runs the checks of the readiness probe. Each check has timeout to finish, otherwise it is down
+type Checker struct {
+	timeout time.Duration
+	checks  map[string]Check
+}
+
+func NewChecker(timeout time.Duration) *Checker {
+	return &Checker{
+		timeout: timeout,
+		checks:  make(map[string]Check),
+	}
+}
+
+// AddCheck adds a check to the readiness probe, the name is used as its key in the Report.
+// Must be called before RegisterRoutes
+func (hc *Checker) AddCheck(name string, check Check) {
+	hc.checks[name] = check
+}
+
+// RegisterRoutes registers the probes, they do not require authentication:
+//
+// + GET /healthz: the process is alive
+//
+// + GET /readyz: the dependencies are ready, responds 503 with the Report if any of them is not
+func (hc *Checker) RegisterRoutes(r *gin.Engine) {
+	r.GET("/healthz", func(c *gin.Context) {
+		c.JSON(http.StatusOK, Report{Status: StatusUp})
+	})
+	r.GET("/readyz", func(c *gin.Context) {
+		report := hc.Ready(c.Request.Context())
+		statusCode := http.StatusOK
+		if report.Status != StatusUp {
+			statusCode = http.StatusServiceUnavailable
+		}
+
+		c.JSON(statusCode, report)
+	})
+}
+
+// Ready runs all the checks concurrently and returns their results
+func (hc *Checker) Ready(ctx context.Context) Report {
+	ctx, cancel := context.WithTimeout(ctx, hc.timeout)
+	defer cancel()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	report := Report{
+		Status: StatusUp,
+		Checks: make(map[string]CheckResult, len(hc.checks)),
+	}
+	for name, check := range hc.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result := CheckResult{Status: StatusUp}
+			if err := check(ctx); err != nil {
+				result = CheckResult{Status: StatusDown, Error: err.Error()}
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[name] = result
+			if result.Status != StatusUp {
+				report.Status = StatusDown
+			}
+		}()
+	}
+	wg.Wait()
+
+	return report
+}
@@ -0,0 +1,111 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func upCheck(context.Context) error {
+	return nil
+}
+
+func downCheck(context.Context) error {
+	return errors.New("pum")
+}
+
+func slowCheck(ctx context.Context) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestReady(t *testing.T) {
+	testCases := []struct {
+		Name           string
+		Checks         map[string]Check
+		ExpectedReport Report
+	}{
+		{
+			Name:           "Without checks",
+			ExpectedReport: Report{Status: StatusUp, Checks: map[string]CheckResult{}},
+		},
+		{
+			Name:   "All checks are up",
+			Checks: map[string]Check{"telegram": upCheck, "pets_service": upCheck},
+			ExpectedReport: Report{
+				Status: StatusUp,
+				Checks: map[string]CheckResult{
+					"telegram":     {Status: StatusUp},
+					"pets_service": {Status: StatusUp},
+				},
+			},
+		},
+		{
+			Name:   "A check is down",
+			Checks: map[string]Check{"telegram": upCheck, "pets_service": downCheck},
+			ExpectedReport: Report{
+				Status: StatusDown,
+				Checks: map[string]CheckResult{
+					"telegram":     {Status: StatusUp},
+					"pets_service": {Status: StatusDown, Error: "pum"},
+				},
+			},
+		},
+		{
+			Name:   "A check times out",
+			Checks: map[string]Check{"telegram": slowCheck},
+			ExpectedReport: Report{
+				Status: StatusDown,
+				Checks: map[string]CheckResult{
+					"telegram": {Status: StatusDown, Error: context.DeadlineExceeded.Error()},
+				},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			checker := NewChecker(10 * time.Millisecond)
+			for name, check := range testCase.Checks {
+				checker.AddCheck(name, check)
+			}
+
+			assert.Equal(t, testCase.ExpectedReport, checker.Ready(context.Background()))
+		})
+	}
+}
+
+func TestRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	checker := NewChecker(time.Second)
+	checker.AddCheck("telegram", upCheck)
+	checker.AddCheck("pets_service", downCheck)
+	checker.RegisterRoutes(engine)
+
+	t.Run("Liveness", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		engine.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.JSONEq(t, `{"status": "up"}`, recorder.Body.String())
+	})
+
+	t.Run("Readiness", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		engine.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+
+		var report Report
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &report))
+		assert.Equal(t, StatusDown, report.Status)
+		assert.Equal(t, CheckResult{Status: StatusDown, Error: "pum"}, report.Checks["pets_service"])
+	})
+}
@@ -0,0 +1,165 @@
+package requester
+
+import (
+	"context"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"sync"
+	"telegram-bot/internal/requester/internal/config"
+	"time"
+)
+
+type circuitState int
+
+const (
+	circuitClosed circuitState = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+func (cs circuitState) String() string {
+	switch cs {
+	case circuitOpen:
+		return "open"
+	case circuitHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+type callOutcome int
+
+const (
+	callSucceeded callOutcome = iota
+	callFailed
+	// callIgnored the call says nothing about the health of the service, eg: it was canceled by the caller
+	callIgnored
+)
+
+// circuitBreaker stops performing calls to a service after failureThreshold consecutive failures. While the
+// circuit is open the calls fail fast, once openTimeout elapses a single call is allowed to probe the service:
+// if it succeeds the circuit is closed again, otherwise it is reopened. A nil circuitBreaker allows every call
+type circuitBreaker struct {
+	mu                  sync.Mutex
+	service             string
+	failureThreshold    int
+	openTimeout         time.Duration
+	state               circuitState
+	consecutiveFailures int
+	openedAt            time.Time
+	probing             bool
+	// generation changes with the state, so the outcome of a call allowed in a previous state is discarded
+	generation uint64
+	now        func() time.Time
+}
+
+// circuitCall identifies a call allowed by the circuit breaker
+type circuitCall struct {
+	generation uint64
+	// probe the call was allowed to probe the service while the circuit was half-open
+	probe bool
+}
+
+// newCircuitBreaker returns nil if the service does not have a circuit breaker configured
+func newCircuitBreaker(service string, breakerConfig *config.CircuitBreaker) *circuitBreaker {
+	if breakerConfig == nil {
+		return nil
+	}
+
+	return &circuitBreaker{
+		service:          service,
+		failureThreshold: breakerConfig.FailureThreshold,
+		openTimeout:      breakerConfig.OpenTimeout.Duration,
+		now:              time.Now,
+	}
+}
+
+// allow returns true if the call can be performed. When it returns true, the outcome of the call must be
+// reported with record along with the returned circuitCall
+func (cb *circuitBreaker) allow() (circuitCall, bool) {
+	if cb == nil {
+		return circuitCall{}, true
+	}
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	switch cb.state {
+	case circuitOpen:
+		if cb.now().Sub(cb.openedAt) < cb.openTimeout {
+			return circuitCall{}, false
+		}
+		cb.setState(circuitHalfOpen)
+		cb.probing = true
+		return circuitCall{generation: cb.generation, probe: true}, true
+	case circuitHalfOpen:
+		if cb.probing {
+			return circuitCall{}, false
+		}
+		cb.probing = true
+		return circuitCall{generation: cb.generation, probe: true}, true
+	default:
+		return circuitCall{generation: cb.generation}, true
+	}
+}
+
+// record updates the state of the circuit with the outcome of a call that was allowed. The outcomes of the
+// calls allowed before the last change of state are discarded, eg: a slow call that started while the circuit
+// was closed and finished while it was half-open is not taken as the probe
+func (cb *circuitBreaker) record(call circuitCall, outcome callOutcome) {
+	if cb == nil {
+		return
+	}
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if call.generation != cb.generation {
+		return
+	}
+
+	if call.probe {
+		cb.probing = false
+	}
+
+	switch outcome {
+	case callSucceeded:
+		cb.consecutiveFailures = 0
+		if call.probe {
+			cb.setState(circuitClosed)
+		}
+	case callFailed:
+		cb.consecutiveFailures++
+		if call.probe || cb.consecutiveFailures >= cb.failureThreshold {
+			cb.openedAt = cb.now()
+			cb.setState(circuitOpen)
+		}
+	}
+}
+
+func (cb *circuitBreaker) setState(state circuitState) {
+	if cb.state == state {
+		return
+	}
+
+	logrus.Warnf("circuit breaker of %s changed from %s to %s", cb.service, cb.state, state)
+	cb.state = state
+	cb.generation++
+}
+
+// getCallOutcome classifies the result of a call: transport errors, timeouts and 5xx responses are failures
+func getCallOutcome(ctx context.Context, response *http.Response, err error) callOutcome {
+	if err != nil {
+		if ctx.Err() == context.Canceled {
+			return callIgnored
+		}
+		return callFailed
+	}
+
+	if response == nil || response.StatusCode >= http.StatusInternalServerError {
+		return callFailed
+	}
+
+	return callSucceeded
+}
@@ -0,0 +1,206 @@
+package requester
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"telegram-bot/internal/requester/internal/config"
+	"telegram-bot/internal/requester/internal/mock"
+	"testing"
+	"time"
+)
+
+func newTestCircuitBreaker(now *time.Time) *circuitBreaker {
+	breaker := newCircuitBreaker(treatmentsServiceName, &config.CircuitBreaker{
+		FailureThreshold: 2,
+		OpenTimeout:      config.Duration{Duration: time.Minute},
+	})
+	breaker.now = func() time.Time { return *now }
+	return breaker
+}
+
+// performCall asks the breaker to perform a call and records its outcome, it returns false if it was not allowed
+func performCall(breaker *circuitBreaker, outcome callOutcome) bool {
+	call, allowed := breaker.allow()
+	if allowed {
+		breaker.record(call, outcome)
+	}
+	return allowed
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	t.Run("Nil circuit breaker allows every call", func(t *testing.T) {
+		var breaker *circuitBreaker
+		assert.True(t, performCall(breaker, callFailed))
+		_, allowed := breaker.allow()
+		assert.True(t, allowed)
+	})
+
+	t.Run("Opens after consecutive failures", func(t *testing.T) {
+		now := time.Now()
+		breaker := newTestCircuitBreaker(&now)
+
+		require.True(t, performCall(breaker, callFailed))
+		require.True(t, performCall(breaker, callSucceeded))
+		require.True(t, performCall(breaker, callFailed))
+		assert.Equal(t, circuitClosed, breaker.state)
+
+		require.True(t, performCall(breaker, callFailed))
+		assert.Equal(t, circuitOpen, breaker.state)
+		_, allowed := breaker.allow()
+		assert.False(t, allowed)
+	})
+
+	t.Run("Half opens after open timeout and closes if the probe succeeds", func(t *testing.T) {
+		now := time.Now()
+		breaker := newTestCircuitBreaker(&now)
+		performCall(breaker, callFailed)
+		performCall(breaker, callFailed)
+
+		now = now.Add(time.Minute)
+		probe, allowed := breaker.allow()
+		require.True(t, allowed)
+		assert.True(t, probe.probe)
+		assert.Equal(t, circuitHalfOpen, breaker.state)
+		_, allowed = breaker.allow()
+		assert.False(t, allowed, "only one probe at a time")
+
+		breaker.record(probe, callSucceeded)
+		assert.Equal(t, circuitClosed, breaker.state)
+		assert.True(t, performCall(breaker, callSucceeded))
+	})
+
+	t.Run("Reopens if the probe fails", func(t *testing.T) {
+		now := time.Now()
+		breaker := newTestCircuitBreaker(&now)
+		performCall(breaker, callFailed)
+		performCall(breaker, callFailed)
+
+		now = now.Add(time.Minute)
+		require.True(t, performCall(breaker, callFailed))
+		assert.Equal(t, circuitOpen, breaker.state)
+		_, allowed := breaker.allow()
+		assert.False(t, allowed)
+	})
+
+	t.Run("Ignored probe lets another call probe", func(t *testing.T) {
+		now := time.Now()
+		breaker := newTestCircuitBreaker(&now)
+		performCall(breaker, callFailed)
+		performCall(breaker, callFailed)
+
+		now = now.Add(time.Minute)
+		require.True(t, performCall(breaker, callIgnored))
+		assert.Equal(t, circuitHalfOpen, breaker.state)
+		_, allowed := breaker.allow()
+		assert.True(t, allowed)
+	})
+
+	t.Run("Calls allowed before the circuit half opens are not taken as the probe", func(t *testing.T) {
+		now := time.Now()
+		breaker := newTestCircuitBreaker(&now)
+		slowCall, allowed := breaker.allow()
+		require.True(t, allowed)
+		performCall(breaker, callFailed)
+		performCall(breaker, callFailed)
+
+		now = now.Add(time.Minute)
+		probe, allowed := breaker.allow()
+		require.True(t, allowed)
+
+		breaker.record(slowCall, callSucceeded)
+		assert.Equal(t, circuitHalfOpen, breaker.state)
+		_, allowed = breaker.allow()
+		assert.False(t, allowed, "the probe is still in flight")
+
+		breaker.record(probe, callFailed)
+		assert.Equal(t, circuitOpen, breaker.state)
+	})
+}
+
+func TestGetCallOutcome(t *testing.T) {
+	cancelledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	testCases := []struct {
+		Name            string
+		Ctx             context.Context
+		Response        *http.Response
+		Err             error
+		ExpectedOutcome callOutcome
+	}{
+		{
+			Name:            "Transport error",
+			Ctx:             context.Background(),
+			Err:             fmt.Errorf("connection refused"),
+			ExpectedOutcome: callFailed,
+		},
+		{
+			Name:            "Canceled by the caller",
+			Ctx:             cancelledCtx,
+			Err:             context.Canceled,
+			ExpectedOutcome: callIgnored,
+		},
+		{
+			Name:            "Nil response",
+			Ctx:             context.Background(),
+			ExpectedOutcome: callFailed,
+		},
+		{
+			Name:            "Server error",
+			Ctx:             context.Background(),
+			Response:        &http.Response{StatusCode: http.StatusBadGateway},
+			ExpectedOutcome: callFailed,
+		},
+		{
+			Name:            "Client error means the service is up",
+			Ctx:             context.Background(),
+			Response:        &http.Response{StatusCode: http.StatusNotFound},
+			ExpectedOutcome: callSucceeded,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			assert.Equal(t, testCase.ExpectedOutcome, getCallOutcome(testCase.Ctx, testCase.Response, testCase.Err))
+		})
+	}
+}
+
+func TestDoRequestFailsFastWhenCircuitIsOpen(t *testing.T) {
+	now := time.Now()
+	clientMock := mock.NewMockhttpClienter(gomock.NewController(t))
+	clientMock.EXPECT().
+		Do(gomock.Any()).
+		Return(nil, fmt.Errorf("connection refused")).
+		Times(2)
+
+	requester := Requester{
+		TreatmentsService: config.ServiceEndpoints{
+			Endpoints: map[string]config.Endpoint{"get_vaccines": {Path: "/vaccines", Method: http.MethodGet}},
+		},
+		clientHTTP: clientMock,
+		breakers:   map[string]*circuitBreaker{treatmentsServiceName: newTestCircuitBreaker(&now)},
+	}
+	data := requestData{
+		operation:     "GetVaccines",
+		serviceName:   treatmentsServiceName,
+		endpointAlias: "get_vaccines",
+	}
+
+	for i := 0; i < 2; i++ {
+		_, err := doRequest[testErrorResponse](context.Background(), &requester, data)
+		assert.ErrorIs(t, err, errPerformingRequest)
+	}
+
+	_, err := doRequest[testErrorResponse](context.Background(), &requester, data)
+
+	var requestErr RequestError
+	require.True(t, errors.As(err, &requestErr))
+	assert.True(t, requestErr.IsServiceUnavailable())
+	assert.ErrorIs(t, err, errServiceUnavailable)
+}
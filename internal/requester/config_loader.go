@@ -0,0 +1,81 @@
+package requester
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"telegram-bot/internal/requester/internal/config"
+)
+
+const (
+	defaultConfigFilePath = "internal/requester/internal/config/config.json"
+	// configPathEnv path of the base config file, if it is not set defaultConfigFilePath is used
+	configPathEnv = "REQUESTER_CONFIG_PATH"
+	// environmentEnv name of the environment whose config file overrides the base one, eg: local
+	environmentEnv = "REQUESTER_ENV"
+)
+
+var errInvalidConfig = errors.New("error invalid requester config")
+
+// serviceBaseURLEnvs env vars that override the base URL of each service
+var serviceBaseURLEnvs = map[string]string{
+	petsServiceName:          "PETS_SERVICE_URL",
+	treatmentsServiceName:    "TREATMENTS_SERVICE_URL",
+	usersServiceName:         "USERS_SERVICE_URL",
+	notificationsServiceName: "NOTIFICATIONS_SERVICE_URL",
+}
+
+// requiredEndpoints endpoints of each service that the requester calls
+var requiredEndpoints = map[string][]string{
+	petsServiceName:          {getPets, registerPet, getPetByID},
+	treatmentsServiceName:    {getPetTreatments, getTreatment, addTreatmentComment, getVaccines},
+	usersServiceName:         {getUser},
+	notificationsServiceName: {scheduleNotifications, deleteNotification},
+}
+
+// configFilePath returns the path of the base config file
+func configFilePath() string {
+	if path := os.Getenv(configPathEnv); path != "" {
+		return path
+	}
+
+	return defaultConfigFilePath
+}
+
+// loadConfig builds the config of the services from its layers: the base file, the file of the environment
+// and the env vars that override the base URLs. The result is validated and all the problems found are returned
+func loadConfig() (Requester, error) {
+	path := configFilePath()
+	rawConfig, err := config.LoadLayers(path, os.Getenv(environmentEnv))
+	if err != nil {
+		return Requester{}, err
+	}
+
+	var requester Requester
+	err = json.Unmarshal(rawConfig, &requester)
+	if err != nil {
+		return Requester{}, fmt.Errorf("%w %s: %w", errInvalidConfig, path, err)
+	}
+
+	services := requester.services()
+	for serviceName, envVar := range serviceBaseURLEnvs {
+		if baseURL := os.Getenv(envVar); baseURL != "" {
+			services[serviceName].SetBase(baseURL)
+		}
+	}
+
+	var errs []error
+	for _, serviceName := range []string{petsServiceName, treatmentsServiceName, usersServiceName, notificationsServiceName} {
+		err = services[serviceName].Validate(serviceName, requiredEndpoints[serviceName])
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return Requester{}, fmt.Errorf("%w %s:\n%w", errInvalidConfig, path, errors.Join(errs...))
+	}
+
+	return requester, nil
+}
@@ -0,0 +1,76 @@
+package requester
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testConfigFilePath = "internal/config/config.json"
+
+func setConfigEnvs(t *testing.T, configPath string, environment string) {
+	t.Setenv(configPathEnv, configPath)
+	t.Setenv(environmentEnv, environment)
+	for _, envVar := range serviceBaseURLEnvs {
+		t.Setenv(envVar, "")
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	t.Run("Environment file overrides base URLs", func(t *testing.T) {
+		setConfigEnvs(t, testConfigFilePath, "local")
+
+		requester, err := loadConfig()
+		require.NoError(t, err)
+		assert.Equal(t, "http://localhost:8090/pets", requester.PetsService.Base)
+		assert.Equal(t, "http://localhost:8090/treatments", requester.TreatmentsService.Base)
+
+		endpoint, err := requester.PetsService.GetEndpoint(getPetByID)
+		require.NoError(t, err)
+		assert.Equal(t, "http://localhost:8090/pets/pet/{petID}", endpoint.GetURL())
+	})
+
+	t.Run("Env vars override base URLs", func(t *testing.T) {
+		setConfigEnvs(t, testConfigFilePath, "local")
+		t.Setenv("USERS_SERVICE_URL", "https://users.staging.lnt.digital")
+
+		requester, err := loadConfig()
+		require.NoError(t, err)
+		assert.Equal(t, "https://users.staging.lnt.digital", requester.UsersService.Base)
+		assert.Equal(t, "http://localhost:8090/pets", requester.PetsService.Base)
+
+		endpoint, err := requester.UsersService.GetEndpoint(getUser)
+		require.NoError(t, err)
+		assert.Equal(t, "https://users.staging.lnt.digital/telegram_id/{telegramID}", endpoint.GetURL())
+	})
+
+	t.Run("Config file does not exist", func(t *testing.T) {
+		setConfigEnvs(t, filepath.Join(t.TempDir(), "config.json"), "")
+
+		_, err := loadConfig()
+		assert.Error(t, err)
+	})
+
+	t.Run("All the problems are reported at once", func(t *testing.T) {
+		configPath := filepath.Join(t.TempDir(), "config.json")
+		err := os.WriteFile(configPath, []byte(`{
+			"pets_service": {"base": "https://api.lnt.digital/pets", "endpoints": {}},
+			"treatments_service": {"base": "", "endpoints": {}},
+			"users_service": {"base": "https://api.lnt.digital/users", "endpoints": {
+				"get_user": {"path": "users", "method": "GET"}
+			}},
+			"notifications_service": {"base": "https://api.lnt.digital/notifications", "endpoints": {}}
+		}`), 0o600)
+		require.NoError(t, err)
+		setConfigEnvs(t, configPath, "")
+
+		_, err = loadConfig()
+		assert.ErrorIs(t, err, errInvalidConfig)
+		assert.ErrorContains(t, err, "pets_service: get_pets")
+		assert.ErrorContains(t, err, "treatments_service")
+		assert.ErrorContains(t, err, `users_service: get_user: path "users" must start with /`)
+		assert.ErrorContains(t, err, "notifications_service: delete_notification")
+	})
+}
@@ -0,0 +1,114 @@
+package requester
+
+import (
+	"context"
+	"errors"
+	"github.com/fsnotify/fsnotify"
+	"github.com/sirupsen/logrus"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"telegram-bot/internal/requester/internal/config"
+	"time"
+)
+
+// reloadDebounce time to wait after a change in the config files before reloading, editors usually write
+// a file in several steps
+const reloadDebounce = 250 * time.Millisecond
+
+var errReloadNotSupported = errors.New("error requester config cannot be reloaded")
+
+// Reload loads the config again and, if it is valid, swaps it atomically: the requests that are being performed
+// finish with the old config and the new ones use the new config. If the new config is invalid, the current one
+// is kept. The circuit breakers and the response cache start from scratch with the new config
+func (r *Requester) Reload() error {
+	if r.latest == nil {
+		return errReloadNotSupported
+	}
+
+	requester, err := newRequesterFromConfig(r.clientHTTP)
+	if err != nil {
+		return err
+	}
+
+	r.latest.Store(requester)
+	return nil
+}
+
+// WatchConfig reloads the config whenever one of its files changes or the process receives SIGHUP.
+// It blocks until the context is done
+func (r *Requester) WatchConfig(ctx context.Context) error {
+	if r.latest == nil {
+		return errReloadNotSupported
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = watcher.Close()
+	}()
+
+	// The directories are watched instead of the files, so files replaced by a rename are still watched
+	configFiles := make(map[string]bool)
+	for _, path := range configFilePaths() {
+		configFiles[filepath.Clean(path)] = true
+		err = watcher.Add(filepath.Dir(path))
+		if err != nil {
+			return err
+		}
+	}
+
+	hangUp := make(chan os.Signal, 1)
+	signal.Notify(hangUp, syscall.SIGHUP)
+	defer signal.Stop(hangUp)
+
+	var debounce <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-hangUp:
+			logrus.Info("SIGHUP received, reloading requester config")
+			r.reloadAndLog()
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if configFiles[filepath.Clean(event.Name)] && !event.Has(fsnotify.Chmod) {
+				debounce = time.After(reloadDebounce)
+			}
+		case <-debounce:
+			debounce = nil
+			logrus.Info("requester config changed, reloading it")
+			r.reloadAndLog()
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			logrus.Errorf("error watching requester config: %v", err)
+		}
+	}
+}
+
+func (r *Requester) reloadAndLog() {
+	err := r.Reload()
+	if err != nil {
+		logrus.Errorf("keeping current requester config, error reloading it: %v", err)
+		return
+	}
+
+	logrus.Info("requester config reloaded correctly")
+}
+
+// configFilePaths returns the paths of the files the config is loaded from
+func configFilePaths() []string {
+	paths := []string{configFilePath()}
+	if environment := os.Getenv(environmentEnv); environment != "" {
+		paths = append(paths, config.EnvironmentFilePath(configFilePath(), environment))
+	}
+
+	return paths
+}
@@ -0,0 +1,95 @@
+package requester
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// copyTestConfig copies the config file to a temporal directory, so it can be modified by the test
+func copyTestConfig(t *testing.T) string {
+	rawConfig, err := os.ReadFile(testConfigFilePath)
+	require.NoError(t, err)
+
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	writeTestConfig(t, configPath, string(rawConfig))
+
+	return configPath
+}
+
+func writeTestConfig(t *testing.T, configPath string, content string) {
+	err := os.WriteFile(configPath, []byte(content), 0o600)
+	require.NoError(t, err)
+}
+
+func replaceInTestConfig(t *testing.T, configPath string, old string, new string) {
+	rawConfig, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+	writeTestConfig(t, configPath, strings.ReplaceAll(string(rawConfig), old, new))
+}
+
+func TestRequesterReload(t *testing.T) {
+	t.Run("Valid config is swapped", func(t *testing.T) {
+		configPath := copyTestConfig(t)
+		setConfigEnvs(t, configPath, "")
+
+		requester, err := NewRequester(&http.Client{})
+		require.NoError(t, err)
+
+		replaceInTestConfig(t, configPath, "https://api.lnt.digital/treatments", "https://treatments.lnt.digital")
+		require.NoError(t, requester.Reload())
+
+		endpoint, err := requester.snapshot().getEndpoint(treatmentsServiceName, getTreatment)
+		require.NoError(t, err)
+		assert.Equal(t, "https://treatments.lnt.digital/treatment/specific/{treatmentID}", endpoint.GetURL())
+	})
+
+	t.Run("Invalid config is discarded", func(t *testing.T) {
+		configPath := copyTestConfig(t)
+		setConfigEnvs(t, configPath, "")
+
+		requester, err := NewRequester(&http.Client{})
+		require.NoError(t, err)
+
+		replaceInTestConfig(t, configPath, `"https://api.lnt.digital/treatments"`, `""`)
+		assert.ErrorIs(t, requester.Reload(), errInvalidConfig)
+
+		endpoint, err := requester.snapshot().getEndpoint(treatmentsServiceName, getTreatment)
+		require.NoError(t, err)
+		assert.Equal(t, "https://api.lnt.digital/treatments/treatment/specific/{treatmentID}", endpoint.GetURL())
+	})
+
+	t.Run("Requester without config cannot be reloaded", func(t *testing.T) {
+		requester := Requester{}
+		assert.ErrorIs(t, requester.Reload(), errReloadNotSupported)
+	})
+}
+
+func TestRequesterWatchConfig(t *testing.T) {
+	configPath := copyTestConfig(t)
+	setConfigEnvs(t, configPath, "")
+
+	requester, err := NewRequester(&http.Client{})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	watchStopped := make(chan error)
+	go func() {
+		watchStopped <- requester.WatchConfig(ctx)
+	}()
+
+	// The file is written until the change is seen, as the watcher may not be ready on the first write
+	assert.Eventually(t, func() bool {
+		replaceInTestConfig(t, configPath, "https://api.lnt.digital/pets", "https://pets.lnt.digital")
+		return requester.snapshot().PetsService.Base == "https://pets.lnt.digital"
+	}, 5*time.Second, 2*reloadDebounce)
+
+	cancel()
+	assert.NoError(t, <-watchStopped)
+}
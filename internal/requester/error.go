@@ -67,9 +67,12 @@ var (
 	errUnmarshallingMultipleTreatments = errors.New("error unmarshalling multiple treatments")
 	errMarshallingPetRequest           = errors.New("error marshalling pet request")
 	errMarshallingNotificationRequest  = errors.New("error marshalling notification request")
+	errMarshallingCommentRequest       = errors.New("error marshalling comment request")
 	errCreatingRequest                 = errors.New("error creating request")
 	errNilResponse                     = errors.New("error nil response")
 	errUnmarshallingErrorResponse      = errors.New("error unmarshalling error response")
+	errServiceUnavailable              = errors.New("error service temporarily unavailable")
+	errServiceUnreachable              = errors.New("error service unreachable")
 )
 
 func ErrPolicyFunc[serviceErrorType serviceError](response *http.Response) error {
@@ -96,6 +99,7 @@ type RequestError interface {
 	IsNoContent() bool
 	IsBadRequest() bool
 	IsNotFound() bool
+	IsServiceUnavailable() bool
 
 	StatusCode() int
 }
@@ -138,6 +142,10 @@ func (re requestError) IsNotFound() bool {
 	return re.statusCode == http.StatusNotFound
 }
 
+func (re requestError) IsServiceUnavailable() bool {
+	return re.statusCode == http.StatusServiceUnavailable
+}
+
 func (re requestError) StatusCode() int {
 	return re.statusCode
 }
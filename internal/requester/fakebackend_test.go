@@ -0,0 +1,117 @@
+package requester
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"telegram-bot/internal/domain"
+	"telegram-bot/internal/fakebackend"
+	"testing"
+	"time"
+)
+
+const ownerTelegramID = "911"
+
+// newFakeBackendRequester returns a requester that performs its requests against a fake backend seeded with
+// the data of the owner
+func newFakeBackendRequester(t *testing.T) (*Requester, *fakebackend.Store) {
+	store := fakebackend.NewStore()
+	store.Seed(ownerTelegramID)
+
+	server := fakebackend.NewServer(store)
+	t.Cleanup(server.Close)
+
+	setConfigEnvs(t, testConfigFilePath, "")
+	for envVar, baseURL := range server.ServiceURLs() {
+		t.Setenv(envVar, baseURL)
+	}
+
+	requester, err := NewRequester(&http.Client{Timeout: 5 * time.Second})
+	require.NoError(t, err)
+
+	return requester, store
+}
+
+func TestRequesterAgainstFakeBackend(t *testing.T) {
+	ctx := context.Background()
+	requester, store := newFakeBackendRequester(t)
+
+	t.Run("Users", func(t *testing.T) {
+		_, err := requester.GetUserData(ctx, 420)
+		var requestErr RequestError
+		require.ErrorAs(t, err, &requestErr)
+		assert.True(t, requestErr.IsNotFound())
+
+		user, err := requester.GetUserData(ctx, 911)
+		require.NoError(t, err)
+		assert.Equal(t, "user-911", user.UserID)
+	})
+
+	t.Run("Pets", func(t *testing.T) {
+		pets, err := requester.GetPetsByOwnerID(ctx, 911)
+		require.NoError(t, err)
+		require.Len(t, pets, 2)
+
+		err = requester.RegisterPet(ctx, domain.PetRequest{
+			Name:      "Chimuelo",
+			Type:      "dragon",
+			BirthDate: "2020-01-02",
+			OwnerID:   ownerTelegramID,
+		})
+		require.NoError(t, err)
+
+		// register_pet invalidates the cached pets of the owner
+		pets, err = requester.GetPetsByOwnerID(ctx, 911)
+		require.NoError(t, err)
+		require.Len(t, pets, 3)
+
+		pet, err := requester.GetPetData(ctx, pets[2].ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Chimuelo", pet.Name)
+	})
+
+	t.Run("Treatments", func(t *testing.T) {
+		pets := store.GetPetsByOwner(ownerTelegramID)
+		treatments, err := requester.GetTreatmentsByPetID(ctx, pets[0].ID)
+		require.NoError(t, err)
+		require.Len(t, treatments, 2)
+
+		err = requester.AddTreatmentComment(ctx, treatments[0].ID, domain.CommentRequest{
+			Information: "Much better today",
+			Owner:       "Lionel",
+		})
+		require.NoError(t, err)
+
+		treatment, err := requester.GetTreatment(ctx, treatments[0].ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Much better today", treatment.Comments[0].Information)
+
+		vaccines, err := requester.GetVaccines(ctx, pets[0].ID)
+		require.NoError(t, err)
+		require.Len(t, vaccines, 2)
+		assert.Equal(t, "Rabies", vaccines[0].Name)
+		assert.Equal(t, 2, vaccines[0].AmountOfDoses)
+	})
+
+	t.Run("Notifications", func(t *testing.T) {
+		notifications, err := requester.RegisterNotifications(ctx, domain.NewNotificationRequest(
+			ownerTelegramID,
+			"Give the pill to Bachicha",
+			time.Now(),
+			nil,
+			[]string{"09:00", "21:00"},
+		))
+		require.NoError(t, err)
+		require.Len(t, notifications, 2)
+
+		err = requester.DeleteNotification(ctx, notifications[0].ID)
+		require.NoError(t, err)
+		assert.Len(t, store.GetNotifications(ownerTelegramID), 1)
+
+		err = requester.DeleteNotification(ctx, notifications[0].ID)
+		var requestErr RequestError
+		require.ErrorAs(t, err, &requestErr)
+		assert.True(t, requestErr.IsNotFound())
+	})
+}
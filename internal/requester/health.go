@@ -0,0 +1,49 @@
+package requester
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+)
+
+// ServiceNames returns the names of the configured services, sorted
+func (r *Requester) ServiceNames() []string {
+	services := r.snapshot().services()
+	names := make([]string, 0, len(services))
+	for serviceName := range services {
+		names = append(names, serviceName)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// PingService returns an error if the service cannot be reached: the request to its base URL fails or the
+// response is a 5xx. Other responses, even a 404, mean that the service is reachable. The circuit breaker of the
+// service is not used, so it can be checked while the circuit is open
+func (r *Requester) PingService(ctx context.Context, serviceName string) error {
+	r = r.snapshot()
+	service, found := r.services()[serviceName]
+	if !found {
+		return fmt.Errorf("unknown service %s", serviceName)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, service.Base, nil)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errCreatingRequest, err)
+	}
+	setTelegramHeader(request)
+
+	response, err := r.clientHTTP.Do(request)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errServiceUnreachable, err)
+	}
+	defer closeResponseBody(response)
+
+	if response.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("%w: status code %d", errServiceUnreachable, response.StatusCode)
+	}
+
+	return nil
+}
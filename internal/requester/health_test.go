@@ -0,0 +1,69 @@
+package requester
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"telegram-bot/internal/requester/internal/config"
+	"testing"
+	"time"
+)
+
+func TestServiceNames(t *testing.T) {
+	requester := Requester{}
+	assert.Equal(
+		t,
+		[]string{notificationsServiceName, petsServiceName, treatmentsServiceName, usersServiceName},
+		requester.ServiceNames(),
+	)
+}
+
+func TestPingService(t *testing.T) {
+	closedServer := httptest.NewServer(http.NotFoundHandler())
+	closedServer.Close()
+
+	testCases := []struct {
+		Name         string
+		StatusCode   int
+		Base         string
+		ExpectsError bool
+	}{
+		{Name: "Service responds OK", StatusCode: http.StatusOK},
+		{Name: "Service responds not found", StatusCode: http.StatusNotFound},
+		{Name: "Service responds unavailable", StatusCode: http.StatusServiceUnavailable, ExpectsError: true},
+		{Name: "Service cannot be reached", Base: closedServer.URL, ExpectsError: true},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "true", r.Header.Get(telegramHeader))
+				w.WriteHeader(testCase.StatusCode)
+			}))
+			defer server.Close()
+
+			base := server.URL
+			if testCase.Base != "" {
+				base = testCase.Base
+			}
+
+			requester := Requester{
+				PetsService: config.ServiceEndpoints{Base: base},
+				clientHTTP:  &http.Client{Timeout: time.Second},
+			}
+			err := requester.PingService(context.Background(), petsServiceName)
+			if testCase.ExpectsError {
+				assert.ErrorIs(t, err, errServiceUnreachable)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+
+	t.Run("Unknown service", func(t *testing.T) {
+		requester := Requester{}
+		assert.Error(t, requester.PingService(context.Background(), "salchis_service"))
+	})
+}
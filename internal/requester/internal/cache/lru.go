@@ -0,0 +1,102 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Entry value stored in the cache and the moment it was stored
+type Entry struct {
+	Value    []byte
+	StoredAt time.Time
+}
+
+// Age returns how long ago the entry was stored
+func (e Entry) Age(now time.Time) time.Duration {
+	return now.Sub(e.StoredAt)
+}
+
+type item struct {
+	key   string
+	entry Entry
+}
+
+// LRU is a cache safe for concurrent use that holds up to maxEntries, evicting the least recently used
+// entry when it is full. Expiration is up to the caller, who decides with the age of the entry
+type LRU struct {
+	mu         sync.Mutex
+	maxEntries int
+	items      map[string]*list.Element
+	order      *list.List
+}
+
+func NewLRU(maxEntries int) *LRU {
+	return &LRU{
+		maxEntries: maxEntries,
+		items:      make(map[string]*list.Element),
+		order:      list.New(),
+	}
+}
+
+// Get returns the entry of the key and marks it as the most recently used
+func (c *LRU) Get(key string) (Entry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, found := c.items[key]
+	if !found {
+		return Entry{}, false
+	}
+
+	c.order.MoveToFront(element)
+	return element.Value.(*item).entry, true
+}
+
+// Set stores the value, replacing the previous one of the key if it exists
+func (c *LRU) Set(key string, value []byte, storedAt time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := Entry{Value: value, StoredAt: storedAt}
+	if element, found := c.items[key]; found {
+		element.Value.(*item).entry = entry
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&item{key: key, entry: entry})
+	for c.maxEntries > 0 && c.order.Len() > c.maxEntries {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*item).key)
+	}
+}
+
+// Delete removes the entry of the key
+func (c *LRU) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, found := c.items[key]; found {
+		c.order.Remove(element)
+		delete(c.items, key)
+	}
+}
+
+// Purge removes all the entries
+func (c *LRU) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.items = make(map[string]*list.Element)
+	c.order.Init()
+}
+
+// Len returns the amount of entries in the cache
+func (c *LRU) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.order.Len()
+}
@@ -0,0 +1,67 @@
+package cache
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestLRU(t *testing.T) {
+	now := time.Now()
+
+	t.Run("Get stored entry", func(t *testing.T) {
+		lru := NewLRU(2)
+		lru.Set("pet-69", []byte("salchicha"), now)
+
+		entry, found := lru.Get("pet-69")
+		require.True(t, found)
+		assert.Equal(t, []byte("salchicha"), entry.Value)
+		assert.Equal(t, time.Minute, entry.Age(now.Add(time.Minute)))
+
+		_, found = lru.Get("pet-420")
+		assert.False(t, found)
+	})
+
+	t.Run("Least recently used entry is evicted", func(t *testing.T) {
+		lru := NewLRU(2)
+		lru.Set("pet-1", []byte("1"), now)
+		lru.Set("pet-2", []byte("2"), now)
+		_, _ = lru.Get("pet-1")
+		lru.Set("pet-3", []byte("3"), now)
+
+		assert.Equal(t, 2, lru.Len())
+		_, found := lru.Get("pet-2")
+		assert.False(t, found)
+		_, found = lru.Get("pet-1")
+		assert.True(t, found)
+		_, found = lru.Get("pet-3")
+		assert.True(t, found)
+	})
+
+	t.Run("Set replaces existing entry", func(t *testing.T) {
+		lru := NewLRU(2)
+		lru.Set("pet-1", []byte("old"), now)
+		lru.Set("pet-1", []byte("new"), now.Add(time.Second))
+
+		entry, found := lru.Get("pet-1")
+		require.True(t, found)
+		assert.Equal(t, []byte("new"), entry.Value)
+		assert.Equal(t, 1, lru.Len())
+	})
+
+	t.Run("Delete and purge", func(t *testing.T) {
+		lru := NewLRU(3)
+		lru.Set("pet-1", []byte("1"), now)
+		lru.Set("pet-2", []byte("2"), now)
+		lru.Set("pet-3", []byte("3"), now)
+
+		lru.Delete("pet-1")
+		_, found := lru.Get("pet-1")
+		assert.False(t, found)
+		assert.Equal(t, 2, lru.Len())
+
+		lru.Purge()
+		assert.Equal(t, 0, lru.Len())
+	})
+}
@@ -4,13 +4,31 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/sirupsen/logrus"
+	"net/http"
+	"telegram-bot/internal/utils"
+	"time"
 )
 
-const defaultLimit = 10
+const (
+	defaultLimit          = 10
+	defaultMaxAttempts    = 3
+	defaultInitialBackoff = 100 * time.Millisecond
+	defaultMaxBackoff     = 2 * time.Second
+
+	defaultFailureThreshold = 5
+	defaultOpenTimeout      = 30 * time.Second
+
+	defaultCacheTTL        = time.Minute
+	defaultCacheMaxEntries = 1000
+)
+
+var defaultRetryStatusCodes = []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout}
 
 type ServiceEndpoints struct {
 	Base      string              `json:"base"`
 	Endpoints map[string]Endpoint `json:"endpoints"`
+	// CircuitBreaker if it is nil, calls to the service are always performed
+	CircuitBreaker *CircuitBreaker `json:"circuit_breaker"`
 }
 
 func (se *ServiceEndpoints) UnmarshalJSON(rawServiceData []byte) error {
@@ -20,8 +38,9 @@ func (se *ServiceEndpoints) UnmarshalJSON(rawServiceData []byte) error {
 	}
 
 	var serviceEndpoints struct {
-		Base      string              `json:"base"`
-		Endpoints map[string]Endpoint `json:"endpoints"`
+		Base           string              `json:"base"`
+		Endpoints      map[string]Endpoint `json:"endpoints"`
+		CircuitBreaker *CircuitBreaker     `json:"circuit_breaker"`
 	}
 
 	err := json.Unmarshal(rawServiceData, &serviceEndpoints)
@@ -32,6 +51,7 @@ func (se *ServiceEndpoints) UnmarshalJSON(rawServiceData []byte) error {
 
 	se.Base = serviceEndpoints.Base
 	se.Endpoints = serviceEndpoints.Endpoints
+	se.CircuitBreaker = serviceEndpoints.CircuitBreaker
 
 	for key, endpointData := range se.Endpoints {
 		endpointData.SetBaseURL(se.Base)
@@ -55,6 +75,16 @@ type Endpoint struct {
 	Path        string       `json:"path"`
 	Method      string       `json:"method"`
 	QueryParams *QueryParams `json:"query_params"`
+	// Timeout deadline for each call to the endpoint, including retries. If it is zero, only the timeout
+	// of the HTTP client applies
+	Timeout Duration `json:"timeout"`
+	// Retry if it is nil, failed calls are not retried
+	Retry *RetryPolicy `json:"retry"`
+	// Cache if it is nil, the responses of the endpoint are not cached. Only applies to GET endpoints
+	Cache *CachePolicy `json:"cache"`
+	// Invalidates aliases of the endpoints of the same service whose cached responses are discarded after
+	// a successful call to this endpoint
+	Invalidates []string `json:"invalidates"`
 	baseURL     string
 }
 
@@ -103,3 +133,150 @@ func (qp *QueryParams) ToMap() map[string]string {
 
 	return paramsMap
 }
+
+// RetryPolicy defines how failed calls to an endpoint are retried. Calls are retried when the request cannot
+// be performed (eg: connection reset) or when the status code of the response is one of StatusCodes
+type RetryPolicy struct {
+	// MaxAttempts amount of attempts including the first one
+	MaxAttempts    int      `json:"max_attempts"`
+	InitialBackoff Duration `json:"initial_backoff"`
+	MaxBackoff     Duration `json:"max_backoff"`
+	StatusCodes    []int    `json:"status_codes"`
+}
+
+func (rp *RetryPolicy) UnmarshalJSON(rawData []byte) error {
+	var retryPolicy struct {
+		MaxAttempts    int      `json:"max_attempts"`
+		InitialBackoff Duration `json:"initial_backoff"`
+		MaxBackoff     Duration `json:"max_backoff"`
+		StatusCodes    []int    `json:"status_codes"`
+	}
+	err := json.Unmarshal(rawData, &retryPolicy)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errUnmarshallingRetryPolicy, err)
+	}
+
+	rp.MaxAttempts = retryPolicy.MaxAttempts
+	if rp.MaxAttempts == 0 {
+		rp.MaxAttempts = defaultMaxAttempts
+	}
+
+	rp.InitialBackoff = retryPolicy.InitialBackoff
+	if rp.InitialBackoff.Duration == 0 {
+		rp.InitialBackoff.Duration = defaultInitialBackoff
+	}
+
+	rp.MaxBackoff = retryPolicy.MaxBackoff
+	if rp.MaxBackoff.Duration == 0 {
+		rp.MaxBackoff.Duration = defaultMaxBackoff
+	}
+
+	rp.StatusCodes = retryPolicy.StatusCodes
+	if rp.StatusCodes == nil {
+		rp.StatusCodes = defaultRetryStatusCodes
+	}
+
+	return nil
+}
+
+// ShouldRetryStatus returns true if a response with the given status code must be retried
+func (rp *RetryPolicy) ShouldRetryStatus(statusCode int) bool {
+	return utils.Contains(rp.StatusCodes, statusCode)
+}
+
+// CircuitBreaker defines when the calls to a service stop being performed. After FailureThreshold consecutive
+// failures the circuit opens and calls fail fast during OpenTimeout, then a single call is allowed to probe
+// if the service recovered
+type CircuitBreaker struct {
+	FailureThreshold int      `json:"failure_threshold"`
+	OpenTimeout      Duration `json:"open_timeout"`
+}
+
+func (cb *CircuitBreaker) UnmarshalJSON(rawData []byte) error {
+	var circuitBreaker struct {
+		FailureThreshold *int      `json:"failure_threshold"`
+		OpenTimeout      *Duration `json:"open_timeout"`
+	}
+	err := json.Unmarshal(rawData, &circuitBreaker)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errUnmarshallingCircuitBreaker, err)
+	}
+
+	cb.FailureThreshold = defaultFailureThreshold
+	if circuitBreaker.FailureThreshold != nil {
+		if *circuitBreaker.FailureThreshold <= 0 {
+			return fmt.Errorf("%w: failure_threshold must be positive", errInvalidCircuitBreaker)
+		}
+		cb.FailureThreshold = *circuitBreaker.FailureThreshold
+	}
+
+	cb.OpenTimeout.Duration = defaultOpenTimeout
+	if circuitBreaker.OpenTimeout != nil {
+		if circuitBreaker.OpenTimeout.Duration <= 0 {
+			return fmt.Errorf("%w: open_timeout must be positive", errInvalidCircuitBreaker)
+		}
+		cb.OpenTimeout = *circuitBreaker.OpenTimeout
+	}
+
+	return nil
+}
+
+// CachePolicy defines how long the responses of an endpoint are cached. A response is served from the cache
+// during TTL, after that it becomes stale and is only served if the service fails, until StaleTTL elapses.
+// MaxEntries bounds the amount of responses cached, the least recently used ones are evicted first
+type CachePolicy struct {
+	TTL        Duration `json:"ttl"`
+	StaleTTL   Duration `json:"stale_ttl"`
+	MaxEntries int      `json:"max_entries"`
+}
+
+func (cp *CachePolicy) UnmarshalJSON(rawData []byte) error {
+	var cachePolicy struct {
+		TTL        Duration `json:"ttl"`
+		StaleTTL   Duration `json:"stale_ttl"`
+		MaxEntries int      `json:"max_entries"`
+	}
+	err := json.Unmarshal(rawData, &cachePolicy)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errUnmarshallingCachePolicy, err)
+	}
+
+	cp.TTL = cachePolicy.TTL
+	if cp.TTL.Duration == 0 {
+		cp.TTL.Duration = defaultCacheTTL
+	}
+
+	cp.StaleTTL = cachePolicy.StaleTTL
+
+	cp.MaxEntries = cachePolicy.MaxEntries
+	if cp.MaxEntries == 0 {
+		cp.MaxEntries = defaultCacheMaxEntries
+	}
+
+	return nil
+}
+
+// Duration wraps time.Duration so it can be defined in the config file as a string, eg: "3s", "500ms"
+type Duration struct {
+	time.Duration
+}
+
+func (d *Duration) UnmarshalJSON(rawData []byte) error {
+	var rawDuration string
+	err := json.Unmarshal(rawData, &rawDuration)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errUnmarshallingDuration, err)
+	}
+
+	duration, err := time.ParseDuration(rawDuration)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errUnmarshallingDuration, err)
+	}
+
+	d.Duration = duration
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
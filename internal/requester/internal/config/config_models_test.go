@@ -6,6 +6,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"testing"
+	"time"
 )
 
 func TestServiceEndpoints_GetEndpoint(t *testing.T) {
@@ -180,3 +181,197 @@ func TestQueryParams_ToMap(t *testing.T) {
 	assert.Equal(t, queryParamsMap["limit"], fmt.Sprint(defaultLimit))
 	assert.Equal(t, queryParamsMap["offset"], "5")
 }
+
+func TestDuration_UnmarshalJSON(t *testing.T) {
+	testCases := []struct {
+		Name             string
+		RawData          []byte
+		ExpectsError     bool
+		ExpectedDuration time.Duration
+	}{
+		{
+			Name:         "Error duration is not a string",
+			RawData:      []byte(`3`),
+			ExpectsError: true,
+		},
+		{
+			Name:         "Error invalid duration format",
+			RawData:      []byte(`"tres segundos"`),
+			ExpectsError: true,
+		},
+		{
+			Name:             "Duration unmarshalled correctly",
+			RawData:          []byte(`"1500ms"`),
+			ExpectsError:     false,
+			ExpectedDuration: 1500 * time.Millisecond,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			duration := Duration{}
+			err := duration.UnmarshalJSON(testCase.RawData)
+			if testCase.ExpectsError {
+				assert.ErrorIs(t, err, errUnmarshallingDuration)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.ExpectedDuration, duration.Duration)
+		})
+	}
+}
+
+func TestRetryPolicy_UnmarshalJSON(t *testing.T) {
+	testCases := []struct {
+		Name                string
+		RawData             []byte
+		ExpectsError        bool
+		ExpectedRetryPolicy RetryPolicy
+	}{
+		{
+			Name:         "Error unmarshalling retry policy",
+			RawData:      []byte(`{"max_attempts": "muchos"}`),
+			ExpectsError: true,
+		},
+		{
+			Name:         "Default values are set for missing fields",
+			RawData:      []byte(`{}`),
+			ExpectsError: false,
+			ExpectedRetryPolicy: RetryPolicy{
+				MaxAttempts:    defaultMaxAttempts,
+				InitialBackoff: Duration{Duration: defaultInitialBackoff},
+				MaxBackoff:     Duration{Duration: defaultMaxBackoff},
+				StatusCodes:    defaultRetryStatusCodes,
+			},
+		},
+		{
+			Name:         "Retry policy unmarshalled correctly",
+			RawData:      []byte(`{"max_attempts": 5, "initial_backoff": "50ms", "max_backoff": "1s", "status_codes": [503]}`),
+			ExpectsError: false,
+			ExpectedRetryPolicy: RetryPolicy{
+				MaxAttempts:    5,
+				InitialBackoff: Duration{Duration: 50 * time.Millisecond},
+				MaxBackoff:     Duration{Duration: time.Second},
+				StatusCodes:    []int{503},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			retryPolicy := RetryPolicy{}
+			err := retryPolicy.UnmarshalJSON(testCase.RawData)
+			if testCase.ExpectsError {
+				assert.ErrorIs(t, err, errUnmarshallingRetryPolicy)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.ExpectedRetryPolicy, retryPolicy)
+		})
+	}
+}
+
+func TestCircuitBreaker_UnmarshalJSON(t *testing.T) {
+	testCases := []struct {
+		Name                   string
+		RawData                []byte
+		ExpectedError          error
+		ExpectedCircuitBreaker CircuitBreaker
+	}{
+		{
+			Name:          "Error unmarshalling circuit breaker",
+			RawData:       []byte(`{"open_timeout": 30}`),
+			ExpectedError: errUnmarshallingCircuitBreaker,
+		},
+		{
+			Name:          "Failure threshold is not positive",
+			RawData:       []byte(`{"failure_threshold": 0}`),
+			ExpectedError: errInvalidCircuitBreaker,
+		},
+		{
+			Name:          "Open timeout is not positive",
+			RawData:       []byte(`{"open_timeout": "-1s"}`),
+			ExpectedError: errInvalidCircuitBreaker,
+		},
+		{
+			Name:    "Default values are set for missing fields",
+			RawData: []byte(`{}`),
+			ExpectedCircuitBreaker: CircuitBreaker{
+				FailureThreshold: defaultFailureThreshold,
+				OpenTimeout:      Duration{Duration: defaultOpenTimeout},
+			},
+		},
+		{
+			Name:    "Circuit breaker unmarshalled correctly",
+			RawData: []byte(`{"failure_threshold": 3, "open_timeout": "10s"}`),
+			ExpectedCircuitBreaker: CircuitBreaker{
+				FailureThreshold: 3,
+				OpenTimeout:      Duration{Duration: 10 * time.Second},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			circuitBreaker := CircuitBreaker{}
+			err := circuitBreaker.UnmarshalJSON(testCase.RawData)
+			if testCase.ExpectedError != nil {
+				assert.ErrorIs(t, err, testCase.ExpectedError)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.ExpectedCircuitBreaker, circuitBreaker)
+		})
+	}
+}
+
+func TestCachePolicy_UnmarshalJSON(t *testing.T) {
+	testCases := []struct {
+		Name                string
+		RawData             []byte
+		ExpectsError        bool
+		ExpectedCachePolicy CachePolicy
+	}{
+		{
+			Name:         "Error unmarshalling cache policy",
+			RawData:      []byte(`{"max_entries": "many"}`),
+			ExpectsError: true,
+		},
+		{
+			Name:         "Default values are set for missing fields",
+			RawData:      []byte(`{}`),
+			ExpectsError: false,
+			ExpectedCachePolicy: CachePolicy{
+				TTL:        Duration{Duration: defaultCacheTTL},
+				MaxEntries: defaultCacheMaxEntries,
+			},
+		},
+		{
+			Name:         "Cache policy unmarshalled correctly",
+			RawData:      []byte(`{"ttl": "5m", "stale_ttl": "1h", "max_entries": 50}`),
+			ExpectsError: false,
+			ExpectedCachePolicy: CachePolicy{
+				TTL:        Duration{Duration: 5 * time.Minute},
+				StaleTTL:   Duration{Duration: time.Hour},
+				MaxEntries: 50,
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			cachePolicy := CachePolicy{}
+			err := cachePolicy.UnmarshalJSON(testCase.RawData)
+			if testCase.ExpectsError {
+				assert.ErrorIs(t, err, errUnmarshallingCachePolicy)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.ExpectedCachePolicy, cachePolicy)
+		})
+	}
+}
@@ -3,8 +3,19 @@ package config
 import "errors"
 
 var (
-	errEndpointDoesNotExist       = errors.New("error endpoint does not exist")
-	errUnmarshallingQueryParams   = errors.New("error unmarshalling query params")
-	errServiceEndpointDataMissing = errors.New("error service endpoints data is missing")
-	errUnmarshallingServiceData   = errors.New("error unmarshalling service data")
+	errEndpointDoesNotExist        = errors.New("error endpoint does not exist")
+	errUnmarshallingQueryParams    = errors.New("error unmarshalling query params")
+	errServiceEndpointDataMissing  = errors.New("error service endpoints data is missing")
+	errUnmarshallingServiceData    = errors.New("error unmarshalling service data")
+	errUnmarshallingDuration       = errors.New("error unmarshalling duration")
+	errUnmarshallingRetryPolicy    = errors.New("error unmarshalling retry policy")
+	errUnmarshallingCircuitBreaker = errors.New("error unmarshalling circuit breaker")
+	errInvalidCircuitBreaker       = errors.New("error invalid circuit breaker")
+	errUnmarshallingCachePolicy    = errors.New("error unmarshalling cache policy")
+	errReadingConfigFile           = errors.New("error reading config file")
+	errMalformedConfigFile         = errors.New("error malformed config file")
+	errMissingBaseURL              = errors.New("error missing base URL")
+	errInvalidBaseURL              = errors.New("error invalid base URL")
+	errMissingEndpoint             = errors.New("error missing endpoint")
+	errInvalidEndpoint             = errors.New("error invalid endpoint")
 )
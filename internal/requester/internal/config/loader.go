@@ -0,0 +1,73 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LoadLayers reads the base config file and, if environment is not empty, the file of that environment placed
+// next to it, eg: config.json and staging results in config.staging.json. The values of the environment file
+// override the base ones, objects are merged key by key so the environment file only needs the values that change
+func LoadLayers(basePath string, environment string) ([]byte, error) {
+	baseConfig, err := readConfigFile(basePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if environment == "" {
+		return json.Marshal(baseConfig)
+	}
+
+	environmentConfig, err := readConfigFile(EnvironmentFilePath(basePath, environment))
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(mergeConfig(baseConfig, environmentConfig))
+}
+
+// EnvironmentFilePath returns the path of the config file of the environment, which is next to the base file
+func EnvironmentFilePath(basePath string, environment string) string {
+	extension := filepath.Ext(basePath)
+	return fmt.Sprintf("%s.%s%s", strings.TrimSuffix(basePath, extension), environment, extension)
+}
+
+func readConfigFile(path string) (map[string]any, error) {
+	rawData, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errReadingConfigFile, err)
+	}
+
+	var configData map[string]any
+	err = json.Unmarshal(rawData, &configData)
+	if err != nil {
+		return nil, fmt.Errorf("%w %s: %v", errMalformedConfigFile, path, err)
+	}
+
+	return configData, nil
+}
+
+// mergeConfig overrides the values of base with the ones of override. Nested objects are merged recursively,
+// any other value is replaced
+func mergeConfig(base map[string]any, override map[string]any) map[string]any {
+	merged := make(map[string]any, len(base))
+	for key, value := range base {
+		merged[key] = value
+	}
+
+	for key, overrideValue := range override {
+		baseObject, baseIsObject := merged[key].(map[string]any)
+		overrideObject, overrideIsObject := overrideValue.(map[string]any)
+		if baseIsObject && overrideIsObject {
+			merged[key] = mergeConfig(baseObject, overrideObject)
+			continue
+		}
+
+		merged[key] = overrideValue
+	}
+
+	return merged
+}
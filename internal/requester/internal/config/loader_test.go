@@ -0,0 +1,101 @@
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeConfigFile(t *testing.T, path string, content string) {
+	err := os.WriteFile(path, []byte(content), 0o600)
+	require.NoError(t, err)
+}
+
+func TestEnvironmentFilePath(t *testing.T) {
+	assert.Equal(t, "config/config.staging.json", EnvironmentFilePath("config/config.json", "staging"))
+	assert.Equal(t, "config/settings.local", EnvironmentFilePath("config/settings", "local"))
+}
+
+func TestLoadLayers(t *testing.T) {
+	directory := t.TempDir()
+	basePath := filepath.Join(directory, "config.json")
+	writeConfigFile(t, basePath, `{
+		"pets_service": {
+			"base": "https://api.lnt.digital/pets",
+			"endpoints": {
+				"get_pets": {"path": "/owner/{ownerID}", "method": "GET", "timeout": "3s"}
+			}
+		}
+	}`)
+	writeConfigFile(t, filepath.Join(directory, "config.local.json"), `{
+		"pets_service": {
+			"base": "http://localhost:8090/pets",
+			"endpoints": {
+				"get_pets": {"timeout": "10s"}
+			}
+		}
+	}`)
+	writeConfigFile(t, filepath.Join(directory, "config.broken.json"), `{"pets_service": `)
+
+	testCases := []struct {
+		Name          string
+		Environment   string
+		ExpectedError error
+		ExpectedJSON  string
+	}{
+		{
+			Name:        "Only base file",
+			Environment: "",
+			ExpectedJSON: `{
+				"pets_service": {
+					"base": "https://api.lnt.digital/pets",
+					"endpoints": {
+						"get_pets": {"path": "/owner/{ownerID}", "method": "GET", "timeout": "3s"}
+					}
+				}
+			}`,
+		},
+		{
+			Name:        "Environment file overrides base values",
+			Environment: "local",
+			ExpectedJSON: `{
+				"pets_service": {
+					"base": "http://localhost:8090/pets",
+					"endpoints": {
+						"get_pets": {"path": "/owner/{ownerID}", "method": "GET", "timeout": "10s"}
+					}
+				}
+			}`,
+		},
+		{
+			Name:          "Environment file does not exist",
+			Environment:   "production",
+			ExpectedError: errReadingConfigFile,
+		},
+		{
+			Name:          "Malformed environment file",
+			Environment:   "broken",
+			ExpectedError: errMalformedConfigFile,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			rawConfig, err := LoadLayers(basePath, testCase.Environment)
+			if testCase.ExpectedError != nil {
+				assert.ErrorIs(t, err, testCase.ExpectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.JSONEq(t, testCase.ExpectedJSON, string(rawConfig))
+		})
+	}
+
+	t.Run("Base file does not exist", func(t *testing.T) {
+		_, err := LoadLayers(filepath.Join(directory, "missing.json"), "")
+		assert.ErrorIs(t, err, errReadingConfigFile)
+	})
+}
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"telegram-bot/internal/utils"
+)
+
+var validMethods = []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+
+// SetBase changes the base URL of the service and of all its endpoints
+func (se *ServiceEndpoints) SetBase(base string) {
+	se.Base = base
+	for key, endpointData := range se.Endpoints {
+		endpointData.SetBaseURL(base)
+		se.Endpoints[key] = endpointData
+	}
+}
+
+// Validate checks that the service has a valid base URL, all the required endpoints and that every endpoint is
+// well-formed. All the problems found are returned at once
+func (se *ServiceEndpoints) Validate(serviceName string, requiredEndpoints []string) error {
+	var errs []error
+
+	baseURL, err := url.Parse(se.Base)
+	if se.Base == "" {
+		errs = append(errs, fmt.Errorf("%w: %s", errMissingBaseURL, serviceName))
+	} else if err != nil || (baseURL.Scheme != "http" && baseURL.Scheme != "https") || baseURL.Host == "" {
+		errs = append(errs, fmt.Errorf("%w: %s: %q", errInvalidBaseURL, serviceName, se.Base))
+	}
+
+	for _, alias := range requiredEndpoints {
+		if _, found := se.Endpoints[alias]; !found {
+			errs = append(errs, fmt.Errorf("%w: %s: %s", errMissingEndpoint, serviceName, alias))
+		}
+	}
+
+	aliases := make([]string, 0, len(se.Endpoints))
+	for alias := range se.Endpoints {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+
+	for _, alias := range aliases {
+		for _, problem := range se.endpointProblems(alias) {
+			errs = append(errs, fmt.Errorf("%w: %s: %s: %s", errInvalidEndpoint, serviceName, alias, problem))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// endpointProblems returns a description of each problem of the endpoint
+func (se *ServiceEndpoints) endpointProblems(alias string) []string {
+	endpoint := se.Endpoints[alias]
+
+	var problems []string
+	if !strings.HasPrefix(endpoint.Path, "/") {
+		problems = append(problems, fmt.Sprintf("path %q must start with /", endpoint.Path))
+	}
+
+	if strings.Count(endpoint.Path, "{") != strings.Count(endpoint.Path, "}") {
+		problems = append(problems, fmt.Sprintf("path %q has unbalanced placeholders", endpoint.Path))
+	}
+
+	if !utils.Contains(validMethods, endpoint.Method) {
+		problems = append(problems, fmt.Sprintf("invalid method %q", endpoint.Method))
+	}
+
+	if endpoint.Timeout.Duration < 0 {
+		problems = append(problems, "timeout cannot be negative")
+	}
+
+	if endpoint.Retry != nil && endpoint.Retry.MaxAttempts < 1 {
+		problems = append(problems, "retry max attempts must be at least 1")
+	}
+
+	if endpoint.Cache != nil && endpoint.Cache.MaxEntries < 0 {
+		problems = append(problems, "cache max entries cannot be negative")
+	}
+
+	for _, invalidatedAlias := range endpoint.Invalidates {
+		if _, found := se.Endpoints[invalidatedAlias]; !found {
+			problems = append(problems, fmt.Sprintf("invalidates unknown endpoint %q", invalidatedAlias))
+		}
+	}
+
+	return problems
+}
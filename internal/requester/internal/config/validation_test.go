@@ -0,0 +1,89 @@
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestServiceEndpoints_SetBase(t *testing.T) {
+	serviceEndpoints := ServiceEndpoints{
+		Base: "https://api.lnt.digital/pets",
+		Endpoints: map[string]Endpoint{
+			"get_pet": {Path: "/pet/{petID}", Method: http.MethodGet},
+		},
+	}
+
+	serviceEndpoints.SetBase("http://localhost:8090/pets")
+
+	endpoint, err := serviceEndpoints.GetEndpoint("get_pet")
+	assert.NoError(t, err)
+	assert.Equal(t, "http://localhost:8090/pets", serviceEndpoints.Base)
+	assert.Equal(t, "http://localhost:8090/pets/pet/{petID}", endpoint.GetURL())
+}
+
+func TestServiceEndpoints_Validate(t *testing.T) {
+	testCases := []struct {
+		Name             string
+		ServiceEndpoints ServiceEndpoints
+		ExpectedErrors   []error
+		ExpectedMessages []string
+	}{
+		{
+			Name: "Valid service",
+			ServiceEndpoints: ServiceEndpoints{
+				Base: "https://api.lnt.digital/pets",
+				Endpoints: map[string]Endpoint{
+					"get_pet":      {Path: "/pet/{petID}", Method: http.MethodGet, Timeout: Duration{Duration: time.Second}},
+					"register_pet": {Path: "/pet", Method: http.MethodPost, Invalidates: []string{"get_pet"}},
+				},
+			},
+		},
+		{
+			Name: "Missing base URL and endpoint",
+			ServiceEndpoints: ServiceEndpoints{
+				Endpoints: map[string]Endpoint{
+					"get_pet": {Path: "/pet/{petID}", Method: http.MethodGet},
+				},
+			},
+			ExpectedErrors:   []error{errMissingBaseURL, errMissingEndpoint},
+			ExpectedMessages: []string{"pets_service: register_pet"},
+		},
+		{
+			Name: "All the problems are reported",
+			ServiceEndpoints: ServiceEndpoints{
+				Base: "api.lnt.digital/pets",
+				Endpoints: map[string]Endpoint{
+					"get_pet":      {Path: "pet/{petID", Method: "FETCH"},
+					"register_pet": {Path: "/pet", Method: http.MethodPost, Invalidates: []string{"get_pets"}},
+				},
+			},
+			ExpectedErrors: []error{errInvalidBaseURL, errInvalidEndpoint},
+			ExpectedMessages: []string{
+				`get_pet: path "pet/{petID" must start with /`,
+				`get_pet: path "pet/{petID" has unbalanced placeholders`,
+				`get_pet: invalid method "FETCH"`,
+				`register_pet: invalidates unknown endpoint "get_pets"`,
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			err := testCase.ServiceEndpoints.Validate("pets_service", []string{"get_pet", "register_pet"})
+			if testCase.ExpectedErrors == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			for _, expectedError := range testCase.ExpectedErrors {
+				assert.ErrorIs(t, err, expectedError)
+			}
+
+			for _, expectedMessage := range testCase.ExpectedMessages {
+				assert.ErrorContains(t, err, expectedMessage)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package requester
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	metricsNamespace = "telegramer"
+	metricsSubsystem = "requester"
+
+	// statusTransportError status label of the calls that did not get a response
+	statusTransportError = "error"
+	// statusCircuitOpen status label of the calls that were not performed because the circuit breaker was open
+	statusCircuitOpen = "circuit_open"
+)
+
+var (
+	// requesterCalls calls to the services by service, endpoint alias and status code of the response
+	requesterCalls = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "calls_total",
+		Help:      "Calls to the services by service, endpoint and status code of the response.",
+	}, []string{"service", "endpoint", "status"})
+
+	// requesterCallDuration duration of the calls to the services, including the retries
+	requesterCallDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "call_duration_seconds",
+		Help:      "Duration of the calls to the services, including the retries.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"service", "endpoint"})
+
+	// requesterRetries amount of retries performed for each endpoint
+	requesterRetries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "retries_total",
+		Help:      "Retries performed by service and endpoint.",
+	}, []string{"service", "endpoint"})
+)
+
+// observeCall records a call to an endpoint that started at start and finished with the given response or error
+func observeCall(data requestData, start time.Time, response *http.Response, err error) {
+	status := statusTransportError
+	if err == nil && response != nil {
+		status = strconv.Itoa(response.StatusCode)
+	}
+
+	requesterCalls.WithLabelValues(data.serviceName, data.endpointAlias, status).Inc()
+	requesterCallDuration.WithLabelValues(data.serviceName, data.endpointAlias).Observe(time.Since(start).Seconds())
+}
@@ -0,0 +1,83 @@
+package requester
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"telegram-bot/internal/requester/internal/config"
+	"telegram-bot/internal/requester/internal/mock"
+	"testing"
+	"time"
+)
+
+func TestDoRequestRecordsMetrics(t *testing.T) {
+	endpoint := config.Endpoint{Path: "/song", Method: http.MethodGet}
+	endpoint.SetBaseURL(testBaseURL)
+
+	data := requestData{
+		operation:     "GetSong",
+		serviceName:   petsServiceName,
+		endpointAlias: "get_song_metrics",
+	}
+
+	testCases := []struct {
+		Name           string
+		Response       *http.Response
+		Err            error
+		Breaker        *circuitBreaker
+		ExpectedStatus string
+	}{
+		{
+			Name: "Status code of the response",
+			Response: &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewBufferString(`{}`)),
+			},
+			ExpectedStatus: "200",
+		},
+		{
+			Name:           "Transport error",
+			Err:            fmt.Errorf("connection refused"),
+			ExpectedStatus: statusTransportError,
+		},
+		{
+			Name: "Circuit breaker is open",
+			Breaker: &circuitBreaker{
+				failureThreshold: 1,
+				openTimeout:      time.Minute,
+				state:            circuitOpen,
+				openedAt:         time.Now(),
+				now:              time.Now,
+			},
+			ExpectedStatus: statusCircuitOpen,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			clientMock := mock.NewMockhttpClienter(gomock.NewController(t))
+			if testCase.Breaker == nil {
+				clientMock.EXPECT().Do(gomock.Any()).Return(testCase.Response, testCase.Err)
+			}
+
+			requester := Requester{
+				PetsService: config.ServiceEndpoints{
+					Endpoints: map[string]config.Endpoint{data.endpointAlias: endpoint},
+				},
+				clientHTTP: clientMock,
+				breakers:   map[string]*circuitBreaker{petsServiceName: testCase.Breaker},
+			}
+
+			calls := requesterCalls.WithLabelValues(petsServiceName, data.endpointAlias, testCase.ExpectedStatus)
+			callsBefore := testutil.ToFloat64(calls)
+
+			_, _ = doRequest[testErrorResponse](context.Background(), &requester, data)
+			assert.Equal(t, callsBefore+1, testutil.ToFloat64(calls))
+		})
+	}
+}
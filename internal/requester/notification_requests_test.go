@@ -0,0 +1,131 @@
+package requester
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"telegram-bot/internal/requester/internal/config"
+	"telegram-bot/internal/requester/internal/mock"
+	"testing"
+)
+
+const notificationID = "ab12"
+
+func TestRequesterDeleteNotification(t *testing.T) {
+	notificationsServiceEndpoints := getExpectedNotificationsServiceEndpoints()
+
+	invalidEndpoint := notificationsServiceEndpoints[deleteNotification]
+	invalidEndpoint.Method = "vamos a bailar el paso del chiquilín"
+
+	requester := Requester{
+		NotificationsService: config.ServiceEndpoints{
+			Endpoints: notificationsServiceEndpoints,
+		},
+	}
+
+	notificationsServiceError := notificationServiceErrorResponse{
+		StatusCode: http.StatusNotFound,
+		Message:    "error la noche no es para dormir",
+	}
+	serviceErrorRaw, err := json.Marshal(notificationsServiceError)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		Name             string
+		Requester        Requester
+		ClientMockConfig *clientMockConfig
+		ExpectsError     bool
+		ExpectedError    error
+	}{
+		{
+			Name: "Endpoint does not exist",
+			Requester: Requester{
+				NotificationsService: config.ServiceEndpoints{Endpoints: map[string]config.Endpoint{}},
+			},
+			ExpectsError:  true,
+			ExpectedError: errEndpointDoesNotExist,
+		},
+		{
+			Name: "Error creating request",
+			Requester: Requester{
+				NotificationsService: config.ServiceEndpoints{Endpoints: map[string]config.Endpoint{
+					deleteNotification: invalidEndpoint,
+				}},
+			},
+			ExpectsError:  true,
+			ExpectedError: errCreatingRequest,
+		},
+		{
+			Name:      "Error performing request",
+			Requester: requester,
+			ClientMockConfig: &clientMockConfig{
+				ResponseBody: nil,
+				Err:          fmt.Errorf("internal error performing request"),
+			},
+			ExpectsError:  true,
+			ExpectedError: errPerformingRequest,
+		},
+		{
+			Name:      "Error nil response",
+			Requester: requester,
+			ClientMockConfig: &clientMockConfig{
+				ResponseBody: nil,
+				Err:          nil,
+			},
+			ExpectsError:  true,
+			ExpectedError: errNilResponse,
+		},
+		{
+			Name:      "Error from notifications service",
+			Requester: requester,
+			ClientMockConfig: &clientMockConfig{
+				ResponseBody: &http.Response{
+					StatusCode: http.StatusNotFound,
+					Body:       io.NopCloser(bytes.NewBuffer(serviceErrorRaw)),
+				},
+				Err: nil,
+			},
+			ExpectsError:  true,
+			ExpectedError: fmt.Errorf(notificationsServiceError.GetMessage()),
+		},
+		{
+			Name:      "Delete notification correctly",
+			Requester: requester,
+			ClientMockConfig: &clientMockConfig{
+				ResponseBody: &http.Response{
+					StatusCode: http.StatusNoContent,
+					Body:       nil,
+				},
+				Err: nil,
+			},
+			ExpectsError: false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			clientMock := mock.NewMockhttpClienter(gomock.NewController(t))
+			if testCase.ClientMockConfig != nil {
+				clientMock.EXPECT().
+					Do(gomock.Any()).
+					Return(testCase.ClientMockConfig.ResponseBody, testCase.ClientMockConfig.Err)
+			}
+
+			testCase.Requester.clientHTTP = clientMock
+
+			err := testCase.Requester.DeleteNotification(context.Background(), notificationID)
+			if testCase.ExpectsError {
+				assert.ErrorContains(t, err, testCase.ExpectedError.Error())
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -2,6 +2,7 @@ package requester
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/golang/mock/gomock"
@@ -175,7 +176,7 @@ func TestRequesterGetPetsByOwnerID(t *testing.T) {
 
 			testCase.Requester.clientHTTP = clientMock
 
-			petsDataResponse, err := testCase.Requester.GetPetsByOwnerID(ownerID)
+			petsDataResponse, err := testCase.Requester.GetPetsByOwnerID(context.Background(), ownerID)
 			if testCase.ExpectsError {
 				assert.ErrorContains(t, err, testCase.ExpectedError.Error())
 				return
@@ -302,7 +303,7 @@ func TestRequesterRegisterPet(t *testing.T) {
 
 			testCase.Requester.clientHTTP = clientMock
 
-			err := testCase.Requester.RegisterPet(petRequest)
+			err := testCase.Requester.RegisterPet(context.Background(), petRequest)
 			if testCase.ExpectsError {
 				assert.ErrorContains(t, err, testCase.ExpectedError.Error())
 				return
@@ -445,7 +446,7 @@ func TestRequesterGetPetDataByID(t *testing.T) {
 			}
 
 			testCase.Requester.clientHTTP = clientMock
-			petDataResponse, err := testCase.Requester.GetPetData(petID)
+			petDataResponse, err := testCase.Requester.GetPetData(context.Background(), petID)
 			if testCase.ExpectsError {
 				assert.ErrorContains(t, err, testCase.ExpectedError.Error())
 				return
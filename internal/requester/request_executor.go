@@ -0,0 +1,279 @@
+package requester
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"io"
+	"net/http"
+	"telegram-bot/internal/requester/internal/config"
+	"telegram-bot/internal/utils/logging"
+	"telegram-bot/internal/utils/urlutils"
+	"time"
+)
+
+// requestData contains everything that is needed to perform a request against an endpoint of a service
+type requestData struct {
+	// operation name of the Requester method, used in errors and logs
+	operation string
+	// serviceName key of the service in the config file, used to pick its endpoints and circuit breaker
+	serviceName   string
+	endpointAlias string
+	// pathParams values that replace the placeholders of the endpoint path, eg: {petID}
+	pathParams map[string]string
+	headers    map[string]string
+	// body if not nil, is marshalled as JSON and sent as the request body
+	body any
+	// errMarshallingBody sentinel error returned if the body cannot be marshalled
+	errMarshallingBody error
+	// errUnmarshallingResponse sentinel error returned if the response body cannot be unmarshalled
+	errUnmarshallingResponse error
+}
+
+// doRequestWithResponse performs the request and unmarshals the response body into ResponseType.
+// ServiceErrorType is the error format of the service that is being called
+func doRequestWithResponse[ResponseType any, ServiceErrorType serviceError](ctx context.Context, r *Requester, data requestData) (ResponseType, error) {
+	var response ResponseType
+	responseBody, err := doRequest[ServiceErrorType](ctx, r, data)
+	if err != nil {
+		return response, err
+	}
+
+	err = json.Unmarshal(responseBody, &response)
+	if err != nil {
+		logging.FromContext(ctx).Errorf("error unmarshalling response of %s: %v", data.operation, err)
+		return response, NewRequestError(
+			fmt.Errorf("%w: %v", data.errUnmarshallingResponse, err),
+			http.StatusInternalServerError,
+			data.operation,
+		)
+	}
+
+	return response, nil
+}
+
+// doRequest performs the request and applies the error policy of the service. If the request is successful
+// the raw response body is returned. ServiceErrorType is the error format of the service that is being called.
+// If the endpoint has a cache policy, fresh cached responses are returned without calling the service and stale
+// ones are returned if the service fails. All the errors are RequestError
+func doRequest[ServiceErrorType serviceError](ctx context.Context, r *Requester, data requestData) ([]byte, error) {
+	r = r.snapshot()
+	endpointData, err := r.getEndpoint(data.serviceName, data.endpointAlias)
+	if err != nil {
+		logging.FromContext(ctx).Errorf("%v", err)
+		return nil, NewRequestError(
+			fmt.Errorf("%w: %s", errEndpointDoesNotExist, data.endpointAlias),
+			http.StatusInternalServerError,
+			data.operation,
+		)
+	}
+
+	var rawBody []byte
+	if data.body != nil {
+		rawBody, err = json.Marshal(data.body)
+		if err != nil {
+			logging.FromContext(ctx).Errorf("error marshalling body of %s: %v", data.operation, err)
+			return nil, NewRequestError(
+				fmt.Errorf("%w: %v", data.errMarshallingBody, err),
+				http.StatusInternalServerError,
+				data.operation,
+			)
+		}
+	}
+
+	if responseBody, found := r.responseCache.fresh(data, endpointData); found {
+		return responseBody, nil
+	}
+
+	responseBody, err := callEndpoint[ServiceErrorType](ctx, r, data, endpointData, rawBody)
+	if err != nil {
+		if staleBody, found := r.responseCache.stale(data, endpointData); found && isServiceFailure(err) {
+			logging.FromContext(ctx).Warnf("serving stale response of %s: %v", data.operation, err)
+			return staleBody, nil
+		}
+
+		return nil, err
+	}
+
+	r.responseCache.store(data, responseBody)
+	r.responseCache.invalidate(data.serviceName, endpointData.Invalidates)
+
+	return responseBody, nil
+}
+
+// callEndpoint performs the request against the service. If the endpoint has a timeout configured, it is applied
+// as a deadline over the given context, and if it has a retry policy the failed attempts are retried within that
+// deadline. If the circuit breaker of the service is open, the request is not performed and a service unavailable
+// error is returned. The call is traced with a span that is a child of the one in the given context
+func callEndpoint[ServiceErrorType serviceError](
+	ctx context.Context,
+	r *Requester,
+	data requestData,
+	endpointData config.Endpoint,
+	rawBody []byte,
+) (responseBody []byte, err error) {
+	ctx, span := startCallSpan(ctx, data, endpointData)
+	defer func() {
+		endCallSpan(span, err)
+	}()
+
+	if endpointData.Timeout.Duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, endpointData.Timeout.Duration)
+		defer cancel()
+	}
+
+	breaker := r.breakers[data.serviceName]
+	call, allowed := breaker.allow()
+	if !allowed {
+		requesterCalls.WithLabelValues(data.serviceName, data.endpointAlias, statusCircuitOpen).Inc()
+		logging.FromContext(ctx).Errorf("%v: %s, %s not performed", errServiceUnavailable, data.serviceName, data.operation)
+		return nil, NewRequestError(
+			fmt.Errorf("%w: %s", errServiceUnavailable, data.serviceName),
+			http.StatusServiceUnavailable,
+			data.operation,
+		)
+	}
+
+	start := time.Now()
+	var response *http.Response
+	for attempt := 1; ; attempt++ {
+		var request *http.Request
+		request, err = newRequest(ctx, endpointData, data, rawBody)
+		if err != nil {
+			breaker.record(call, callIgnored)
+			logging.FromContext(ctx).Errorf("error creating %s request: %v", data.operation, err)
+			return nil, NewRequestError(
+				fmt.Errorf("%w: %v", errCreatingRequest, err),
+				http.StatusInternalServerError,
+				data.operation,
+			)
+		}
+
+		response, err = r.clientHTTP.Do(request)
+		if !shouldRetry(ctx, endpointData, attempt, response, err) {
+			break
+		}
+
+		reason := err
+		if reason == nil {
+			reason = fmt.Errorf("status code %d", response.StatusCode)
+			closeResponseBody(response)
+		}
+
+		backoff := retryBackoff(endpointData.Retry, attempt)
+		logging.FromContext(ctx).Warnf(
+			"retrying %s in %v, attempt %d of %d failed: %v",
+			data.operation,
+			backoff,
+			attempt,
+			endpointData.Retry.MaxAttempts,
+			reason,
+		)
+		requesterRetries.WithLabelValues(data.serviceName, data.endpointAlias).Inc()
+
+		if waitErr := waitBackoff(ctx, backoff); waitErr != nil {
+			response, err = nil, fmt.Errorf("%w while retrying: %v", waitErr, reason)
+			break
+		}
+	}
+
+	breaker.record(call, getCallOutcome(ctx, response, err))
+	observeCall(data, start, response, err)
+	if response != nil {
+		span.SetAttributes(semconv.HTTPResponseStatusCode(response.StatusCode))
+	}
+
+	if err != nil {
+		logging.FromContext(ctx).Errorf("error performing %s request: %v", data.operation, err)
+		statusCode := http.StatusInternalServerError
+		if errors.Is(err, context.DeadlineExceeded) {
+			statusCode = http.StatusGatewayTimeout
+		}
+
+		return nil, NewRequestError(
+			fmt.Errorf("%w %s", errPerformingRequest, data.operation),
+			statusCode,
+			err.Error(),
+		)
+	}
+
+	defer closeResponseBody(response)
+
+	if response == nil {
+		logging.FromContext(ctx).Errorf("%v in %s", errNilResponse, data.operation)
+		return nil, NewRequestError(
+			errNilResponse,
+			http.StatusInternalServerError,
+			data.operation,
+		)
+	}
+
+	err = ErrPolicyFunc[ServiceErrorType](response)
+	if err != nil {
+		logging.FromContext(ctx).Errorf("error from service in %s: %v", data.operation, err)
+		return nil, NewRequestError(
+			err,
+			response.StatusCode,
+			"",
+		)
+	}
+
+	if response.Body == nil {
+		return nil, nil
+	}
+
+	responseBody, err = io.ReadAll(response.Body)
+	if err != nil {
+		logging.FromContext(ctx).Errorf("error reading response body of %s: %v", data.operation, err)
+		return nil, NewRequestError(
+			errReadingResponseBody,
+			http.StatusInternalServerError,
+			data.operation,
+		)
+	}
+
+	return responseBody, nil
+}
+
+// newRequest creates the request for the endpoint. Must be called for each attempt, so the body can be sent again.
+// If the context has a request ID, it is sent in the RequestIDHeader, and the trace context is sent in the
+// W3C headers
+func newRequest(ctx context.Context, endpointData config.Endpoint, data requestData, rawBody []byte) (*http.Request, error) {
+	var requestBody io.Reader
+	if rawBody != nil {
+		requestBody = bytes.NewReader(rawBody)
+	}
+
+	url := urlutils.FormatURL(endpointData.GetURL(), data.pathParams)
+	request, err := http.NewRequestWithContext(ctx, endpointData.Method, url, requestBody)
+	if err != nil {
+		return nil, err
+	}
+
+	if endpointData.QueryParams != nil {
+		urlutils.AddQueryParams(request, endpointData.QueryParams.ToMap())
+	}
+
+	setTelegramHeader(request)
+	if requestID := logging.RequestID(ctx); requestID != "" {
+		request.Header.Set(logging.RequestIDHeader, requestID)
+	}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(request.Header))
+	for header, value := range data.headers {
+		request.Header.Add(header, value)
+	}
+
+	return request, nil
+}
+
+func closeResponseBody(response *http.Response) {
+	if response != nil && response.Body != nil {
+		_ = response.Body.Close()
+	}
+}
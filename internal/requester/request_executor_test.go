@@ -0,0 +1,128 @@
+package requester
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"telegram-bot/internal/requester/internal/config"
+	"telegram-bot/internal/requester/internal/mock"
+	"telegram-bot/internal/utils/logging"
+	"testing"
+	"time"
+)
+
+type testResponse struct {
+	Name string `json:"name"`
+}
+
+func TestDoRequestWithResponse(t *testing.T) {
+	endpoint := config.Endpoint{
+		Path:        "/song/{songID}",
+		Method:      http.MethodPost,
+		QueryParams: &config.QueryParams{Limit: 5, Offset: 1},
+	}
+	endpoint.SetBaseURL(testBaseURL)
+
+	requester := Requester{
+		PetsService: config.ServiceEndpoints{
+			Endpoints: map[string]config.Endpoint{"get_song": endpoint},
+		},
+	}
+	data := requestData{
+		operation:                "GetSong",
+		serviceName:              petsServiceName,
+		endpointAlias:            "get_song",
+		pathParams:               map[string]string{"songID": "69"},
+		headers:                  map[string]string{headerTelegramID: "911"},
+		body:                     testResponse{Name: "Mil horas"},
+		errUnmarshallingResponse: errUnmarshallingPetData,
+	}
+
+	t.Run("Request is built with endpoint data", func(t *testing.T) {
+		clientMock := mock.NewMockhttpClienter(gomock.NewController(t))
+		clientMock.EXPECT().
+			Do(gomock.Any()).
+			DoAndReturn(func(request *http.Request) (*http.Response, error) {
+				assert.Equal(t, http.MethodPost, request.Method)
+				assert.Equal(t, testBaseURL+"/song/69", request.URL.Scheme+"://"+request.URL.Host+request.URL.Path)
+				assert.Equal(t, "5", request.URL.Query().Get("limit"))
+				assert.Equal(t, "1", request.URL.Query().Get("offset"))
+				assert.Equal(t, "911", request.Header.Get(headerTelegramID))
+				assert.Equal(t, "true", request.Header.Get(telegramHeader))
+				assert.Equal(t, "request-69", request.Header.Get(logging.RequestIDHeader))
+
+				rawBody, err := io.ReadAll(request.Body)
+				require.NoError(t, err)
+				assert.JSONEq(t, `{"name": "Mil horas"}`, string(rawBody))
+
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewBufferString(`{"name": "Bailando"}`)),
+				}, nil
+			})
+		requester.clientHTTP = clientMock
+
+		ctx := logging.WithRequestID(context.Background(), "request-69")
+		response, err := doRequestWithResponse[testResponse, testErrorResponse](ctx, &requester, data)
+		require.NoError(t, err)
+		assert.Equal(t, testResponse{Name: "Bailando"}, response)
+	})
+
+	t.Run("Errors are request errors", func(t *testing.T) {
+		clientMock := mock.NewMockhttpClienter(gomock.NewController(t))
+		clientMock.EXPECT().
+			Do(gomock.Any()).
+			Return(&http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewBufferString(`{"name": 69}`)),
+			}, nil)
+		requester.clientHTTP = clientMock
+
+		_, err := doRequestWithResponse[testResponse, testErrorResponse](context.Background(), &requester, data)
+
+		var requestErr RequestError
+		require.True(t, errors.As(err, &requestErr))
+		assert.Equal(t, http.StatusInternalServerError, requestErr.StatusCode())
+		assert.ErrorIs(t, err, errUnmarshallingPetData)
+	})
+}
+
+func TestDoRequestAppliesEndpointTimeout(t *testing.T) {
+	endpoint := config.Endpoint{
+		Path:    "/song",
+		Method:  http.MethodGet,
+		Timeout: config.Duration{Duration: time.Second},
+	}
+
+	clientMock := mock.NewMockhttpClienter(gomock.NewController(t))
+	clientMock.EXPECT().
+		Do(gomock.Any()).
+		DoAndReturn(func(request *http.Request) (*http.Response, error) {
+			deadline, found := request.Context().Deadline()
+			assert.True(t, found)
+			assert.WithinDuration(t, time.Now().Add(time.Second), deadline, 100*time.Millisecond)
+
+			return nil, context.DeadlineExceeded
+		})
+
+	requester := Requester{
+		PetsService: config.ServiceEndpoints{
+			Endpoints: map[string]config.Endpoint{"get_song": endpoint},
+		},
+		clientHTTP: clientMock,
+	}
+	_, err := doRequest[testErrorResponse](context.Background(), &requester, requestData{
+		operation:     "GetSong",
+		serviceName:   petsServiceName,
+		endpointAlias: "get_song",
+	})
+
+	var requestErr RequestError
+	require.True(t, errors.As(err, &requestErr))
+	assert.Equal(t, http.StatusGatewayTimeout, requestErr.StatusCode())
+}
@@ -1,46 +1,105 @@
 package requester
 
 import (
-	"encoding/json"
+	"fmt"
 	"net/http"
+	"sync/atomic"
 	"telegram-bot/internal/requester/internal/config"
-	"telegram-bot/internal/utils"
 )
 
+const telegramHeader = "X-Telegram-App"
+
+// Services names, they match the keys of the config file
 const (
-	configFilePath = "internal/requester/internal/config/config.json"
-	telegramHeader = "X-Telegram-App"
+	petsServiceName          = "pets_service"
+	treatmentsServiceName    = "treatments_service"
+	usersServiceName         = "users_service"
+	notificationsServiceName = "notifications_service"
 )
 
 type httpClienter interface {
 	Do(req *http.Request) (*http.Response, error)
 }
 
+// Requester performs the requests to the services. The config of the services can be reloaded while the bot
+// is running, see Reload, so the exported fields only hold the config that was loaded when it was created
 type Requester struct {
 	PetsService          config.ServiceEndpoints `json:"pets_service"`
 	TreatmentsService    config.ServiceEndpoints `json:"treatments_service"`
 	UsersService         config.ServiceEndpoints `json:"users_service"`
 	NotificationsService config.ServiceEndpoints `json:"notifications_service"`
 	clientHTTP           httpClienter
+	// breakers circuit breakers by service name
+	breakers map[string]*circuitBreaker
+	// responseCache cached responses of the endpoints that have a cache policy
+	responseCache *responseCache
+	// latest requester built with the latest valid config. If it is nil, the config is never reloaded
+	latest *atomic.Pointer[Requester]
 }
 
 func NewRequester(client httpClienter) (*Requester, error) {
-	rawFileData, err := utils.ReadFileWithPath(configFilePath, "requester.go")
+	requester, err := newRequesterFromConfig(client)
 	if err != nil {
 		return nil, err
 	}
 
-	var requester Requester
-	err = json.Unmarshal(rawFileData, &requester)
+	latest := *requester
+	requester.latest = &atomic.Pointer[Requester]{}
+	requester.latest.Store(&latest)
+
+	return requester, nil
+}
+
+// newRequesterFromConfig loads the config and builds a requester with it, including the circuit breakers and
+// the response cache
+func newRequesterFromConfig(client httpClienter) (*Requester, error) {
+	requester, err := loadConfig()
 	if err != nil {
 		return nil, err
 	}
 
 	requester.clientHTTP = client
 
+	services := requester.services()
+	requester.breakers = make(map[string]*circuitBreaker, len(services))
+	for serviceName, service := range services {
+		requester.breakers[serviceName] = newCircuitBreaker(serviceName, service.CircuitBreaker)
+	}
+	requester.responseCache = newResponseCache(services)
+
 	return &requester, nil
 }
 
+// snapshot returns the requester built with the latest config. Each request must use a single snapshot, so a
+// reload in the middle of a request does not mix two configs
+func (r *Requester) snapshot() *Requester {
+	if r.latest == nil {
+		return r
+	}
+
+	return r.latest.Load()
+}
+
+// getEndpoint returns the endpoint of the service based on the given alias
+func (r *Requester) getEndpoint(serviceName string, endpointAlias string) (config.Endpoint, error) {
+	service, found := r.services()[serviceName]
+	if !found {
+		return config.Endpoint{}, fmt.Errorf("unknown service %s", serviceName)
+	}
+
+	return service.GetEndpoint(endpointAlias)
+}
+
+// services returns the config of each service by its name
+func (r *Requester) services() map[string]*config.ServiceEndpoints {
+	return map[string]*config.ServiceEndpoints{
+		petsServiceName:          &r.PetsService,
+		treatmentsServiceName:    &r.TreatmentsService,
+		usersServiceName:         &r.UsersService,
+		notificationsServiceName: &r.NotificationsService,
+	}
+}
+
 // setTelegramHeader sets a header to indicate that the request come from Telegram Service
 func setTelegramHeader(request *http.Request) {
 	request.Header.Add(telegramHeader, "true")
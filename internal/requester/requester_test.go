@@ -2,6 +2,7 @@ package requester
 
 import (
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"net/http"
 	"telegram-bot/internal/requester/internal/config"
 	"testing"
@@ -13,9 +14,15 @@ type expectedServiceConfig struct {
 }
 
 func TestNewRequester(t *testing.T) {
+	t.Setenv(configPathEnv, "internal/config/config.json")
+	t.Setenv(environmentEnv, "")
+	for _, envVar := range serviceBaseURLEnvs {
+		t.Setenv(envVar, "")
+	}
+
 	client := http.Client{}
 	requester, err := NewRequester(&client)
-	assert.NoError(t, err)
+	require.NoError(t, err)
 
 	expectedPetsServiceConfig := expectedServiceConfig{
 		BaseURL:           "https://api.lnt.digital/pets",
@@ -34,6 +41,12 @@ func TestNewRequester(t *testing.T) {
 		ExpectedEndpoints: getExpectedUsersServiceEndpoints(),
 	}
 	assertServiceConfig(t, requester.UsersService, expectedUsersServiceConfig)
+
+	expectedNotificationsServiceConfig := expectedServiceConfig{
+		BaseURL:           "https://api.lnt.digital/notifications",
+		ExpectedEndpoints: getExpectedNotificationsServiceEndpoints(),
+	}
+	assertServiceConfig(t, requester.NotificationsService, expectedNotificationsServiceConfig)
 }
 
 func assertServiceConfig(t *testing.T, service config.ServiceEndpoints, expectedResults expectedServiceConfig) {
@@ -89,6 +102,10 @@ func getExpectedTreatmentsServiceEndpoints() map[string]config.Endpoint {
 			Path:   "/treatment/specific/{treatmentID}",
 			Method: http.MethodGet,
 		},
+		"add_treatment_comment": {
+			Path:   "/treatment/{treatmentID}/comment",
+			Method: http.MethodPost,
+		},
 		"get_vaccines": {
 			Path:   "/application/pet/{petID}",
 			Method: http.MethodGet,
@@ -108,3 +125,16 @@ func getExpectedUsersServiceEndpoints() map[string]config.Endpoint {
 		},
 	}
 }
+
+func getExpectedNotificationsServiceEndpoints() map[string]config.Endpoint {
+	return map[string]config.Endpoint{
+		"schedule_notifications": {
+			Path:   "/notification",
+			Method: http.MethodPost,
+		},
+		"delete_notification": {
+			Path:   "/notification/{notificationID}",
+			Method: http.MethodDelete,
+		},
+	}
+}
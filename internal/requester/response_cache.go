@@ -0,0 +1,107 @@
+package requester
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"telegram-bot/internal/requester/internal/cache"
+	"telegram-bot/internal/requester/internal/config"
+	"time"
+)
+
+// responseCache caches the raw responses of the GET endpoints that have a cache policy. There is one LRU for each
+// endpoint, so the bounds of an endpoint do not affect the others. A nil responseCache does not cache anything
+type responseCache struct {
+	// caches LRU by service name and endpoint alias
+	caches map[string]map[string]*cache.LRU
+	now    func() time.Time
+}
+
+func newResponseCache(services map[string]*config.ServiceEndpoints) *responseCache {
+	caches := make(map[string]map[string]*cache.LRU)
+	for serviceName, service := range services {
+		for alias, endpoint := range service.Endpoints {
+			if endpoint.Cache == nil || endpoint.Method != http.MethodGet {
+				continue
+			}
+
+			if caches[serviceName] == nil {
+				caches[serviceName] = make(map[string]*cache.LRU)
+			}
+			caches[serviceName][alias] = cache.NewLRU(endpoint.Cache.MaxEntries)
+		}
+	}
+
+	return &responseCache{
+		caches: caches,
+		now:    time.Now,
+	}
+}
+
+// fresh returns the cached response of the request if it is younger than the TTL of the endpoint
+func (rc *responseCache) fresh(data requestData, endpoint config.Endpoint) ([]byte, bool) {
+	return rc.get(data, endpoint, func(policy *config.CachePolicy) time.Duration {
+		return policy.TTL.Duration
+	})
+}
+
+// stale returns the cached response of the request if it has not been expired for longer than the StaleTTL
+// of the endpoint. It is meant to be used when the service fails
+func (rc *responseCache) stale(data requestData, endpoint config.Endpoint) ([]byte, bool) {
+	return rc.get(data, endpoint, func(policy *config.CachePolicy) time.Duration {
+		return policy.TTL.Duration + policy.StaleTTL.Duration
+	})
+}
+
+func (rc *responseCache) get(data requestData, endpoint config.Endpoint, maxAge func(*config.CachePolicy) time.Duration) ([]byte, bool) {
+	lru := rc.lru(data.serviceName, data.endpointAlias)
+	if lru == nil {
+		return nil, false
+	}
+
+	entry, found := lru.Get(cacheKey(data))
+	if !found || entry.Age(rc.now()) >= maxAge(endpoint.Cache) {
+		return nil, false
+	}
+
+	return entry.Value, true
+}
+
+// store caches the response of the request if the endpoint has a cache policy
+func (rc *responseCache) store(data requestData, responseBody []byte) {
+	lru := rc.lru(data.serviceName, data.endpointAlias)
+	if lru == nil {
+		return
+	}
+
+	lru.Set(cacheKey(data), responseBody, rc.now())
+}
+
+// invalidate discards all the cached responses of the given endpoints of the service
+func (rc *responseCache) invalidate(serviceName string, endpointAliases []string) {
+	for _, alias := range endpointAliases {
+		if lru := rc.lru(serviceName, alias); lru != nil {
+			lru.Purge()
+		}
+	}
+}
+
+func (rc *responseCache) lru(serviceName string, endpointAlias string) *cache.LRU {
+	if rc == nil {
+		return nil
+	}
+
+	return rc.caches[serviceName][endpointAlias]
+}
+
+// cacheKey identifies the request within its endpoint. fmt prints maps sorted by key, so the key is deterministic
+func cacheKey(data requestData) string {
+	return fmt.Sprint(data.pathParams, data.headers)
+}
+
+// isServiceFailure returns true if the error is due to the service being down or failing, so a stale response
+// is better than an error
+func isServiceFailure(err error) bool {
+	var requestErr RequestError
+	return errors.As(err, &requestErr) && requestErr.StatusCode() >= http.StatusInternalServerError
+}
@@ -0,0 +1,165 @@
+package requester
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"telegram-bot/internal/requester/internal/config"
+	"telegram-bot/internal/requester/internal/mock"
+	"testing"
+	"time"
+)
+
+func newCachedPetsService() config.ServiceEndpoints {
+	return config.ServiceEndpoints{
+		Endpoints: map[string]config.Endpoint{
+			getPetByID: {
+				Path:   "/pet/{petID}",
+				Method: http.MethodGet,
+				Cache: &config.CachePolicy{
+					TTL:        config.Duration{Duration: time.Minute},
+					StaleTTL:   config.Duration{Duration: time.Hour},
+					MaxEntries: 10,
+				},
+			},
+			registerPet: {
+				Path:        "/pet",
+				Method:      http.MethodPost,
+				Invalidates: []string{getPetByID},
+			},
+		},
+	}
+}
+
+func okResponse(body string) *http.Response {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(bytes.NewBufferString(body)),
+	}
+}
+
+func TestDoRequestWithResponseCache(t *testing.T) {
+	petsService := newCachedPetsService()
+	getPetData := func(petID string) requestData {
+		return requestData{
+			operation:     "GetPetData",
+			serviceName:   petsServiceName,
+			endpointAlias: getPetByID,
+			pathParams:    map[string]string{"petID": petID},
+		}
+	}
+	registerPetData := requestData{
+		operation:     "RegisterPet",
+		serviceName:   petsServiceName,
+		endpointAlias: registerPet,
+	}
+
+	newTestRequester := func(t *testing.T, now *time.Time) (*Requester, *mock.MockhttpClienter) {
+		clientMock := mock.NewMockhttpClienter(gomock.NewController(t))
+		responseCache := newResponseCache(map[string]*config.ServiceEndpoints{petsServiceName: &petsService})
+		responseCache.now = func() time.Time { return *now }
+
+		return &Requester{PetsService: petsService, clientHTTP: clientMock, responseCache: responseCache}, clientMock
+	}
+
+	t.Run("Fresh response is served from the cache", func(t *testing.T) {
+		now := time.Now()
+		requester, clientMock := newTestRequester(t, &now)
+		clientMock.EXPECT().Do(gomock.Any()).Return(okResponse(`{"name": "Bachicha"}`), nil).Times(1)
+
+		for i := 0; i < 2; i++ {
+			response, err := doRequestWithResponse[testResponse, testErrorResponse](context.Background(), requester, getPetData("69"))
+			require.NoError(t, err)
+			assert.Equal(t, testResponse{Name: "Bachicha"}, response)
+		}
+	})
+
+	t.Run("Requests with different params are cached separately", func(t *testing.T) {
+		now := time.Now()
+		requester, clientMock := newTestRequester(t, &now)
+		clientMock.EXPECT().Do(gomock.Any()).Return(okResponse(`{"name": "Bachicha"}`), nil)
+		clientMock.EXPECT().Do(gomock.Any()).Return(okResponse(`{"name": "Salchicha"}`), nil)
+
+		_, err := doRequest[testErrorResponse](context.Background(), requester, getPetData("69"))
+		require.NoError(t, err)
+		response, err := doRequestWithResponse[testResponse, testErrorResponse](context.Background(), requester, getPetData("420"))
+		require.NoError(t, err)
+		assert.Equal(t, testResponse{Name: "Salchicha"}, response)
+	})
+
+	t.Run("Expired response is fetched again", func(t *testing.T) {
+		now := time.Now()
+		requester, clientMock := newTestRequester(t, &now)
+		clientMock.EXPECT().Do(gomock.Any()).Return(okResponse(`{"name": "Bachicha"}`), nil)
+		clientMock.EXPECT().Do(gomock.Any()).Return(okResponse(`{"name": "Salchicha"}`), nil)
+
+		_, err := doRequest[testErrorResponse](context.Background(), requester, getPetData("69"))
+		require.NoError(t, err)
+
+		now = now.Add(time.Minute)
+		response, err := doRequestWithResponse[testResponse, testErrorResponse](context.Background(), requester, getPetData("69"))
+		require.NoError(t, err)
+		assert.Equal(t, testResponse{Name: "Salchicha"}, response)
+	})
+
+	t.Run("Writes invalidate cached responses", func(t *testing.T) {
+		now := time.Now()
+		requester, clientMock := newTestRequester(t, &now)
+		clientMock.EXPECT().Do(gomock.Any()).Return(okResponse(`{"name": "Bachicha"}`), nil)
+		clientMock.EXPECT().Do(gomock.Any()).Return(&http.Response{StatusCode: http.StatusCreated}, nil)
+		clientMock.EXPECT().Do(gomock.Any()).Return(okResponse(`{"name": "Salchicha"}`), nil)
+
+		_, err := doRequest[testErrorResponse](context.Background(), requester, getPetData("69"))
+		require.NoError(t, err)
+		_, err = doRequest[testErrorResponse](context.Background(), requester, registerPetData)
+		require.NoError(t, err)
+
+		response, err := doRequestWithResponse[testResponse, testErrorResponse](context.Background(), requester, getPetData("69"))
+		require.NoError(t, err)
+		assert.Equal(t, testResponse{Name: "Salchicha"}, response)
+	})
+
+	t.Run("Stale response is served if the service fails", func(t *testing.T) {
+		now := time.Now()
+		requester, clientMock := newTestRequester(t, &now)
+		clientMock.EXPECT().Do(gomock.Any()).Return(okResponse(`{"name": "Bachicha"}`), nil)
+		clientMock.EXPECT().Do(gomock.Any()).Return(nil, fmt.Errorf("connection refused")).Times(2)
+
+		_, err := doRequest[testErrorResponse](context.Background(), requester, getPetData("69"))
+		require.NoError(t, err)
+
+		now = now.Add(30 * time.Minute)
+		response, err := doRequestWithResponse[testResponse, testErrorResponse](context.Background(), requester, getPetData("69"))
+		require.NoError(t, err)
+		assert.Equal(t, testResponse{Name: "Bachicha"}, response)
+
+		now = now.Add(time.Hour)
+		_, err = doRequest[testErrorResponse](context.Background(), requester, getPetData("69"))
+		assert.ErrorIs(t, err, errPerformingRequest)
+	})
+
+	t.Run("Stale response is not served on client errors", func(t *testing.T) {
+		now := time.Now()
+		requester, clientMock := newTestRequester(t, &now)
+		clientMock.EXPECT().Do(gomock.Any()).Return(okResponse(`{"name": "Bachicha"}`), nil)
+		clientMock.EXPECT().Do(gomock.Any()).Return(&http.Response{
+			StatusCode: http.StatusNotFound,
+			Body:       io.NopCloser(bytes.NewBufferString(`{"message": "pet not found"}`)),
+		}, nil)
+
+		_, err := doRequest[testErrorResponse](context.Background(), requester, getPetData("69"))
+		require.NoError(t, err)
+
+		now = now.Add(2 * time.Minute)
+		_, err = doRequest[testErrorResponse](context.Background(), requester, getPetData("69"))
+
+		var requestErr RequestError
+		require.ErrorAs(t, err, &requestErr)
+		assert.True(t, requestErr.IsNotFound())
+	})
+}
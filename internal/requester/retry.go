@@ -0,0 +1,69 @@
+package requester
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"telegram-bot/internal/requester/internal/config"
+	"telegram-bot/internal/utils"
+	"time"
+)
+
+// idempotentMethods methods that can be retried safely
+var idempotentMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodOptions,
+	http.MethodPut,
+	http.MethodDelete,
+}
+
+// shouldRetry returns true if the attempt must be retried based on the retry policy of the endpoint. Only calls to
+// idempotent methods are retried, and never if the context is done
+func shouldRetry(ctx context.Context, endpointData config.Endpoint, attempt int, response *http.Response, err error) bool {
+	policy := endpointData.Retry
+	if policy == nil || attempt >= policy.MaxAttempts || ctx.Err() != nil {
+		return false
+	}
+
+	if !utils.Contains(idempotentMethods, endpointData.Method) {
+		return false
+	}
+
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+
+	return response != nil && policy.ShouldRetryStatus(response.StatusCode)
+}
+
+// retryBackoff returns the time to wait before the next attempt. The backoff grows exponentially with each attempt
+// up to MaxBackoff, and a random jitter is applied over it so the clients do not retry all at the same time
+func retryBackoff(policy *config.RetryPolicy, attempt int) time.Duration {
+	backoff := policy.InitialBackoff.Duration
+	for i := 1; i < attempt && backoff < policy.MaxBackoff.Duration; i++ {
+		backoff *= 2
+	}
+
+	if backoff > policy.MaxBackoff.Duration {
+		backoff = policy.MaxBackoff.Duration
+	}
+
+	// Equal jitter: wait at least half of the backoff
+	halfBackoff := backoff / 2
+	return halfBackoff + time.Duration(rand.Int63n(int64(halfBackoff)+1))
+}
+
+// waitBackoff blocks during the given backoff. Returns an error if the context is done before
+func waitBackoff(ctx context.Context, backoff time.Duration) error {
+	timer := time.NewTimer(backoff)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,171 @@
+package requester
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"telegram-bot/internal/requester/internal/config"
+	"telegram-bot/internal/requester/internal/mock"
+	"testing"
+	"time"
+)
+
+func testRetryPolicy() *config.RetryPolicy {
+	return &config.RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: config.Duration{Duration: time.Millisecond},
+		MaxBackoff:     config.Duration{Duration: 4 * time.Millisecond},
+		StatusCodes:    []int{http.StatusServiceUnavailable},
+	}
+}
+
+func TestShouldRetry(t *testing.T) {
+	getEndpoint := config.Endpoint{Method: http.MethodGet, Retry: testRetryPolicy()}
+	postEndpoint := config.Endpoint{Method: http.MethodPost, Retry: testRetryPolicy()}
+	cancelledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	testCases := []struct {
+		Name          string
+		Ctx           context.Context
+		Endpoint      config.Endpoint
+		Attempt       int
+		Response      *http.Response
+		Err           error
+		ExpectedRetry bool
+	}{
+		{
+			Name:          "Endpoint without retry policy",
+			Ctx:           context.Background(),
+			Endpoint:      config.Endpoint{Method: http.MethodGet},
+			Attempt:       1,
+			Err:           fmt.Errorf("connection reset by peer"),
+			ExpectedRetry: false,
+		},
+		{
+			Name:          "Non idempotent method",
+			Ctx:           context.Background(),
+			Endpoint:      postEndpoint,
+			Attempt:       1,
+			Err:           fmt.Errorf("connection reset by peer"),
+			ExpectedRetry: false,
+		},
+		{
+			Name:          "Max attempts reached",
+			Ctx:           context.Background(),
+			Endpoint:      getEndpoint,
+			Attempt:       3,
+			Err:           fmt.Errorf("connection reset by peer"),
+			ExpectedRetry: false,
+		},
+		{
+			Name:          "Context is done",
+			Ctx:           cancelledCtx,
+			Endpoint:      getEndpoint,
+			Attempt:       1,
+			Err:           context.Canceled,
+			ExpectedRetry: false,
+		},
+		{
+			Name:          "Error performing request",
+			Ctx:           context.Background(),
+			Endpoint:      getEndpoint,
+			Attempt:       1,
+			Err:           fmt.Errorf("connection reset by peer"),
+			ExpectedRetry: true,
+		},
+		{
+			Name:          "Status code is not retryable",
+			Ctx:           context.Background(),
+			Endpoint:      getEndpoint,
+			Attempt:       1,
+			Response:      &http.Response{StatusCode: http.StatusNotFound},
+			ExpectedRetry: false,
+		},
+		{
+			Name:          "Status code is retryable",
+			Ctx:           context.Background(),
+			Endpoint:      getEndpoint,
+			Attempt:       2,
+			Response:      &http.Response{StatusCode: http.StatusServiceUnavailable},
+			ExpectedRetry: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			retry := shouldRetry(testCase.Ctx, testCase.Endpoint, testCase.Attempt, testCase.Response, testCase.Err)
+			assert.Equal(t, testCase.ExpectedRetry, retry)
+		})
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	policy := &config.RetryPolicy{
+		InitialBackoff: config.Duration{Duration: 100 * time.Millisecond},
+		MaxBackoff:     config.Duration{Duration: time.Second},
+	}
+
+	expectedBackoffs := []time.Duration{
+		100 * time.Millisecond,
+		200 * time.Millisecond,
+		400 * time.Millisecond,
+		800 * time.Millisecond,
+		time.Second,
+		time.Second,
+	}
+
+	for idx, expectedBackoff := range expectedBackoffs {
+		backoff := retryBackoff(policy, idx+1)
+		assert.GreaterOrEqual(t, backoff, expectedBackoff/2)
+		assert.LessOrEqual(t, backoff, expectedBackoff)
+	}
+}
+
+func TestDoRequestRetriesIdempotentCalls(t *testing.T) {
+	endpoint := config.Endpoint{
+		Path:   "/song",
+		Method: http.MethodGet,
+		Retry:  testRetryPolicy(),
+	}
+
+	clientMock := mock.NewMockhttpClienter(gomock.NewController(t))
+	gomock.InOrder(
+		clientMock.EXPECT().
+			Do(gomock.Any()).
+			Return(nil, fmt.Errorf("connection reset by peer")),
+		clientMock.EXPECT().
+			Do(gomock.Any()).
+			Return(&http.Response{
+				StatusCode: http.StatusServiceUnavailable,
+				Body:       io.NopCloser(bytes.NewBufferString(`{"status": 503, "message": "en mantenimiento"}`)),
+			}, nil),
+		clientMock.EXPECT().
+			Do(gomock.Any()).
+			Return(&http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewBufferString(`{"name": "Muchachos"}`)),
+			}, nil),
+	)
+
+	requester := Requester{
+		PetsService: config.ServiceEndpoints{
+			Endpoints: map[string]config.Endpoint{"get_song": endpoint},
+		},
+		clientHTTP: clientMock,
+	}
+	response, err := doRequestWithResponse[testResponse, testErrorResponse](context.Background(), &requester, requestData{
+		operation:                "GetSong",
+		serviceName:              petsServiceName,
+		endpointAlias:            "get_song",
+		errUnmarshallingResponse: errUnmarshallingPetData,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, testResponse{Name: "Muchachos"}, response)
+}
@@ -0,0 +1,41 @@
+package requester
+
+import (
+	"context"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"telegram-bot/internal/requester/internal/config"
+)
+
+const (
+	tracerName = "telegram-bot/internal/requester"
+
+	serviceAttribute  = attribute.Key("requester.service")
+	endpointAttribute = attribute.Key("requester.endpoint")
+)
+
+// startCallSpan starts the span of a call to an endpoint, its name is the operation of the call
+func startCallSpan(ctx context.Context, data requestData, endpointData config.Endpoint) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(
+		ctx,
+		data.operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			serviceAttribute.String(data.serviceName),
+			endpointAttribute.String(data.endpointAlias),
+			semconv.HTTPRequestMethodKey.String(endpointData.Method),
+		),
+	)
+}
+
+// endCallSpan ends the span of a call, marking it as failed if the call returned an error
+func endCallSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
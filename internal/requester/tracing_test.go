@@ -0,0 +1,84 @@
+package requester
+
+import (
+	"bytes"
+	"context"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"io"
+	"net/http"
+	"telegram-bot/internal/requester/internal/config"
+	"telegram-bot/internal/requester/internal/mock"
+	"testing"
+)
+
+func TestDoRequestIsTraced(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+
+	endpoint := config.Endpoint{Path: "/song", Method: http.MethodGet}
+	endpoint.SetBaseURL(testBaseURL)
+
+	testCases := []struct {
+		Name           string
+		StatusCode     int
+		ExpectedStatus codes.Code
+	}{
+		{Name: "Successful call", StatusCode: http.StatusOK, ExpectedStatus: codes.Unset},
+		{Name: "Failed call", StatusCode: http.StatusInternalServerError, ExpectedStatus: codes.Error},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			ctx, parent := provider.Tracer("test").Start(context.Background(), "parent")
+			defer parent.End()
+
+			clientMock := mock.NewMockhttpClienter(gomock.NewController(t))
+			clientMock.EXPECT().
+				Do(gomock.Any()).
+				DoAndReturn(func(request *http.Request) (*http.Response, error) {
+					assert.Contains(t, request.Header.Get("traceparent"), parent.SpanContext().TraceID().String())
+
+					return &http.Response{
+						StatusCode: testCase.StatusCode,
+						Body:       io.NopCloser(bytes.NewBufferString(`{}`)),
+					}, nil
+				})
+
+			requester := Requester{
+				PetsService: config.ServiceEndpoints{
+					Endpoints: map[string]config.Endpoint{"get_song": endpoint},
+				},
+				clientHTTP: clientMock,
+			}
+			_, _ = doRequest[testErrorResponse](ctx, &requester, requestData{
+				operation:     "GetSong",
+				serviceName:   petsServiceName,
+				endpointAlias: "get_song",
+			})
+
+			spans := recorder.Ended()
+			require.NotEmpty(t, spans)
+			span := spans[len(spans)-1]
+			assert.Equal(t, "GetSong", span.Name())
+			assert.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID())
+			assert.Equal(t, testCase.ExpectedStatus, span.Status().Code)
+			assert.Contains(t, span.Attributes(), serviceAttribute.String(petsServiceName))
+			assert.Contains(t, span.Attributes(), semconv.HTTPResponseStatusCode(testCase.StatusCode))
+		})
+	}
+}
@@ -2,6 +2,7 @@ package requester
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/golang/mock/gomock"
@@ -166,7 +167,7 @@ func TestRequesterGetTreatmentsByPetID(t *testing.T) {
 
 			testCase.Requester.clientHTTP = clientMock
 
-			treatmentsResponse, err := testCase.Requester.GetTreatmentsByPetID(petID)
+			treatmentsResponse, err := testCase.Requester.GetTreatmentsByPetID(context.Background(), petID)
 			if testCase.ExpectsError {
 				assert.ErrorContains(t, err, testCase.ExpectedError.Error())
 				return
@@ -328,7 +329,7 @@ func TestRequesterGetTreatment(t *testing.T) {
 			}
 
 			testCase.Requester.clientHTTP = clientMock
-			treatmentResponse, err := testCase.Requester.GetTreatment(treatmentID)
+			treatmentResponse, err := testCase.Requester.GetTreatment(context.Background(), treatmentID)
 			if testCase.ExpectsError {
 				assert.ErrorContains(t, err, testCase.ExpectedError.Error())
 				return
@@ -503,7 +504,7 @@ func TestRequesterGetVaccines(t *testing.T) {
 
 			testCase.Requester.clientHTTP = clientMock
 
-			vaccinesResponse, err := testCase.Requester.GetVaccines(petID)
+			vaccinesResponse, err := testCase.Requester.GetVaccines(context.Background(), petID)
 			if testCase.ExpectsError {
 				assert.ErrorContains(t, err, testCase.ExpectedError.Error())
 				return
@@ -514,3 +515,122 @@ func TestRequesterGetVaccines(t *testing.T) {
 		})
 	}
 }
+
+func TestRequesterAddTreatmentComment(t *testing.T) {
+	treatmentsServiceEndpoints := getExpectedTreatmentsServiceEndpoints()
+
+	invalidEndpoint := treatmentsServiceEndpoints[addTreatmentComment]
+	invalidEndpoint.Method = "quiero que me des un beso"
+
+	requester := Requester{
+		TreatmentsService: config.ServiceEndpoints{
+			Endpoints: treatmentsServiceEndpoints,
+		},
+	}
+
+	treatmentsServiceError := treatmentServiceErrorResponse{
+		Code: http.StatusBadRequest,
+		Msg:  "error el comentario no puede estar vacio",
+	}
+	serviceErrorRaw, err := json.Marshal(treatmentsServiceError)
+	require.NoError(t, err)
+
+	treatmentID := "123abc"
+	commentRequest := domain.CommentRequest{
+		Information: "Ya no se rasca tanto la oreja",
+		Owner:       "Pity Alvarez",
+	}
+
+	testCases := []struct {
+		Name             string
+		Requester        Requester
+		ClientMockConfig *clientMockConfig
+		ExpectsError     bool
+		ExpectedError    error
+	}{
+		{
+			Name: "Endpoint does not exist",
+			Requester: Requester{
+				TreatmentsService: config.ServiceEndpoints{Endpoints: map[string]config.Endpoint{}},
+			},
+			ExpectsError:  true,
+			ExpectedError: errEndpointDoesNotExist,
+		},
+		{
+			Name: "Error creating request",
+			Requester: Requester{
+				TreatmentsService: config.ServiceEndpoints{Endpoints: map[string]config.Endpoint{
+					addTreatmentComment: invalidEndpoint,
+				}},
+			},
+			ExpectsError:  true,
+			ExpectedError: errCreatingRequest,
+		},
+		{
+			Name:      "Error performing request",
+			Requester: requester,
+			ClientMockConfig: &clientMockConfig{
+				ResponseBody: nil,
+				Err:          fmt.Errorf("internal error performing request"),
+			},
+			ExpectsError:  true,
+			ExpectedError: errPerformingRequest,
+		},
+		{
+			Name:      "Error nil response",
+			Requester: requester,
+			ClientMockConfig: &clientMockConfig{
+				ResponseBody: nil,
+				Err:          nil,
+			},
+			ExpectsError:  true,
+			ExpectedError: errNilResponse,
+		},
+		{
+			Name:      "Error from treatments service",
+			Requester: requester,
+			ClientMockConfig: &clientMockConfig{
+				ResponseBody: &http.Response{
+					StatusCode: http.StatusBadRequest,
+					Body:       io.NopCloser(bytes.NewBuffer(serviceErrorRaw)),
+				},
+				Err: nil,
+			},
+			ExpectsError:  true,
+			ExpectedError: fmt.Errorf(treatmentsServiceError.GetMessage()),
+		},
+		{
+			Name:      "Add comment correctly",
+			Requester: requester,
+			ClientMockConfig: &clientMockConfig{
+				ResponseBody: &http.Response{
+					StatusCode: http.StatusCreated,
+					Body:       nil,
+				},
+				Err: nil,
+			},
+			ExpectsError: false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			clientMock := mock.NewMockhttpClienter(gomock.NewController(t))
+			if testCase.ClientMockConfig != nil {
+				clientMock.EXPECT().
+					Do(gomock.Any()).
+					Return(testCase.ClientMockConfig.ResponseBody, testCase.ClientMockConfig.Err)
+			}
+
+			testCase.Requester.clientHTTP = clientMock
+
+			err := testCase.Requester.AddTreatmentComment(context.Background(), treatmentID, commentRequest)
+			if testCase.ExpectsError {
+				assert.ErrorContains(t, err, testCase.ExpectedError.Error())
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
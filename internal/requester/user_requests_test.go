@@ -2,6 +2,7 @@ package requester
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/golang/mock/gomock"
@@ -153,7 +154,7 @@ func TestRequesterGetUser(t *testing.T) {
 
 			testCase.Requester.clientHTTP = clientMock
 
-			petsDataResponse, err := testCase.Requester.GetUserData(telegramID)
+			petsDataResponse, err := testCase.Requester.GetUserData(context.Background(), telegramID)
 			if testCase.ExpectsError {
 				assert.ErrorContains(t, err, testCase.ExpectedError.Error())
 				return
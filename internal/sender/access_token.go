@@ -0,0 +1,313 @@
+package sender
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	secretEnvVar                 = "SECRET"
+	cryptographicAlgorithmEnvVar = "ALGORITHM"
+	accessCodeEnvVar             = "ACCESS_CODE"
+	keysPathEnvVar               = "JWT_KEYS_PATH"
+	issuerEnvVar                 = "JWT_ISSUER"
+	audienceEnvVar               = "JWT_AUDIENCE"
+	leewayEnvVar                 = "JWT_LEEWAY"
+
+	accessCodeClaim = "access_code"
+	bearerPrefix    = "Bearer "
+	defaultLeeway   = 30 * time.Second
+	// defaultKeyID kid of the key set with SECRET and ALGORITHM, it verifies the tokens that do not have a kid
+	defaultKeyID = ""
+)
+
+var (
+	errTokenMissing         = errors.New("error token is missing")
+	errInvalidToken         = errors.New("error invalid JWT")
+	errUnknownKeyID         = errors.New("error unknown kid")
+	errUnexpectedAlgorithm  = errors.New("error unexpected signing method")
+	errMissingClaim         = errors.New("error required claim is missing")
+	errInvalidAccessCode    = errors.New("error invalid access code")
+	errNoVerificationKeys   = errors.New("error there are no keys to verify the access tokens")
+	errInvalidKeyConfig     = errors.New("error invalid verification key")
+	errUnsupportedAlgorithm = errors.New("error unsupported signing method")
+)
+
+// keyConfig key of the keys file. HMAC keys have a secret and RSA or ECDSA keys the path of their PEM public key,
+// relative paths are resolved from the directory of the keys file
+type keyConfig struct {
+	KeyID         string `json:"kid"`
+	Algorithm     string `json:"algorithm"`
+	Secret        string `json:"secret"`
+	PublicKeyPath string `json:"public_key_path"`
+}
+
+type verificationKey struct {
+	algorithm string
+	key       any
+}
+
+// tokenVerifier verifies the access tokens of the sender API. The key is picked by the kid of the token, so several
+// keys can be active while they are rotated: first from the configured keys and then from the JWKS, if any.
+// The tokens must have exp, nbf and iat, and the issuer and audience configured. The tokens verified with the
+// configured keys must have the access code too
+type tokenVerifier struct {
+	keys map[string]verificationKey
+	// jwks if it is nil, only the configured keys are used
+	jwks       *jwksKeySet
+	accessCode string
+	parser     *jwt.Parser
+}
+
+// newTokenVerifierFromEnv loads the verifier config once, so it is not read on each request:
+//
+// + SECRET and ALGORITHM: HMAC key used for the tokens without kid.
+//
+// + JWT_KEYS_PATH: JSON file with a list of keyConfig, identified by kid.
+//
+// + JWKS_URL or JWKS_PATH: JWKS document with more keys, see newJWKSKeySetFromEnv.
+//
+// + JWT_ISSUER and JWT_AUDIENCE: if they are set, the iss and aud claims must match them.
+//
+// + JWT_LEEWAY: tolerance for the time claims, by default defaultLeeway.
+//
+// + ACCESS_CODE: value of the access_code claim of the tokens verified with SECRET or JWT_KEYS_PATH
+func newTokenVerifierFromEnv() (*tokenVerifier, error) {
+	keys := make(map[string]verificationKey)
+	if secret := os.Getenv(secretEnvVar); secret != "" {
+		key, err := newVerificationKey(keyConfig{
+			Algorithm: os.Getenv(cryptographicAlgorithmEnvVar),
+			Secret:    secret,
+		}, "")
+		if err != nil {
+			return nil, err
+		}
+		keys[defaultKeyID] = key
+	}
+
+	if keysPath := os.Getenv(keysPathEnvVar); keysPath != "" {
+		fileKeys, err := loadKeysFile(keysPath)
+		if err != nil {
+			return nil, err
+		}
+		for keyID, key := range fileKeys {
+			keys[keyID] = key
+		}
+	}
+
+	keySet, err := newJWKSKeySetFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	leeway := defaultLeeway
+	if rawLeeway := os.Getenv(leewayEnvVar); rawLeeway != "" {
+		leeway, err = time.ParseDuration(rawLeeway)
+		if err != nil {
+			return nil, fmt.Errorf("error invalid %s: %v", leewayEnvVar, err)
+		}
+	}
+
+	return newTokenVerifier(
+		keys,
+		keySet,
+		os.Getenv(issuerEnvVar),
+		os.Getenv(audienceEnvVar),
+		leeway,
+		os.Getenv(accessCodeEnvVar),
+	)
+}
+
+func newTokenVerifier(
+	keys map[string]verificationKey,
+	keySet *jwksKeySet,
+	issuer string,
+	audience string,
+	leeway time.Duration,
+	accessCode string,
+) (*tokenVerifier, error) {
+	if len(keys) == 0 && keySet == nil {
+		return nil, errNoVerificationKeys
+	}
+
+	var algorithms []string
+	for _, key := range keys {
+		algorithms = append(algorithms, key.algorithm)
+	}
+	if keySet != nil {
+		algorithms = append(algorithms, jwksAlgorithms...)
+	}
+
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods(algorithms),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(leeway),
+	}
+	if issuer != "" {
+		options = append(options, jwt.WithIssuer(issuer))
+	}
+	if audience != "" {
+		options = append(options, jwt.WithAudience(audience))
+	}
+
+	return &tokenVerifier{
+		keys:       keys,
+		jwks:       keySet,
+		accessCode: accessCode,
+		parser:     jwt.NewParser(options...),
+	}, nil
+}
+
+// verifiedToken claims of a valid access token
+type verifiedToken struct {
+	claims jwt.MapClaims
+	// configuredKey the token was verified with a key of SECRET or JWT_KEYS_PATH, not with one of the JWKS
+	configuredKey bool
+}
+
+// verify returns the claims of the token if it is valid. The token can have the Bearer prefix
+func (tv *tokenVerifier) verify(tokenString string) (verifiedToken, error) {
+	tokenString = strings.TrimPrefix(tokenString, bearerPrefix)
+	if tokenString == "" {
+		return verifiedToken{}, errTokenMissing
+	}
+
+	claims := jwt.MapClaims{}
+	token, err := tv.parser.ParseWithClaims(tokenString, claims, tv.keyFunc)
+	if err != nil {
+		return verifiedToken{}, fmt.Errorf("%w: %v", errInvalidToken, err)
+	}
+
+	// The parser validates nbf and iat only if they are present
+	for _, claim := range []string{"nbf", "iat"} {
+		if _, found := claims[claim]; !found {
+			return verifiedToken{}, fmt.Errorf("%w: %s", errMissingClaim, claim)
+		}
+	}
+
+	// The access code is shared with the issuers of the configured keys, the tokens of the JWKS are issued by the
+	// auth service, which does not know it
+	keyID, _ := token.Header["kid"].(string)
+	_, isConfiguredKey := tv.keys[keyID]
+	if isConfiguredKey {
+		accessCode, _ := claims[accessCodeClaim].(string)
+		if accessCode != tv.accessCode {
+			return verifiedToken{}, errInvalidAccessCode
+		}
+	}
+
+	return verifiedToken{claims: claims, configuredKey: isConfiguredKey}, nil
+}
+
+// keyFunc returns the key with the kid of the token, its algorithm must be the one of the key. The tokens without
+// kid are only verified with the key set with SECRET
+func (tv *tokenVerifier) keyFunc(token *jwt.Token) (any, error) {
+	keyID, _ := token.Header["kid"].(string)
+	key, found := tv.keys[keyID]
+	if !found && (keyID == defaultKeyID || tv.jwks == nil) {
+		return nil, fmt.Errorf("%w: %q", errUnknownKeyID, keyID)
+	}
+
+	if !found {
+		var err error
+		key, err = tv.jwks.key(keyID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if token.Method.Alg() != key.algorithm {
+		return nil, fmt.Errorf("%w: %s", errUnexpectedAlgorithm, token.Method.Alg())
+	}
+
+	return key.key, nil
+}
+
+// loadKeysFile returns the keys of the file by kid
+func loadKeysFile(path string) (map[string]verificationKey, error) {
+	rawKeys, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading keys file: %v", err)
+	}
+
+	var keyConfigs []keyConfig
+	err = json.Unmarshal(rawKeys, &keyConfigs)
+	if err != nil {
+		return nil, fmt.Errorf("error unmarshalling keys file: %v", err)
+	}
+
+	keys := make(map[string]verificationKey, len(keyConfigs))
+	for _, config := range keyConfigs {
+		if config.KeyID == "" {
+			return nil, fmt.Errorf("%w: kid is missing", errInvalidKeyConfig)
+		}
+		if _, found := keys[config.KeyID]; found {
+			return nil, fmt.Errorf("%w: kid %s is repeated", errInvalidKeyConfig, config.KeyID)
+		}
+
+		key, err := newVerificationKey(config, filepath.Dir(path))
+		if err != nil {
+			return nil, fmt.Errorf("%w: kid %s", err, config.KeyID)
+		}
+		keys[config.KeyID] = key
+	}
+
+	return keys, nil
+}
+
+// newVerificationKey supports HMAC secrets and RSA or ECDSA public keys
+func newVerificationKey(config keyConfig, baseDir string) (verificationKey, error) {
+	var key any
+	var err error
+	switch jwt.GetSigningMethod(config.Algorithm).(type) {
+	case *jwt.SigningMethodHMAC:
+		if config.Secret == "" {
+			return verificationKey{}, fmt.Errorf("%w: secret is missing", errInvalidKeyConfig)
+		}
+		key = []byte(config.Secret)
+	case *jwt.SigningMethodRSA:
+		key, err = readPublicKey(config.PublicKeyPath, baseDir, func(pem []byte) (any, error) {
+			return jwt.ParseRSAPublicKeyFromPEM(pem)
+		})
+	case *jwt.SigningMethodECDSA:
+		key, err = readPublicKey(config.PublicKeyPath, baseDir, func(pem []byte) (any, error) {
+			return jwt.ParseECPublicKeyFromPEM(pem)
+		})
+	default:
+		return verificationKey{}, fmt.Errorf("%w: %q", errUnsupportedAlgorithm, config.Algorithm)
+	}
+
+	if err != nil {
+		return verificationKey{}, err
+	}
+
+	return verificationKey{algorithm: config.Algorithm, key: key}, nil
+}
+
+func readPublicKey(path string, baseDir string, parse func(pem []byte) (any, error)) (any, error) {
+	if path == "" {
+		return nil, fmt.Errorf("%w: public_key_path is missing", errInvalidKeyConfig)
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(baseDir, path)
+	}
+
+	rawPEM, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errInvalidKeyConfig, err)
+	}
+
+	key, err := parse(rawPEM)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errInvalidKeyConfig, err)
+	}
+
+	return key, nil
+}
@@ -0,0 +1,320 @@
+package sender
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const (
+	testSecret     = "salchicha"
+	testAccessCode = "pet-place"
+	testIssuer     = "https://auth.pet.place"
+	testAudience   = "telegramer"
+)
+
+// testKeys signing keys whose public keys are written in a keys file
+type testKeys struct {
+	rsaKey   *rsa.PrivateKey
+	ecdsaKey *ecdsa.PrivateKey
+	path     string
+}
+
+func newTestKeys(t *testing.T) testKeys {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	writePublicKey(t, filepath.Join(dir, "rsa.pem"), &rsaKey.PublicKey)
+	writePublicKey(t, filepath.Join(dir, "ecdsa.pem"), &ecdsaKey.PublicKey)
+
+	keysPath := filepath.Join(dir, "keys.json")
+	err = os.WriteFile(keysPath, []byte(`[
+		{"kid": "rsa-2024", "algorithm": "RS256", "public_key_path": "rsa.pem"},
+		{"kid": "ecdsa-2024", "algorithm": "ES256", "public_key_path": "ecdsa.pem"},
+		{"kid": "hmac-2024", "algorithm": "HS256", "secret": "rotated-salchicha"}
+	]`), 0o600)
+	require.NoError(t, err)
+
+	return testKeys{rsaKey: rsaKey, ecdsaKey: ecdsaKey, path: keysPath}
+}
+
+func writePublicKey(t *testing.T, path string, publicKey any) {
+	rawKey, err := x509.MarshalPKIXPublicKey(publicKey)
+	require.NoError(t, err)
+
+	err = os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: rawKey}), 0o600)
+	require.NoError(t, err)
+}
+
+// validClaims returns claims that pass every check, the modify function changes them for each test case
+func validClaims(modify func(claims jwt.MapClaims)) jwt.MapClaims {
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":           testIssuer,
+		"aud":           testAudience,
+		"exp":           now.Add(time.Hour).Unix(),
+		"nbf":           now.Unix(),
+		"iat":           now.Unix(),
+		accessCodeClaim: testAccessCode,
+	}
+	if modify != nil {
+		modify(claims)
+	}
+
+	return claims
+}
+
+func signToken(t *testing.T, method jwt.SigningMethod, keyID string, key any, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(method, claims)
+	if keyID != "" {
+		token.Header["kid"] = keyID
+	}
+
+	tokenString, err := token.SignedString(key)
+	require.NoError(t, err)
+	return tokenString
+}
+
+func setVerifierEnvs(t *testing.T, keysPath string) {
+	t.Setenv(secretEnvVar, testSecret)
+	t.Setenv(cryptographicAlgorithmEnvVar, "HS256")
+	t.Setenv(accessCodeEnvVar, testAccessCode)
+	t.Setenv(keysPathEnvVar, keysPath)
+	t.Setenv(issuerEnvVar, testIssuer)
+	t.Setenv(audienceEnvVar, testAudience)
+	t.Setenv(leewayEnvVar, "1m")
+}
+
+func TestTokenVerifier(t *testing.T) {
+	keys := newTestKeys(t)
+	setVerifierEnvs(t, keys.path)
+
+	verifier, err := newTokenVerifierFromEnv()
+	require.NoError(t, err)
+
+	hmacSecret := []byte(testSecret)
+	testCases := []struct {
+		Name          string
+		Token         string
+		ExpectedError error
+	}{
+		{
+			Name:  "Token signed with the default secret",
+			Token: signToken(t, jwt.SigningMethodHS256, "", hmacSecret, validClaims(nil)),
+		},
+		{
+			Name:  "Token with Bearer prefix",
+			Token: bearerPrefix + signToken(t, jwt.SigningMethodHS256, "", hmacSecret, validClaims(nil)),
+		},
+		{
+			Name:  "Token signed with a rotated secret",
+			Token: signToken(t, jwt.SigningMethodHS256, "hmac-2024", []byte("rotated-salchicha"), validClaims(nil)),
+		},
+		{
+			Name:  "Token signed with RS256",
+			Token: signToken(t, jwt.SigningMethodRS256, "rsa-2024", keys.rsaKey, validClaims(nil)),
+		},
+		{
+			Name:  "Token signed with ES256",
+			Token: signToken(t, jwt.SigningMethodES256, "ecdsa-2024", keys.ecdsaKey, validClaims(nil)),
+		},
+		{
+			Name: "Token expired within the leeway",
+			Token: signToken(t, jwt.SigningMethodHS256, "", hmacSecret, validClaims(func(claims jwt.MapClaims) {
+				claims["exp"] = time.Now().Add(-30 * time.Second).Unix()
+			})),
+		},
+		{
+			Name:          "Token is missing",
+			ExpectedError: errTokenMissing,
+		},
+		{
+			Name: "Token is expired",
+			Token: signToken(t, jwt.SigningMethodHS256, "", hmacSecret, validClaims(func(claims jwt.MapClaims) {
+				claims["exp"] = time.Now().Add(-time.Hour).Unix()
+			})),
+			ExpectedError: errInvalidToken,
+		},
+		{
+			Name: "Token without exp",
+			Token: signToken(t, jwt.SigningMethodHS256, "", hmacSecret, validClaims(func(claims jwt.MapClaims) {
+				delete(claims, "exp")
+			})),
+			ExpectedError: errInvalidToken,
+		},
+		{
+			Name: "Token without nbf",
+			Token: signToken(t, jwt.SigningMethodHS256, "", hmacSecret, validClaims(func(claims jwt.MapClaims) {
+				delete(claims, "nbf")
+			})),
+			ExpectedError: errMissingClaim,
+		},
+		{
+			Name: "Token without iat",
+			Token: signToken(t, jwt.SigningMethodHS256, "", hmacSecret, validClaims(func(claims jwt.MapClaims) {
+				delete(claims, "iat")
+			})),
+			ExpectedError: errMissingClaim,
+		},
+		{
+			Name: "Token not valid yet",
+			Token: signToken(t, jwt.SigningMethodHS256, "", hmacSecret, validClaims(func(claims jwt.MapClaims) {
+				claims["nbf"] = time.Now().Add(time.Hour).Unix()
+			})),
+			ExpectedError: errInvalidToken,
+		},
+		{
+			Name: "Token from another issuer",
+			Token: signToken(t, jwt.SigningMethodHS256, "", hmacSecret, validClaims(func(claims jwt.MapClaims) {
+				claims["iss"] = "https://evil.corp"
+			})),
+			ExpectedError: errInvalidToken,
+		},
+		{
+			Name: "Token for another audience",
+			Token: signToken(t, jwt.SigningMethodHS256, "", hmacSecret, validClaims(func(claims jwt.MapClaims) {
+				claims["aud"] = "pets-service"
+			})),
+			ExpectedError: errInvalidToken,
+		},
+		{
+			Name: "Token with invalid access code",
+			Token: signToken(t, jwt.SigningMethodHS256, "", hmacSecret, validClaims(func(claims jwt.MapClaims) {
+				claims[accessCodeClaim] = "hacker"
+			})),
+			ExpectedError: errInvalidAccessCode,
+		},
+		{
+			Name:          "Token with unknown kid",
+			Token:         signToken(t, jwt.SigningMethodHS256, "hmac-1999", hmacSecret, validClaims(nil)),
+			ExpectedError: errInvalidToken,
+		},
+		{
+			Name:          "Token signed with another algorithm than the one of its kid",
+			Token:         signToken(t, jwt.SigningMethodHS256, "rsa-2024", hmacSecret, validClaims(nil)),
+			ExpectedError: errInvalidToken,
+		},
+		{
+			Name:          "Token signed with another key",
+			Token:         signToken(t, jwt.SigningMethodHS256, "", []byte("another-secret"), validClaims(nil)),
+			ExpectedError: errInvalidToken,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			token, err := verifier.verify(testCase.Token)
+			if testCase.ExpectedError != nil {
+				assert.ErrorIs(t, err, testCase.ExpectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, testAccessCode, token.claims[accessCodeClaim])
+			assert.True(t, token.configuredKey)
+		})
+	}
+}
+
+func TestNewTokenVerifierFromEnv(t *testing.T) {
+	keys := newTestKeys(t)
+
+	testCases := []struct {
+		Name          string
+		Envs          map[string]string
+		KeysFile      string
+		ExpectedError error
+	}{
+		{
+			Name: "Only the default secret",
+			Envs: map[string]string{secretEnvVar: testSecret, cryptographicAlgorithmEnvVar: "HS256"},
+		},
+		{
+			Name: "Only the keys file",
+			Envs: map[string]string{keysPathEnvVar: keys.path},
+		},
+		{
+			Name: "Only the JWKS",
+			Envs: map[string]string{jwksURLEnvVar: "http://localhost:0/.well-known/jwks.json"},
+		},
+		{
+			Name:          "Without keys",
+			ExpectedError: errNoVerificationKeys,
+		},
+		{
+			Name: "JWKS URL and path",
+			Envs: map[string]string{
+				jwksURLEnvVar:  "http://localhost:0/.well-known/jwks.json",
+				jwksPathEnvVar: "jwks.json",
+			},
+			ExpectedError: errInvalidJWKSConfig,
+		},
+		{
+			Name:          "JWKS file does not exist",
+			Envs:          map[string]string{jwksPathEnvVar: "missing-jwks.json"},
+			ExpectedError: errFetchingJWKS,
+		},
+		{
+			Name:          "Default secret with an unsupported algorithm",
+			Envs:          map[string]string{secretEnvVar: testSecret, cryptographicAlgorithmEnvVar: "none"},
+			ExpectedError: errUnsupportedAlgorithm,
+		},
+		{
+			Name:          "Key without kid",
+			KeysFile:      `[{"algorithm": "HS256", "secret": "salchicha"}]`,
+			ExpectedError: errInvalidKeyConfig,
+		},
+		{
+			Name:          "Repeated kid",
+			KeysFile:      `[{"kid": "a", "algorithm": "HS256", "secret": "a"}, {"kid": "a", "algorithm": "HS512", "secret": "b"}]`,
+			ExpectedError: errInvalidKeyConfig,
+		},
+		{
+			Name:          "Public key file does not exist",
+			KeysFile:      `[{"kid": "rsa", "algorithm": "RS256", "public_key_path": "missing.pem"}]`,
+			ExpectedError: errInvalidKeyConfig,
+		},
+		{
+			Name:          "Public key of another type",
+			KeysFile:      `[{"kid": "rsa", "algorithm": "RS256", "public_key_path": "` + filepath.Join(filepath.Dir(keys.path), "ecdsa.pem") + `"}]`,
+			ExpectedError: errInvalidKeyConfig,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			for _, envVar := range []string{secretEnvVar, cryptographicAlgorithmEnvVar, keysPathEnvVar, leewayEnvVar, jwksURLEnvVar, jwksPathEnvVar} {
+				t.Setenv(envVar, "")
+			}
+			for envVar, value := range testCase.Envs {
+				t.Setenv(envVar, value)
+			}
+			if testCase.KeysFile != "" {
+				keysPath := filepath.Join(t.TempDir(), "keys.json")
+				require.NoError(t, os.WriteFile(keysPath, []byte(testCase.KeysFile), 0o600))
+				t.Setenv(keysPathEnvVar, keysPath)
+			}
+
+			_, err := newTokenVerifierFromEnv()
+			if testCase.ExpectedError != nil {
+				assert.ErrorIs(t, err, testCase.ExpectedError)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
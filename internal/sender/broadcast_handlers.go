@@ -0,0 +1,97 @@
+package sender
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"telegram-bot/internal/sender/internal/broadcast"
+)
+
+const broadcastIDParam = "broadcastID"
+
+// CreateBroadcast queues a broadcast, its progress can be followed with GetBroadcast
+func (ns *NotificationsSender) CreateBroadcast(c *gin.Context) {
+	var request broadcast.Request
+	err := c.ShouldBindJSON(&request)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse{
+			StatusCode: http.StatusBadRequest,
+			Message:    fmt.Sprintf("error unmarshaling body: %v", err.Error()),
+		})
+		return
+	}
+
+	err = request.Validate()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse{
+			StatusCode: http.StatusBadRequest,
+			Message:    err.Error(),
+		})
+		return
+	}
+
+	progress, err := ns.broadcaster.enqueue(request)
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, errorResponse{
+			StatusCode: http.StatusServiceUnavailable,
+			Message:    err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, progress)
+}
+
+// BroadcastToAll queues a broadcast of the message to all the users and returns its ID, it is used by the
+// admin commands of the bot
+func (ns *NotificationsSender) BroadcastToAll(message string) (string, error) {
+	request := broadcast.Request{
+		Message:  message,
+		Audience: broadcast.Audience{Type: broadcast.AudienceAll},
+	}
+	err := request.Validate()
+	if err != nil {
+		return "", err
+	}
+
+	progress, err := ns.broadcaster.enqueue(request)
+	if err != nil {
+		return "", err
+	}
+
+	return progress.ID, nil
+}
+
+// GetBroadcast responds the progress of the broadcast
+func (ns *NotificationsSender) GetBroadcast(c *gin.Context) {
+	progress, err := ns.broadcaster.progress(c.Param(broadcastIDParam))
+	if err != nil {
+		c.JSON(http.StatusNotFound, errorResponse{
+			StatusCode: http.StatusNotFound,
+			Message:    err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, progress)
+}
+
+// CancelBroadcast stops the broadcast. If it is being delivered, it is cancelled after the current message
+func (ns *NotificationsSender) CancelBroadcast(c *gin.Context) {
+	progress, err := ns.broadcaster.cancel(c.Param(broadcastIDParam))
+	if err != nil {
+		statusCode := http.StatusNotFound
+		if errors.Is(err, errBroadcastFinished) {
+			statusCode = http.StatusConflict
+		}
+
+		c.JSON(statusCode, errorResponse{
+			StatusCode: statusCode,
+			Message:    err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, progress)
+}
@@ -0,0 +1,373 @@
+package sender
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"sort"
+	"strings"
+	"sync"
+	"telegram-bot/internal/bot"
+	"telegram-bot/internal/requester"
+	"telegram-bot/internal/sender/internal/broadcast"
+	"time"
+)
+
+const (
+	broadcastRateEnvVar = "BROADCAST_RATE"
+	// defaultBroadcastRate messages per second, Telegram allows about 30 to different users
+	defaultBroadcastRate = 25
+	broadcastQueueSize   = 100
+	// broadcastRetention and maxFinishedBroadcasts after it or beyond them, the finished broadcasts are forgotten
+	broadcastRetention    = 24 * time.Hour
+	maxFinishedBroadcasts = 1000
+)
+
+type broadcastStatus string
+
+const (
+	broadcastQueued    broadcastStatus = "queued"
+	broadcastRunning   broadcastStatus = "running"
+	broadcastCompleted broadcastStatus = "completed"
+	// broadcastCancelled it was cancelled or interrupted because the sender was shutting down
+	broadcastCancelled broadcastStatus = "cancelled"
+)
+
+var (
+	errBroadcastQueueFull = errors.New("error broadcast queue is full")
+	errBroadcastNotFound  = errors.New("error broadcast not found")
+	errBroadcastFinished  = errors.New("error broadcast already finished")
+)
+
+// broadcastProgress state of a broadcast. Total is the amount of known users when the delivery started, each of them
+// is processed: the ones out of the audience are skipped, the ones whose chat is suppressed, see unreachableChats,
+// are not sent and the rest are sent or failed
+type broadcastProgress struct {
+	ID         string             `json:"id"`
+	Status     broadcastStatus    `json:"status"`
+	Audience   broadcast.Audience `json:"audience"`
+	Total      int                `json:"total"`
+	Processed  int                `json:"processed"`
+	Sent       int                `json:"sent"`
+	Skipped    int                `json:"skipped"`
+	Suppressed int                `json:"suppressed"`
+	Failed     int                `json:"failed"`
+	CreatedAt  time.Time          `json:"created_at"`
+	StartedAt  *time.Time         `json:"started_at,omitempty"`
+	FinishedAt *time.Time         `json:"finished_at,omitempty"`
+}
+
+type queuedBroadcast struct {
+	progress     broadcastProgress
+	announcement bot.Announcement
+	ctx          context.Context
+	cancel       context.CancelFunc
+}
+
+// broadcaster delivers the broadcasts one at a time, in the order in which they were received. The messages are
+// throttled to interval, so Telegram does not rate limit the bot. The progress of the finished broadcasts is kept
+// for broadcastRetention, at most for the last maxFinishedBroadcasts
+type broadcaster struct {
+	announcer   announcer
+	requester   AudienceRequester
+	unreachable *unreachableChats
+	interval    time.Duration
+	queue       chan *queuedBroadcast
+	now         func() time.Time
+
+	mu         sync.Mutex
+	broadcasts map[string]*queuedBroadcast
+}
+
+// newBroadcaster the broadcasts are not sent to the chats suppressed by unreachable, and the chats that cannot be
+// reached are recorded in it, as the notifications
+func newBroadcaster(
+	announcer announcer,
+	audienceRequester AudienceRequester,
+	unreachable *unreachableChats,
+	rate int,
+) *broadcaster {
+	return &broadcaster{
+		announcer:   announcer,
+		requester:   audienceRequester,
+		unreachable: unreachable,
+		interval:    time.Second / time.Duration(rate),
+		queue:       make(chan *queuedBroadcast, broadcastQueueSize),
+		now:         time.Now,
+		broadcasts:  make(map[string]*queuedBroadcast),
+	}
+}
+
+// enqueue queues the broadcast, it must be valid
+func (b *broadcaster) enqueue(request broadcast.Request) (broadcastProgress, error) {
+	buttons := make([]bot.AnnouncementButton, 0, len(request.Buttons))
+	for _, button := range request.Buttons {
+		buttons = append(buttons, bot.AnnouncementButton{Text: button.Text, URL: button.URL})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	queued := &queuedBroadcast{
+		progress: broadcastProgress{
+			ID:        uuid.NewString(),
+			Status:    broadcastQueued,
+			Audience:  request.Audience,
+			CreatedAt: b.now(),
+		},
+		announcement: bot.Announcement{
+			Text:     request.Message,
+			ImageURL: request.ImageURL,
+			Buttons:  buttons,
+		},
+		ctx:    ctx,
+		cancel: cancel,
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	select {
+	case b.queue <- queued:
+	default:
+		cancel()
+		return broadcastProgress{}, errBroadcastQueueFull
+	}
+
+	b.broadcasts[queued.progress.ID] = queued
+	return queued.progress, nil
+}
+
+// progress returns a copy of the progress of the broadcast
+func (b *broadcaster) progress(broadcastID string) (broadcastProgress, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.pruneLocked()
+	queued, found := b.broadcasts[broadcastID]
+	if !found {
+		return broadcastProgress{}, errBroadcastNotFound
+	}
+
+	return queued.progress, nil
+}
+
+// cancel stops the broadcast, the messages that were already sent cannot be undone
+func (b *broadcaster) cancel(broadcastID string) (broadcastProgress, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.pruneLocked()
+	queued, found := b.broadcasts[broadcastID]
+	if !found {
+		return broadcastProgress{}, errBroadcastNotFound
+	}
+
+	switch queued.progress.Status {
+	case broadcastCompleted, broadcastCancelled:
+		return queued.progress, errBroadcastFinished
+	case broadcastQueued:
+		b.finishLocked(queued, broadcastCancelled)
+	}
+	queued.cancel()
+
+	return queued.progress, nil
+}
+
+// run delivers the queued broadcasts until the context is done, the one that is being delivered is interrupted
+func (b *broadcaster) run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case queued := <-b.queue:
+			stopInterruption := context.AfterFunc(ctx, queued.cancel)
+			b.deliver(queued)
+			stopInterruption()
+		}
+	}
+}
+
+// deliver sends the broadcast to each known user in its audience
+func (b *broadcaster) deliver(queued *queuedBroadcast) {
+	b.mu.Lock()
+	if queued.progress.Status != broadcastQueued {
+		b.mu.Unlock()
+		return
+	}
+
+	telegramIDs := b.announcer.KnownUsers()
+	startedAt := b.now()
+	queued.progress.Status = broadcastRunning
+	queued.progress.StartedAt = &startedAt
+	queued.progress.Total = len(telegramIDs)
+	b.mu.Unlock()
+
+	logger := logrus.WithField("broadcast_id", queued.progress.ID)
+	logger.Infof("delivering broadcast to %d known users", len(telegramIDs))
+
+	throttle := time.NewTicker(b.interval)
+	defer throttle.Stop()
+
+	for _, telegramID := range telegramIDs {
+		if queued.ctx.Err() != nil {
+			break
+		}
+
+		outcome := b.deliverTo(queued, telegramID, throttle.C)
+		if outcome == "" {
+			break
+		}
+		broadcastMessages.WithLabelValues(outcome).Inc()
+
+		b.mu.Lock()
+		queued.progress.Processed++
+		switch outcome {
+		case outcomeSent:
+			queued.progress.Sent++
+		case outcomeSkipped:
+			queued.progress.Skipped++
+		case outcomeSuppressed:
+			queued.progress.Suppressed++
+		default:
+			queued.progress.Failed++
+		}
+		b.mu.Unlock()
+	}
+
+	status := broadcastCompleted
+	if queued.ctx.Err() != nil {
+		status = broadcastCancelled
+	}
+
+	b.mu.Lock()
+	b.finishLocked(queued, status)
+	progress := queued.progress
+	b.mu.Unlock()
+	queued.cancel()
+
+	logger.Infof(
+		"broadcast %s: %d sent, %d skipped, %d suppressed, %d failed",
+		status,
+		progress.Sent,
+		progress.Skipped,
+		progress.Suppressed,
+		progress.Failed,
+	)
+}
+
+// deliverTo sends the broadcast to the user if it is in the audience and its chat is not suppressed, and returns
+// the outcome. If the broadcast is cancelled while waiting for the throttle, the outcome is empty
+func (b *broadcaster) deliverTo(queued *queuedBroadcast, telegramID int64, throttle <-chan time.Time) string {
+	inAudience, err := b.inAudience(queued.ctx, queued.progress.Audience, telegramID)
+	if err != nil {
+		if queued.ctx.Err() != nil {
+			return ""
+		}
+		logrus.Errorf("error checking if %d is in the audience of broadcast %s: %v", telegramID, queued.progress.ID, err)
+		return outcomeAudienceError
+	}
+
+	if !inAudience {
+		return outcomeSkipped
+	}
+
+	if _, found := b.unreachable.suppressed(telegramID); found {
+		return outcomeSuppressed
+	}
+
+	select {
+	case <-queued.ctx.Done():
+		return ""
+	case <-throttle:
+	}
+
+	err = b.announcer.SendAnnouncement(queued.ctx, telegramID, queued.announcement)
+	if err != nil {
+		logrus.Errorf("error sending broadcast %s to %d: %v", queued.progress.ID, telegramID, err)
+		if reason := bot.UnreachableReason(err); reason != "" {
+			b.unreachable.record(telegramID, reason)
+			return outcomeUnreachable
+		}
+		return outcomeSendError
+	}
+
+	b.unreachable.remove(telegramID)
+	return outcomeSent
+}
+
+// inAudience returns true if the user is in the audience. The users that are not registered are only in the
+// audience of all the users
+func (b *broadcaster) inAudience(ctx context.Context, audience broadcast.Audience, telegramID int64) (bool, error) {
+	switch audience.Type {
+	case broadcast.AudiencePetType:
+		pets, err := b.requester.GetPetsByOwnerID(ctx, telegramID)
+		if isNotFound(err) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+
+		for _, pet := range pets {
+			if strings.EqualFold(strings.TrimSpace(pet.Type), strings.TrimSpace(audience.PetType)) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case broadcast.AudienceCity:
+		userInfo, err := b.requester.GetUserData(ctx, telegramID)
+		if isNotFound(err) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+
+		return strings.EqualFold(strings.TrimSpace(userInfo.City), strings.TrimSpace(audience.City)), nil
+	default:
+		return true, nil
+	}
+}
+
+// finishLocked must be called with mu locked
+func (b *broadcaster) finishLocked(queued *queuedBroadcast, status broadcastStatus) {
+	finishedAt := b.now()
+	queued.progress.Status = status
+	queued.progress.FinishedAt = &finishedAt
+	b.pruneLocked()
+}
+
+// pruneLocked forgets the finished broadcasts older than broadcastRetention and the oldest ones beyond
+// maxFinishedBroadcasts. It is called when a broadcast finishes and when they are read, so the expired ones are
+// not returned even if no broadcast finished since then. Must be called with mu locked
+func (b *broadcaster) pruneLocked() {
+	expiredAt := b.now().Add(-broadcastRetention)
+	var finished []*queuedBroadcast
+	for broadcastID, queued := range b.broadcasts {
+		if queued.progress.FinishedAt == nil {
+			continue
+		}
+
+		if queued.progress.FinishedAt.Before(expiredAt) {
+			delete(b.broadcasts, broadcastID)
+			continue
+		}
+		finished = append(finished, queued)
+	}
+
+	if len(finished) <= maxFinishedBroadcasts {
+		return
+	}
+
+	sort.Slice(finished, func(i, j int) bool {
+		return finished[i].progress.FinishedAt.Before(*finished[j].progress.FinishedAt)
+	})
+	for _, queued := range finished[:len(finished)-maxFinishedBroadcasts] {
+		delete(b.broadcasts, queued.progress.ID)
+	}
+}
+
+func isNotFound(err error) bool {
+	var requestError requester.RequestError
+	return errors.As(err, &requestError) && requestError.IsNotFound()
+}
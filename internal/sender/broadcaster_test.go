@@ -0,0 +1,334 @@
+package sender
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tele "gopkg.in/telebot.v3"
+	"net/http"
+	"telegram-bot/internal/bot"
+	"telegram-bot/internal/domain"
+	"telegram-bot/internal/requester"
+	"telegram-bot/internal/sender/internal/broadcast"
+	"telegram-bot/internal/sender/internal/mock"
+	"testing"
+	"time"
+)
+
+const testBroadcastRate = 1000
+
+var errNotFound = requester.NewRequestError(errors.New("not found"), http.StatusNotFound, "")
+
+// runBroadcaster runs the broadcaster until the test finishes
+func runBroadcaster(t *testing.T, b *broadcaster) {
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		b.run(ctx)
+		close(stopped)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-stopped
+	})
+}
+
+func newTestUnreachableChats(t *testing.T) *unreachableChats {
+	chats, err := newUnreachableChats("", time.Hour, func(unreachableChat) {})
+	require.NoError(t, err)
+	return chats
+}
+
+func waitForStatus(t *testing.T, b *broadcaster, broadcastID string, status broadcastStatus) broadcastProgress {
+	var progress broadcastProgress
+	require.Eventually(t, func() bool {
+		var err error
+		progress, err = b.progress(broadcastID)
+		require.NoError(t, err)
+		return progress.Status == status
+	}, 2*time.Second, 5*time.Millisecond)
+
+	return progress
+}
+
+func TestBroadcasterDeliversToTheAudience(t *testing.T) {
+	testCases := []struct {
+		Name             string
+		Audience         broadcast.Audience
+		SetRequester     func(requesterMock *mock.MockAudienceRequester)
+		ExpectedSent     []int64
+		ExpectedProgress broadcastProgress
+	}{
+		{
+			Name:             "All the users",
+			Audience:         broadcast.Audience{Type: broadcast.AudienceAll},
+			SetRequester:     func(*mock.MockAudienceRequester) {},
+			ExpectedSent:     []int64{69, 420, 911},
+			ExpectedProgress: broadcastProgress{Total: 3, Processed: 3, Sent: 3},
+		},
+		{
+			Name:     "Users with dogs",
+			Audience: broadcast.Audience{Type: broadcast.AudiencePetType, PetType: "Dog"},
+			SetRequester: func(requesterMock *mock.MockAudienceRequester) {
+				requesterMock.EXPECT().GetPetsByOwnerID(gomock.Any(), int64(69)).Return(nil, errNotFound)
+				requesterMock.EXPECT().GetPetsByOwnerID(gomock.Any(), int64(420)).Return([]domain.PetData{
+					{PetDataIdentifier: domain.PetDataIdentifier{Name: "Michi", Type: "cat"}},
+				}, nil)
+				requesterMock.EXPECT().GetPetsByOwnerID(gomock.Any(), int64(911)).Return([]domain.PetData{
+					{PetDataIdentifier: domain.PetDataIdentifier{Name: "Michi", Type: "cat"}},
+					{PetDataIdentifier: domain.PetDataIdentifier{Name: "Bachicha", Type: "dog"}},
+				}, nil)
+			},
+			ExpectedSent:     []int64{911},
+			ExpectedProgress: broadcastProgress{Total: 3, Processed: 3, Sent: 1, Skipped: 2},
+		},
+		{
+			Name:     "Users in a city",
+			Audience: broadcast.Audience{Type: broadcast.AudienceCity, City: "buenos aires"},
+			SetRequester: func(requesterMock *mock.MockAudienceRequester) {
+				requesterMock.EXPECT().GetUserData(gomock.Any(), int64(69)).Return(domain.UserInfo{City: "Buenos Aires"}, nil)
+				requesterMock.EXPECT().GetUserData(gomock.Any(), int64(420)).Return(domain.UserInfo{City: "Rosario"}, nil)
+				requesterMock.EXPECT().GetUserData(gomock.Any(), int64(911)).Return(domain.UserInfo{}, errors.New("pum"))
+			},
+			ExpectedSent:     []int64{69},
+			ExpectedProgress: broadcastProgress{Total: 3, Processed: 3, Sent: 1, Skipped: 1, Failed: 1},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			announcerMock := mock.NewMockannouncer(ctrl)
+			requesterMock := mock.NewMockAudienceRequester(ctrl)
+
+			announcerMock.EXPECT().KnownUsers().Return([]int64{69, 420, 911})
+			testCase.SetRequester(requesterMock)
+			announcement := bot.Announcement{
+				Text:     "*Pet Place* is turning 1!",
+				ImageURL: "https://pet.place/cake.jpg",
+				Buttons:  []bot.AnnouncementButton{{Text: "Celebrate", URL: "https://pet.place/birthday"}},
+			}
+			for _, telegramID := range testCase.ExpectedSent {
+				announcerMock.EXPECT().SendAnnouncement(gomock.Any(), telegramID, announcement).Return(nil)
+			}
+
+			b := newBroadcaster(announcerMock, requesterMock, newTestUnreachableChats(t), testBroadcastRate)
+			runBroadcaster(t, b)
+
+			queued, err := b.enqueue(broadcast.Request{
+				Message:  announcement.Text,
+				ImageURL: announcement.ImageURL,
+				Buttons:  []broadcast.Button{{Text: "Celebrate", URL: "https://pet.place/birthday"}},
+				Audience: testCase.Audience,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, broadcastQueued, queued.Status)
+
+			progress := waitForStatus(t, b, queued.ID, broadcastCompleted)
+			assert.Equal(t, testCase.ExpectedProgress.Total, progress.Total)
+			assert.Equal(t, testCase.ExpectedProgress.Processed, progress.Processed)
+			assert.Equal(t, testCase.ExpectedProgress.Sent, progress.Sent)
+			assert.Equal(t, testCase.ExpectedProgress.Skipped, progress.Skipped)
+			assert.Equal(t, testCase.ExpectedProgress.Failed, progress.Failed)
+			assert.NotNil(t, progress.StartedAt)
+			assert.NotNil(t, progress.FinishedAt)
+		})
+	}
+}
+
+func TestBroadcasterUnreachableChats(t *testing.T) {
+	now := time.Now()
+	chats := newTestUnreachableChats(t)
+	chats.now = func() time.Time { return now }
+	// 69 blocked the bot recently and 911 blocked it before the cooldown
+	chats.record(911, bot.ReasonBlocked)
+	now = now.Add(2 * time.Hour)
+	chats.record(69, bot.ReasonBlocked)
+
+	announcerMock := mock.NewMockannouncer(gomock.NewController(t))
+	announcerMock.EXPECT().KnownUsers().Return([]int64{69, 420, 911})
+	announcerMock.EXPECT().SendAnnouncement(gomock.Any(), int64(420), gomock.Any()).Return(tele.ErrBlockedByUser)
+	announcerMock.EXPECT().SendAnnouncement(gomock.Any(), int64(911), gomock.Any()).Return(nil)
+
+	b := newBroadcaster(announcerMock, nil, chats, testBroadcastRate)
+	runBroadcaster(t, b)
+
+	queued, err := b.enqueue(broadcast.Request{Message: "Hi", Audience: broadcast.Audience{Type: broadcast.AudienceAll}})
+	require.NoError(t, err)
+
+	progress := waitForStatus(t, b, queued.ID, broadcastCompleted)
+	assert.Equal(t, 3, progress.Processed)
+	assert.Equal(t, 1, progress.Sent)
+	assert.Equal(t, 1, progress.Suppressed)
+	assert.Equal(t, 1, progress.Failed)
+
+	var unreachableIDs []int64
+	for _, chat := range chats.list() {
+		unreachableIDs = append(unreachableIDs, chat.TelegramID)
+	}
+	assert.Equal(t, []int64{69, 420}, unreachableIDs)
+}
+
+func TestBroadcasterIsThrottled(t *testing.T) {
+	announcerMock := mock.NewMockannouncer(gomock.NewController(t))
+	announcerMock.EXPECT().KnownUsers().Return([]int64{1, 2, 3, 4})
+	announcerMock.EXPECT().SendAnnouncement(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(4)
+
+	b := newBroadcaster(announcerMock, nil, newTestUnreachableChats(t), 20)
+	runBroadcaster(t, b)
+
+	start := time.Now()
+	queued, err := b.enqueue(broadcast.Request{Message: "Hi", Audience: broadcast.Audience{Type: broadcast.AudienceAll}})
+	require.NoError(t, err)
+	waitForStatus(t, b, queued.ID, broadcastCompleted)
+
+	assert.GreaterOrEqual(t, time.Since(start), 4*b.interval)
+}
+
+func TestBroadcasterCancel(t *testing.T) {
+	request := broadcast.Request{Message: "Hi", Audience: broadcast.Audience{Type: broadcast.AudienceAll}}
+
+	t.Run("Queued broadcast is not delivered", func(t *testing.T) {
+		b := newBroadcaster(mock.NewMockannouncer(gomock.NewController(t)), nil, newTestUnreachableChats(t), testBroadcastRate)
+		queued, err := b.enqueue(request)
+		require.NoError(t, err)
+
+		progress, err := b.cancel(queued.ID)
+		require.NoError(t, err)
+		assert.Equal(t, broadcastCancelled, progress.Status)
+
+		runBroadcaster(t, b)
+		require.Eventually(t, func() bool {
+			return len(b.queue) == 0
+		}, time.Second, 5*time.Millisecond)
+
+		_, err = b.cancel(queued.ID)
+		assert.ErrorIs(t, err, errBroadcastFinished)
+	})
+
+	t.Run("Running broadcast stops after the current message", func(t *testing.T) {
+		announcerMock := mock.NewMockannouncer(gomock.NewController(t))
+		b := newBroadcaster(announcerMock, nil, newTestUnreachableChats(t), testBroadcastRate)
+
+		var broadcastID string
+		announcerMock.EXPECT().KnownUsers().Return([]int64{1, 2, 3})
+		announcerMock.EXPECT().
+			SendAnnouncement(gomock.Any(), int64(1), gomock.Any()).
+			DoAndReturn(func(context.Context, int64, bot.Announcement) error {
+				_, err := b.cancel(broadcastID)
+				assert.NoError(t, err)
+				return nil
+			})
+
+		queued, err := b.enqueue(request)
+		require.NoError(t, err)
+		broadcastID = queued.ID
+		runBroadcaster(t, b)
+
+		progress := waitForStatus(t, b, queued.ID, broadcastCancelled)
+		assert.Equal(t, 1, progress.Sent)
+		assert.Equal(t, 1, progress.Processed)
+	})
+
+	t.Run("Unknown broadcast", func(t *testing.T) {
+		b := newBroadcaster(nil, nil, newTestUnreachableChats(t), testBroadcastRate)
+		_, err := b.cancel("salchicha")
+		assert.ErrorIs(t, err, errBroadcastNotFound)
+	})
+}
+
+func TestBroadcasterForgetsFinishedBroadcasts(t *testing.T) {
+	now := time.Date(2024, time.June, 9, 12, 0, 0, 0, time.UTC)
+	request := broadcast.Request{Message: "Hi", Audience: broadcast.Audience{Type: broadcast.AudienceAll}}
+
+	t.Run("Finished broadcasts are forgotten after the retention", func(t *testing.T) {
+		b := newBroadcaster(nil, nil, newTestUnreachableChats(t), testBroadcastRate)
+		b.now = func() time.Time { return now }
+
+		expired, err := b.enqueue(request)
+		require.NoError(t, err)
+		_, err = b.cancel(expired.ID)
+		require.NoError(t, err)
+		queued, err := b.enqueue(request)
+		require.NoError(t, err)
+
+		b.now = func() time.Time { return now.Add(broadcastRetention + time.Second) }
+		finished, err := b.enqueue(request)
+		require.NoError(t, err)
+		_, err = b.cancel(finished.ID)
+		require.NoError(t, err)
+
+		_, err = b.progress(expired.ID)
+		assert.ErrorIs(t, err, errBroadcastNotFound)
+		_, err = b.progress(queued.ID)
+		assert.NoError(t, err, "the broadcasts that did not finish are kept")
+		_, err = b.progress(finished.ID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Expired broadcasts are forgotten when they are read", func(t *testing.T) {
+		b := newBroadcaster(nil, nil, newTestUnreachableChats(t), testBroadcastRate)
+		b.now = func() time.Time { return now }
+
+		expired, err := b.enqueue(request)
+		require.NoError(t, err)
+		_, err = b.cancel(expired.ID)
+		require.NoError(t, err)
+
+		b.now = func() time.Time { return now.Add(broadcastRetention + time.Second) }
+		_, err = b.progress(expired.ID)
+		assert.ErrorIs(t, err, errBroadcastNotFound)
+		_, err = b.cancel(expired.ID)
+		assert.ErrorIs(t, err, errBroadcastNotFound)
+		assert.Empty(t, b.broadcasts)
+	})
+
+	t.Run("Only the last finished broadcasts are kept", func(t *testing.T) {
+		b := newBroadcaster(nil, nil, newTestUnreachableChats(t), testBroadcastRate)
+		b.now = func() time.Time { return now }
+
+		b.mu.Lock()
+		for i := range maxFinishedBroadcasts + 1 {
+			queued := &queuedBroadcast{progress: broadcastProgress{ID: fmt.Sprint(i)}}
+			b.broadcasts[queued.progress.ID] = queued
+			b.now = func() time.Time { return now.Add(time.Duration(i) * time.Second) }
+			b.finishLocked(queued, broadcastCompleted)
+		}
+		b.mu.Unlock()
+
+		_, err := b.progress("0")
+		assert.ErrorIs(t, err, errBroadcastNotFound)
+		_, err = b.progress(fmt.Sprint(maxFinishedBroadcasts))
+		assert.NoError(t, err)
+		assert.Len(t, b.broadcasts, maxFinishedBroadcasts)
+	})
+}
+
+func TestBroadcasterQueueIsFull(t *testing.T) {
+	b := newBroadcaster(nil, nil, newTestUnreachableChats(t), testBroadcastRate)
+	request := broadcast.Request{Message: "Hi", Audience: broadcast.Audience{Type: broadcast.AudienceAll}}
+	for range broadcastQueueSize {
+		_, err := b.enqueue(request)
+		require.NoError(t, err)
+	}
+
+	_, err := b.enqueue(request)
+	assert.ErrorIs(t, err, errBroadcastQueueFull)
+}
+
+func TestBroadcastToAll(t *testing.T) {
+	ns := &NotificationsSender{broadcaster: newBroadcaster(nil, nil, newTestUnreachableChats(t), testBroadcastRate)}
+
+	broadcastID, err := ns.BroadcastToAll("*Pet Place* is turning 1!")
+	require.NoError(t, err)
+
+	progress, err := ns.broadcaster.progress(broadcastID)
+	require.NoError(t, err)
+	assert.Equal(t, broadcast.AudienceAll, progress.Audience.Type)
+
+	_, err = ns.BroadcastToAll("")
+	assert.Error(t, err)
+}
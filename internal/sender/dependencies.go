@@ -0,0 +1,19 @@
+package sender
+
+import (
+	"context"
+	"telegram-bot/internal/bot"
+	"telegram-bot/internal/domain"
+)
+
+// announcer sends the broadcasts to the users known by the bot
+type announcer interface {
+	KnownUsers() []int64
+	SendAnnouncement(ctx context.Context, telegramID int64, announcement bot.Announcement) error
+}
+
+// AudienceRequester fetches the data used to pick the audience of a broadcast
+type AudienceRequester interface {
+	GetUserData(ctx context.Context, telegramID int64) (domain.UserInfo, error)
+	GetPetsByOwnerID(ctx context.Context, ownerID int64) ([]domain.PetData, error)
+}
@@ -0,0 +1,111 @@
+package broadcast
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"telegram-bot/internal/utils/formatter"
+	"telegram-bot/internal/utils/urlutils"
+	"unicode/utf8"
+)
+
+// Audiences of a broadcast
+const (
+	// AudienceAll every user known by the bot
+	AudienceAll = "all"
+	// AudiencePetType users with at least one pet of the given type
+	AudiencePetType = "pet_type"
+	// AudienceCity users that live in the given city
+	AudienceCity = "city"
+)
+
+const (
+	// maxMessageLength max length of a Telegram message, and maxCaptionLength of the caption of a photo
+	maxMessageLength = 4096
+	maxCaptionLength = 1024
+	maxButtons       = 10
+)
+
+var errInvalidBroadcast = errors.New("error invalid broadcast")
+
+// Request message sent to an audience. The message is Markdown, it can have an image and URL buttons
+type Request struct {
+	Message  string   `json:"message"`
+	ImageURL string   `json:"image_url,omitempty"`
+	Buttons  []Button `json:"buttons,omitempty"`
+	Audience Audience `json:"audience"`
+}
+
+type Button struct {
+	Text string `json:"text"`
+	URL  string `json:"url"`
+}
+
+// Audience users that receive the broadcast, PetType and City are only used by their audience type.
+// Both are compared ignoring the case
+type Audience struct {
+	Type    string `json:"type"`
+	PetType string `json:"pet_type,omitempty"`
+	City    string `json:"city,omitempty"`
+}
+
+// Validate returns an error with all the problems of the request
+func (r Request) Validate() error {
+	var problems []string
+	maxLength := maxMessageLength
+	if r.ImageURL != "" {
+		maxLength = maxCaptionLength
+		if !urlutils.IsHTTPURL(r.ImageURL) {
+			problems = append(problems, "image_url must be an http or https URL")
+		}
+	}
+
+	switch length := utf8.RuneCountInString(r.Message); {
+	case strings.TrimSpace(r.Message) == "":
+		problems = append(problems, "message is required")
+	case length > maxLength:
+		problems = append(problems, fmt.Sprintf("message has %d characters, the max is %d", length, maxLength))
+	}
+
+	// Otherwise the message would fail for each user while it is delivered
+	if err := formatter.ValidateMarkdown(r.Message); err != nil {
+		problems = append(problems, fmt.Sprintf("message: %v", err))
+	}
+
+	if len(r.Buttons) > maxButtons {
+		problems = append(problems, fmt.Sprintf("there are %d buttons, the max is %d", len(r.Buttons), maxButtons))
+	}
+	for idx, button := range r.Buttons {
+		if strings.TrimSpace(button.Text) == "" {
+			problems = append(problems, fmt.Sprintf("buttons[%d].text is required", idx))
+		}
+		if !urlutils.IsHTTPURL(button.URL) {
+			problems = append(problems, fmt.Sprintf("buttons[%d].url must be an http or https URL", idx))
+		}
+	}
+
+	switch r.Audience.Type {
+	case AudienceAll:
+	case AudiencePetType:
+		if strings.TrimSpace(r.Audience.PetType) == "" {
+			problems = append(problems, "audience.pet_type is required")
+		}
+	case AudienceCity:
+		if strings.TrimSpace(r.Audience.City) == "" {
+			problems = append(problems, "audience.city is required")
+		}
+	default:
+		problems = append(problems, fmt.Sprintf(
+			"audience.type must be %s, %s or %s",
+			AudienceAll,
+			AudiencePetType,
+			AudienceCity,
+		))
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", errInvalidBroadcast, strings.Join(problems, ", "))
+	}
+
+	return nil
+}
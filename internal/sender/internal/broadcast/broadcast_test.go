@@ -0,0 +1,79 @@
+package broadcast
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestRequestValidate(t *testing.T) {
+	testCases := []struct {
+		Name             string
+		Request          Request
+		ExpectedProblems []string
+	}{
+		{
+			Name: "Valid request for all the users",
+			Request: Request{
+				Message:  "*Pet Place* is turning 1!",
+				ImageURL: "https://pet.place/cake.jpg",
+				Buttons:  []Button{{Text: "Celebrate", URL: "https://pet.place/birthday"}},
+				Audience: Audience{Type: AudienceAll},
+			},
+		},
+		{
+			Name:    "Valid request for a pet type",
+			Request: Request{Message: "Rabies vaccination week", Audience: Audience{Type: AudiencePetType, PetType: "dog"}},
+		},
+		{
+			Name:    "Valid request for a city",
+			Request: Request{Message: "New vet in town", Audience: Audience{Type: AudienceCity, City: "Buenos Aires"}},
+		},
+		{
+			Name: "Invalid request",
+			Request: Request{
+				Message:  " ",
+				ImageURL: "ftp://pet.place/cake.jpg",
+				Buttons:  []Button{{URL: "pet.place"}},
+				Audience: Audience{Type: AudienceCity},
+			},
+			ExpectedProblems: []string{
+				"image_url must be an http or https URL",
+				"message is required",
+				"buttons[0].text is required",
+				"buttons[0].url must be an http or https URL",
+				"audience.city is required",
+			},
+		},
+		{
+			Name:             "Caption is too long",
+			Request:          Request{Message: strings.Repeat("a", 1025), ImageURL: "https://pet.place/cake.jpg", Audience: Audience{Type: AudienceAll}},
+			ExpectedProblems: []string{"message has 1025 characters, the max is 1024"},
+		},
+		{
+			Name:             "Unknown audience",
+			Request:          Request{Message: "Hi", Audience: Audience{Type: "vets"}},
+			ExpectedProblems: []string{"audience.type must be all, pet_type or city"},
+		},
+		{
+			Name:             "Message is not valid Markdown",
+			Request:          Request{Message: "Vaccines for *all the pets", Audience: Audience{Type: AudienceAll}},
+			ExpectedProblems: []string{"* at character 14 is not closed"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			err := testCase.Request.Validate()
+			if len(testCase.ExpectedProblems) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.ErrorIs(t, err, errInvalidBroadcast)
+			for _, problem := range testCase.ExpectedProblems {
+				assert.Contains(t, err.Error(), problem)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: dependencies.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+	bot "telegram-bot/internal/bot"
+	domain "telegram-bot/internal/domain"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// Mockannouncer is a mock of announcer interface.
+type Mockannouncer struct {
+	ctrl     *gomock.Controller
+	recorder *MockannouncerMockRecorder
+}
+
+// MockannouncerMockRecorder is the mock recorder for Mockannouncer.
+type MockannouncerMockRecorder struct {
+	mock *Mockannouncer
+}
+
+// NewMockannouncer creates a new mock instance.
+func NewMockannouncer(ctrl *gomock.Controller) *Mockannouncer {
+	mock := &Mockannouncer{ctrl: ctrl}
+	mock.recorder = &MockannouncerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *Mockannouncer) EXPECT() *MockannouncerMockRecorder {
+	return m.recorder
+}
+
+// KnownUsers mocks base method.
+func (m *Mockannouncer) KnownUsers() []int64 {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "KnownUsers")
+	ret0, _ := ret[0].([]int64)
+	return ret0
+}
+
+// KnownUsers indicates an expected call of KnownUsers.
+func (mr *MockannouncerMockRecorder) KnownUsers() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "KnownUsers", reflect.TypeOf((*Mockannouncer)(nil).KnownUsers))
+}
+
+// SendAnnouncement mocks base method.
+func (m *Mockannouncer) SendAnnouncement(ctx context.Context, telegramID int64, announcement bot.Announcement) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendAnnouncement", ctx, telegramID, announcement)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendAnnouncement indicates an expected call of SendAnnouncement.
+func (mr *MockannouncerMockRecorder) SendAnnouncement(ctx, telegramID, announcement interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendAnnouncement", reflect.TypeOf((*Mockannouncer)(nil).SendAnnouncement), ctx, telegramID, announcement)
+}
+
+// MockAudienceRequester is a mock of AudienceRequester interface.
+type MockAudienceRequester struct {
+	ctrl     *gomock.Controller
+	recorder *MockAudienceRequesterMockRecorder
+}
+
+// MockAudienceRequesterMockRecorder is the mock recorder for MockAudienceRequester.
+type MockAudienceRequesterMockRecorder struct {
+	mock *MockAudienceRequester
+}
+
+// NewMockAudienceRequester creates a new mock instance.
+func NewMockAudienceRequester(ctrl *gomock.Controller) *MockAudienceRequester {
+	mock := &MockAudienceRequester{ctrl: ctrl}
+	mock.recorder = &MockAudienceRequesterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAudienceRequester) EXPECT() *MockAudienceRequesterMockRecorder {
+	return m.recorder
+}
+
+// GetPetsByOwnerID mocks base method.
+func (m *MockAudienceRequester) GetPetsByOwnerID(ctx context.Context, ownerID int64) ([]domain.PetData, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPetsByOwnerID", ctx, ownerID)
+	ret0, _ := ret[0].([]domain.PetData)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPetsByOwnerID indicates an expected call of GetPetsByOwnerID.
+func (mr *MockAudienceRequesterMockRecorder) GetPetsByOwnerID(ctx, ownerID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPetsByOwnerID", reflect.TypeOf((*MockAudienceRequester)(nil).GetPetsByOwnerID), ctx, ownerID)
+}
+
+// GetUserData mocks base method.
+func (m *MockAudienceRequester) GetUserData(ctx context.Context, telegramID int64) (domain.UserInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserData", ctx, telegramID)
+	ret0, _ := ret[0].(domain.UserInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserData indicates an expected call of GetUserData.
+func (mr *MockAudienceRequesterMockRecorder) GetUserData(ctx, telegramID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserData", reflect.TypeOf((*MockAudienceRequester)(nil).GetUserData), ctx, telegramID)
+}
@@ -1,6 +1,91 @@
 package notification
 
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"telegram-bot/internal/utils/urlutils"
+	"time"
+	"unicode/utf8"
+)
+
+// Types of the attachments
+const (
+	AttachmentPhoto    = "photo"
+	AttachmentDocument = "document"
+)
+
+const (
+	// MaxPhotoSize and MaxDocumentSize max sizes of the files uploaded to Telegram
+	MaxPhotoSize     = 10 << 20
+	MaxDocumentSize  = 50 << 20
+	maxCaptionLength = 1024
+)
+
+var errInvalidAttachment = errors.New("error invalid attachment")
+
+// Notification message sent to a user. If SendAt is in the future, it is scheduled to be sent at that time
 type Notification struct {
-	TelegramID string `json:"telegram_id" binding:"required"`
-	Message    string `json:"message" binding:"required"`
+	TelegramID string      `json:"telegram_id" binding:"required"`
+	Message    string      `json:"message" binding:"required"`
+	Attachment *Attachment `json:"attachment,omitempty"`
+	SendAt     *time.Time  `json:"send_at,omitempty"`
+}
+
+// Attachment photo or document sent after the message. The file is downloaded by Telegram from URL or uploaded
+// from Data, its base64 content. FileName is the name shown for the documents
+type Attachment struct {
+	Type     string `json:"type"`
+	URL      string `json:"url,omitempty"`
+	Data     string `json:"data,omitempty"`
+	FileName string `json:"file_name,omitempty"`
+	Caption  string `json:"caption,omitempty"`
+}
+
+// Decode validates the attachment and returns the content of Data, nil if the file is sent by URL
+func (a Attachment) Decode() ([]byte, error) {
+	var problems []string
+	maxSize := MaxPhotoSize
+	switch a.Type {
+	case AttachmentPhoto:
+	case AttachmentDocument:
+		maxSize = MaxDocumentSize
+		if a.Data != "" && strings.TrimSpace(a.FileName) == "" {
+			problems = append(problems, "file_name is required to upload a document")
+		}
+	default:
+		problems = append(problems, fmt.Sprintf("type must be %s or %s", AttachmentPhoto, AttachmentDocument))
+	}
+
+	if utf8.RuneCountInString(a.Caption) > maxCaptionLength {
+		problems = append(problems, fmt.Sprintf("caption must have at most %d characters", maxCaptionLength))
+	}
+
+	var content []byte
+	switch {
+	case (a.URL == "") == (a.Data == ""):
+		problems = append(problems, "exactly one of url or data is required")
+	case a.URL != "":
+		if !urlutils.IsHTTPURL(a.URL) {
+			problems = append(problems, "url must be an http or https URL")
+		}
+	// DecodedLen counts the padding, so the payloads that are too large are rejected before decoding them
+	case base64.StdEncoding.DecodedLen(len(a.Data)) > maxSize+2:
+		problems = append(problems, fmt.Sprintf("%s must be at most %d bytes", a.Type, maxSize))
+	default:
+		var err error
+		content, err = base64.StdEncoding.DecodeString(a.Data)
+		if err != nil {
+			problems = append(problems, "data must be base64 encoded")
+		} else if len(content) > maxSize {
+			problems = append(problems, fmt.Sprintf("%s must be at most %d bytes", a.Type, maxSize))
+		}
+	}
+
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("%w: %s", errInvalidAttachment, strings.Join(problems, ", "))
+	}
+
+	return content, nil
 }
@@ -0,0 +1,92 @@
+package notification
+
+import (
+	"encoding/base64"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestAttachmentDecode(t *testing.T) {
+	pdf := []byte("%PDF-1.4")
+
+	testCases := []struct {
+		Name             string
+		Attachment       Attachment
+		ExpectedContent  []byte
+		ExpectedProblems []string
+	}{
+		{
+			Name:       "Photo by URL",
+			Attachment: Attachment{Type: AttachmentPhoto, URL: "https://pet.place/prescription.jpg", Caption: "Prescription"},
+		},
+		{
+			Name:            "Document by data",
+			Attachment:      Attachment{Type: AttachmentDocument, Data: base64.StdEncoding.EncodeToString(pdf), FileName: "results.pdf"},
+			ExpectedContent: pdf,
+		},
+		{
+			Name:             "Unknown type",
+			Attachment:       Attachment{Type: "video", URL: "https://pet.place/bachicha.mp4"},
+			ExpectedProblems: []string{"type must be photo or document"},
+		},
+		{
+			Name:             "Without file",
+			Attachment:       Attachment{Type: AttachmentPhoto},
+			ExpectedProblems: []string{"exactly one of url or data is required"},
+		},
+		{
+			Name:             "With URL and data",
+			Attachment:       Attachment{Type: AttachmentPhoto, URL: "https://pet.place/prescription.jpg", Data: "JVBERi0xLjQ="},
+			ExpectedProblems: []string{"exactly one of url or data is required"},
+		},
+		{
+			Name:             "Invalid URL",
+			Attachment:       Attachment{Type: AttachmentPhoto, URL: "file:///etc/passwd"},
+			ExpectedProblems: []string{"url must be an http or https URL"},
+		},
+		{
+			Name:             "Invalid data",
+			Attachment:       Attachment{Type: AttachmentDocument, Data: "not base64!", FileName: "results.pdf"},
+			ExpectedProblems: []string{"data must be base64 encoded"},
+		},
+		{
+			Name:             "Document without file name",
+			Attachment:       Attachment{Type: AttachmentDocument, Data: "JVBERi0xLjQ="},
+			ExpectedProblems: []string{"file_name is required to upload a document"},
+		},
+		{
+			Name: "Photo too large",
+			Attachment: Attachment{
+				Type: AttachmentPhoto,
+				Data: base64.StdEncoding.EncodeToString(make([]byte, MaxPhotoSize+1)),
+			},
+			ExpectedProblems: []string{"photo must be at most 10485760 bytes"},
+		},
+		{
+			Name: "Caption too long",
+			Attachment: Attachment{
+				Type:    AttachmentPhoto,
+				URL:     "https://pet.place/prescription.jpg",
+				Caption: strings.Repeat("a", maxCaptionLength+1),
+			},
+			ExpectedProblems: []string{"caption must have at most 1024 characters"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			content, err := testCase.Attachment.Decode()
+			if len(testCase.ExpectedProblems) == 0 {
+				assert.NoError(t, err)
+				assert.Equal(t, testCase.ExpectedContent, content)
+				return
+			}
+
+			assert.ErrorIs(t, err, errInvalidAttachment)
+			for _, problem := range testCase.ExpectedProblems {
+				assert.Contains(t, err.Error(), problem)
+			}
+		})
+	}
+}
@@ -0,0 +1,348 @@
+package sender
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	jwksURLEnvVar             = "JWKS_URL"
+	jwksPathEnvVar            = "JWKS_PATH"
+	jwksRefreshIntervalEnvVar = "JWKS_REFRESH_INTERVAL"
+
+	defaultJWKSRefreshInterval = time.Hour
+	// jwksMinRefreshInterval minimum time between two fetches of the JWKS, so tokens with random kids cannot
+	// make the verifier flood the auth service
+	jwksMinRefreshInterval = time.Minute
+	jwksFetchTimeout       = 5 * time.Second
+	// maxJWKSSize a JWKS with a few keys takes some KB, a bigger document is not read in memory
+	maxJWKSSize = 1 << 20
+)
+
+var (
+	errFetchingJWKS      = errors.New("error fetching JWKS")
+	errInvalidJWKS       = errors.New("error invalid JWKS")
+	errInvalidJWKSConfig = errors.New("error JWKS_URL and JWKS_PATH cannot be set at the same time")
+	errJWKSTooLarge      = errors.New("error JWKS is too large")
+)
+
+// jwksAlgorithms algorithms of the keys that can be published in a JWKS
+var jwksAlgorithms = []string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512"}
+
+// jwk JSON Web Key, only the public keys used to sign are supported: RSA and EC
+type jwk struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	// RSA
+	N string `json:"n"`
+	E string `json:"e"`
+	// EC
+	Curve string `json:"crv"`
+	X     string `json:"x"`
+	Y     string `json:"y"`
+}
+
+type jwks struct {
+	Keys []jwk `json:"keys"`
+}
+
+// jwksKeySet keys published in a JWKS document, by kid. The keys are fetched again after refreshInterval, or when
+// a token has an unknown kid, at most once every minRefreshInterval
+type jwksKeySet struct {
+	source             string
+	fetch              func(ctx context.Context) ([]byte, error)
+	refreshInterval    time.Duration
+	minRefreshInterval time.Duration
+	now                func() time.Time
+
+	mu          sync.Mutex
+	keys        map[string]verificationKey
+	fetchedAt   time.Time
+	lastAttempt time.Time
+	// refreshing is closed when the fetch in progress finishes, it is nil if there is none
+	refreshing chan struct{}
+}
+
+// newJWKSKeySetFromEnv returns nil if neither JWKS_URL nor JWKS_PATH are set. The keys are fetched right away:
+// if a file cannot be read it is an error, but if the URL cannot be fetched the keys are fetched with the
+// first token, so the bot does not depend on the auth service to start
+func newJWKSKeySetFromEnv() (*jwksKeySet, error) {
+	jwksURL := os.Getenv(jwksURLEnvVar)
+	jwksPath := os.Getenv(jwksPathEnvVar)
+	if jwksURL != "" && jwksPath != "" {
+		return nil, errInvalidJWKSConfig
+	}
+
+	refreshInterval := defaultJWKSRefreshInterval
+	if rawInterval := os.Getenv(jwksRefreshIntervalEnvVar); rawInterval != "" {
+		var err error
+		refreshInterval, err = time.ParseDuration(rawInterval)
+		if err != nil {
+			return nil, fmt.Errorf("error invalid %s: %v", jwksRefreshIntervalEnvVar, err)
+		}
+	}
+
+	switch {
+	case jwksURL != "":
+		client := &http.Client{Timeout: jwksFetchTimeout}
+		keySet := newJWKSKeySet(jwksURL, fetchJWKSURL(client, jwksURL), refreshInterval)
+		if err := keySet.refresh(); err != nil {
+			logrus.Warnf("keys will be fetched with the first token: %v", err)
+		}
+		return keySet, nil
+	case jwksPath != "":
+		keySet := newJWKSKeySet(jwksPath, readJWKSFile(jwksPath), refreshInterval)
+		return keySet, keySet.refresh()
+	default:
+		return nil, nil
+	}
+}
+
+func newJWKSKeySet(source string, fetch func(ctx context.Context) ([]byte, error), refreshInterval time.Duration) *jwksKeySet {
+	return &jwksKeySet{
+		source:             source,
+		fetch:              fetch,
+		refreshInterval:    refreshInterval,
+		minRefreshInterval: jwksMinRefreshInterval,
+		now:                time.Now,
+		keys:               make(map[string]verificationKey),
+	}
+}
+
+// key returns the key with the given kid. If the keys are too old or the kid is unknown, they are fetched again.
+// If the fetch fails, the cached key is used
+func (ks *jwksKeySet) key(keyID string) (verificationKey, error) {
+	ks.mu.Lock()
+	key, found := ks.keys[keyID]
+	isFresh := ks.now().Sub(ks.fetchedAt) < ks.refreshInterval
+	canRefresh := ks.refreshing != nil || ks.now().Sub(ks.lastAttempt) >= ks.minRefreshInterval
+	ks.mu.Unlock()
+
+	if found && isFresh {
+		return key, nil
+	}
+
+	if canRefresh {
+		err := ks.refresh()
+		if err != nil {
+			logrus.Errorf("keeping cached keys: %v", err)
+		}
+
+		ks.mu.Lock()
+		key, found = ks.keys[keyID]
+		ks.mu.Unlock()
+	}
+
+	if !found {
+		return verificationKey{}, fmt.Errorf("%w: %q", errUnknownKeyID, keyID)
+	}
+
+	return key, nil
+}
+
+// refresh fetches the keys, if the JWKS is not valid the cached keys are kept. The keys are fetched without holding
+// mu, so the cached keys can be used meanwhile, and once at a time: if a fetch is in progress, refresh waits for it
+func (ks *jwksKeySet) refresh() error {
+	ks.mu.Lock()
+	if refreshing := ks.refreshing; refreshing != nil {
+		ks.mu.Unlock()
+		<-refreshing
+		return nil
+	}
+
+	refreshing := make(chan struct{})
+	ks.refreshing = refreshing
+	ks.lastAttempt = ks.now()
+	ks.mu.Unlock()
+
+	keys, err := ks.fetchKeys()
+
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	if err == nil {
+		ks.keys = keys
+		ks.fetchedAt = ks.now()
+	}
+	ks.refreshing = nil
+	close(refreshing)
+
+	return err
+}
+
+func (ks *jwksKeySet) fetchKeys() (map[string]verificationKey, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), jwksFetchTimeout)
+	defer cancel()
+
+	rawJWKS, err := ks.fetch(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w from %s: %v", errFetchingJWKS, ks.source, err)
+	}
+
+	keys, err := parseJWKS(rawJWKS)
+	if err != nil {
+		return nil, fmt.Errorf("%w from %s: %v", errInvalidJWKS, ks.source, err)
+	}
+
+	return keys, nil
+}
+
+func fetchJWKSURL(client *http.Client, url string) func(ctx context.Context) ([]byte, error) {
+	return func(ctx context.Context) ([]byte, error) {
+		request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		response, err := client.Do(request)
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			_ = response.Body.Close()
+		}()
+
+		if response.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("status code %d", response.StatusCode)
+		}
+
+		rawJWKS, err := io.ReadAll(io.LimitReader(response.Body, maxJWKSSize+1))
+		if err != nil {
+			return nil, err
+		}
+
+		if len(rawJWKS) > maxJWKSSize {
+			return nil, fmt.Errorf("%w: larger than %d bytes", errJWKSTooLarge, maxJWKSSize)
+		}
+
+		return rawJWKS, nil
+	}
+}
+
+func readJWKSFile(path string) func(ctx context.Context) ([]byte, error) {
+	return func(context.Context) ([]byte, error) {
+		return os.ReadFile(path)
+	}
+}
+
+// parseJWKS returns the signing keys of the document by kid, the keys that are not supported are skipped
+func parseJWKS(rawJWKS []byte) (map[string]verificationKey, error) {
+	var document jwks
+	err := json.Unmarshal(rawJWKS, &document)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]verificationKey, len(document.Keys))
+	for _, webKey := range document.Keys {
+		if webKey.KeyID == "" || (webKey.Use != "" && webKey.Use != "sig") {
+			continue
+		}
+
+		key, err := webKey.verificationKey()
+		if err != nil {
+			logrus.Warnf("skipping key %s of JWKS: %v", webKey.KeyID, err)
+			continue
+		}
+		keys[webKey.KeyID] = key
+	}
+
+	return keys, nil
+}
+
+// verificationKey if the key does not have alg, RSA keys are used with RS256 and EC keys with the algorithm of
+// their curve
+func (webKey jwk) verificationKey() (verificationKey, error) {
+	switch webKey.KeyType {
+	case "RSA":
+		n, err := decodeBigInt(webKey.N)
+		if err != nil {
+			return verificationKey{}, fmt.Errorf("invalid n: %v", err)
+		}
+		e, err := decodeBigInt(webKey.E)
+		if err != nil || !e.IsInt64() {
+			return verificationKey{}, fmt.Errorf("invalid e")
+		}
+
+		algorithm := webKey.Algorithm
+		if algorithm == "" {
+			algorithm = "RS256"
+		}
+		if algorithm != "RS256" && algorithm != "RS384" && algorithm != "RS512" {
+			return verificationKey{}, fmt.Errorf("%w: %s", errUnsupportedAlgorithm, algorithm)
+		}
+
+		return verificationKey{
+			algorithm: algorithm,
+			key:       &rsa.PublicKey{N: n, E: int(e.Int64())},
+		}, nil
+	case "EC":
+		curve, curveAlgorithm, err := ecCurve(webKey.Curve)
+		if err != nil {
+			return verificationKey{}, err
+		}
+		if webKey.Algorithm != "" && webKey.Algorithm != curveAlgorithm {
+			return verificationKey{}, fmt.Errorf("%w: %s with curve %s", errUnsupportedAlgorithm, webKey.Algorithm, webKey.Curve)
+		}
+
+		x, err := decodeBigInt(webKey.X)
+		if err != nil {
+			return verificationKey{}, fmt.Errorf("invalid x: %v", err)
+		}
+		y, err := decodeBigInt(webKey.Y)
+		if err != nil {
+			return verificationKey{}, fmt.Errorf("invalid y: %v", err)
+		}
+		publicKey := &ecdsa.PublicKey{Curve: curve, X: x, Y: y}
+		if _, err := publicKey.ECDH(); err != nil {
+			return verificationKey{}, fmt.Errorf("invalid point: %v", err)
+		}
+
+		return verificationKey{
+			algorithm: curveAlgorithm,
+			key:       publicKey,
+		}, nil
+	default:
+		return verificationKey{}, fmt.Errorf("unsupported kty %q", webKey.KeyType)
+	}
+}
+
+func ecCurve(name string) (elliptic.Curve, string, error) {
+	switch name {
+	case "P-256":
+		return elliptic.P256(), "ES256", nil
+	case "P-384":
+		return elliptic.P384(), "ES384", nil
+	case "P-521":
+		return elliptic.P521(), "ES512", nil
+	default:
+		return nil, "", fmt.Errorf("unsupported crv %q", name)
+	}
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	rawValue, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	if len(rawValue) == 0 {
+		return nil, fmt.Errorf("value is empty")
+	}
+
+	return new(big.Int).SetBytes(rawValue), nil
+}
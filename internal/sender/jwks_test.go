@@ -0,0 +1,269 @@
+package sender
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func rsaJWK(keyID string, key *rsa.PrivateKey) jwk {
+	return jwk{
+		KeyType:   "RSA",
+		KeyID:     keyID,
+		Use:       "sig",
+		Algorithm: "RS256",
+		N:         base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		E:         base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+func ecJWK(keyID string, key *ecdsa.PrivateKey) jwk {
+	return jwk{
+		KeyType: "EC",
+		KeyID:   keyID,
+		Curve:   "P-256",
+		X:       base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, 32))),
+		Y:       base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, 32))),
+	}
+}
+
+func marshalJWKS(t *testing.T, keys ...jwk) []byte {
+	rawJWKS, err := json.Marshal(jwks{Keys: keys})
+	require.NoError(t, err)
+	return rawJWKS
+}
+
+func TestParseJWKS(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	encryptionKey := rsaJWK("rsa-enc", rsaKey)
+	encryptionKey.Use = "enc"
+	symmetricKey := jwk{KeyType: "oct", KeyID: "oct"}
+	invalidPoint := ecJWK("ec-invalid", ecdsaKey)
+	invalidPoint.Y = invalidPoint.X
+
+	keys, err := parseJWKS(marshalJWKS(
+		t,
+		rsaJWK("rsa", rsaKey),
+		ecJWK("ec", ecdsaKey),
+		encryptionKey,
+		symmetricKey,
+		invalidPoint,
+	))
+	require.NoError(t, err)
+
+	require.Len(t, keys, 2)
+	assert.Equal(t, "RS256", keys["rsa"].algorithm)
+	assert.True(t, rsaKey.PublicKey.Equal(keys["rsa"].key))
+	assert.Equal(t, "ES256", keys["ec"].algorithm)
+	assert.True(t, ecdsaKey.PublicKey.Equal(keys["ec"].key))
+
+	_, err = parseJWKS([]byte(`{"keys": "salchicha"}`))
+	assert.Error(t, err)
+}
+
+// fakeJWKSSource JWKS source that counts the fetches
+type fakeJWKSSource struct {
+	mu      sync.Mutex
+	rawJWKS []byte
+	err     error
+	fetches int
+}
+
+func (fs *fakeJWKSSource) fetch(context.Context) ([]byte, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	fs.fetches++
+	return fs.rawJWKS, fs.err
+}
+
+func (fs *fakeJWKSSource) set(rawJWKS []byte, err error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	fs.rawJWKS, fs.err = rawJWKS, err
+}
+
+func TestJWKSKeySet(t *testing.T) {
+	firstKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	secondKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	now := time.Date(2024, time.June, 9, 12, 0, 0, 0, time.UTC)
+	source := &fakeJWKSSource{rawJWKS: marshalJWKS(t, rsaJWK("first", firstKey))}
+	keySet := newJWKSKeySet("fake", source.fetch, time.Hour)
+	keySet.now = func() time.Time { return now }
+	require.NoError(t, keySet.refresh())
+
+	t.Run("Known kid is served from the cache", func(t *testing.T) {
+		_, err := keySet.key("first")
+		require.NoError(t, err)
+		assert.Equal(t, 1, source.fetches)
+	})
+
+	t.Run("Unknown kid refreshes the keys", func(t *testing.T) {
+		source.set(marshalJWKS(t, rsaJWK("first", firstKey), rsaJWK("second", secondKey)), nil)
+		now = now.Add(jwksMinRefreshInterval)
+
+		key, err := keySet.key("second")
+		require.NoError(t, err)
+		assert.True(t, secondKey.PublicKey.Equal(key.key))
+		assert.Equal(t, 2, source.fetches)
+	})
+
+	t.Run("Unknown kids do not refresh the keys more than once per interval", func(t *testing.T) {
+		_, err := keySet.key("third")
+		assert.ErrorIs(t, err, errUnknownKeyID)
+		_, err = keySet.key("fourth")
+		assert.ErrorIs(t, err, errUnknownKeyID)
+		assert.Equal(t, 2, source.fetches)
+	})
+
+	t.Run("Cached keys are used if the refresh fails", func(t *testing.T) {
+		source.set(nil, errors.New("auth service is down"))
+		now = now.Add(2 * time.Hour)
+
+		_, err := keySet.key("first")
+		require.NoError(t, err)
+		assert.Equal(t, 3, source.fetches)
+	})
+}
+
+func TestJWKSKeySetFetchesWithoutBlockingTheCachedKeys(t *testing.T) {
+	firstKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	secondKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	source := &fakeJWKSSource{rawJWKS: marshalJWKS(t, rsaJWK("first", firstKey))}
+	keySet := newJWKSKeySet("fake", source.fetch, time.Hour)
+	keySet.minRefreshInterval = 0
+	require.NoError(t, keySet.refresh())
+
+	fetching := make(chan struct{})
+	releaseFetch := make(chan struct{})
+	keySet.fetch = func(ctx context.Context) ([]byte, error) {
+		close(fetching)
+		<-releaseFetch
+		return marshalJWKS(t, rsaJWK("first", firstKey), rsaJWK("second", secondKey)), nil
+	}
+
+	var wg sync.WaitGroup
+	fetchedKeys := make(chan verificationKey, 2)
+	for range 2 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			key, err := keySet.key("second")
+			assert.NoError(t, err)
+			fetchedKeys <- key
+		}()
+	}
+	<-fetching
+
+	_, err = keySet.key("first")
+	assert.NoError(t, err, "the cached keys must be served while the keys are fetched")
+
+	close(releaseFetch)
+	wg.Wait()
+	close(fetchedKeys)
+	for key := range fetchedKeys {
+		assert.True(t, secondKey.PublicKey.Equal(key.key))
+	}
+}
+
+func TestFetchJWKSURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		size := maxJWKSSize
+		if r.URL.Path == "/too-large" {
+			size++
+		}
+		_, _ = w.Write(make([]byte, size))
+	}))
+	defer server.Close()
+
+	rawJWKS, err := fetchJWKSURL(server.Client(), server.URL+"/jwks")(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, rawJWKS, maxJWKSSize)
+
+	_, err = fetchJWKSURL(server.Client(), server.URL+"/too-large")(context.Background())
+	assert.ErrorIs(t, err, errJWKSTooLarge)
+}
+
+func TestTokenVerifierWithJWKS(t *testing.T) {
+	firstKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	rotatedKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	var mu sync.Mutex
+	publishedKeys := []jwk{rsaJWK("first", firstKey)}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		_, _ = w.Write(marshalJWKS(t, publishedKeys...))
+	}))
+	defer server.Close()
+
+	setVerifierEnvs(t, "")
+	t.Setenv(jwksURLEnvVar, server.URL)
+	verifier, err := newTokenVerifierFromEnv()
+	require.NoError(t, err)
+	verifier.jwks.minRefreshInterval = 0
+
+	t.Run("Token signed with a key of the JWKS", func(t *testing.T) {
+		_, err := verifier.verify(signToken(t, jwt.SigningMethodRS256, "first", firstKey, validClaims(nil)))
+		assert.NoError(t, err)
+	})
+
+	t.Run("Token signed with a key published after startup", func(t *testing.T) {
+		mu.Lock()
+		publishedKeys = append(publishedKeys, ecJWK("rotated", rotatedKey))
+		mu.Unlock()
+
+		_, err := verifier.verify(signToken(t, jwt.SigningMethodES256, "rotated", rotatedKey, validClaims(nil)))
+		assert.NoError(t, err)
+	})
+
+	t.Run("Token of the JWKS does not need the access code", func(t *testing.T) {
+		claims := validClaims(func(claims jwt.MapClaims) {
+			delete(claims, accessCodeClaim)
+		})
+		_, err := verifier.verify(signToken(t, jwt.SigningMethodRS256, "first", firstKey, claims))
+		assert.NoError(t, err)
+	})
+
+	t.Run("Token signed with the shared secret", func(t *testing.T) {
+		_, err := verifier.verify(signToken(t, jwt.SigningMethodHS256, "", []byte(testSecret), validClaims(nil)))
+		assert.NoError(t, err)
+	})
+
+	t.Run("Token with a kid that is not published", func(t *testing.T) {
+		_, err := verifier.verify(signToken(t, jwt.SigningMethodRS256, "unknown", firstKey, validClaims(nil)))
+		assert.ErrorIs(t, err, errInvalidToken)
+	})
+
+	t.Run("Token signed with HMAC using the public key of the JWKS", func(t *testing.T) {
+		_, err := verifier.verify(signToken(t, jwt.SigningMethodHS256, "first", firstKey.N.Bytes(), validClaims(nil)))
+		assert.ErrorIs(t, err, errInvalidToken)
+	})
+}
@@ -0,0 +1,49 @@
+package sender
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	metricsNamespace = "telegramer"
+	metricsSubsystem = "sender"
+
+	outcomeSent              = "sent"
+	outcomeInvalidTelegramID = "invalid_telegram_id"
+	outcomeInvalidAttachment = "invalid_attachment"
+	outcomeScheduled         = "scheduled"
+	outcomeScheduleError     = "schedule_error"
+	outcomeUnreachable       = "unreachable"
+	outcomeSuppressed        = "suppressed"
+	outcomeSendError         = "send_error"
+	outcomeSkipped           = "skipped"
+	outcomeAudienceError     = "audience_error"
+)
+
+var (
+	// notificationBatchSize amount of notifications received in each trigger
+	notificationBatchSize = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "notification_batch_size",
+		Help:      "Amount of notifications received in each trigger.",
+		Buckets:   []float64{1, 5, 10, 25, 50, 100, 250, 500},
+	})
+
+	// notificationsProcessed notifications processed by outcome
+	notificationsProcessed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "notifications_total",
+		Help:      "Notifications processed by outcome (sent, scheduled, invalid_telegram_id, invalid_attachment, schedule_error, unreachable, suppressed or send_error).",
+	}, []string{"outcome"})
+
+	// broadcastMessages messages of the broadcasts by outcome
+	broadcastMessages = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "broadcast_messages_total",
+		Help:      "Known users processed by the broadcasts by outcome (sent, skipped, suppressed, audience_error, unreachable or send_error).",
+	}, []string{"outcome"})
+)
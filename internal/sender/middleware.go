@@ -1,25 +1,52 @@
 package sender
 
 import (
-	"fmt"
+	"errors"
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/sirupsen/logrus"
 	"net/http"
-	"os"
 )
 
+const jwtHeader = "Authorization"
+
+// AuthMode way in which a request can be authenticated
+type AuthMode int
+
 const (
-	secretEnvVar                 = "SECRET"
-	cryptographicAlgorithmEnvVar = "ALGORITHM"
-	accessCodeEnvVar             = "ACCESS_CODE"
-	jwtHeader                    = "Authorization"
+	// AuthAccessToken JWT in the Authorization header
+	AuthAccessToken AuthMode = iota
+	// AuthRequestSigning HMAC-SHA256 signature of the request, for the clients that cannot mint access tokens
+	AuthRequestSigning
 )
 
-// AccessControl middleware use by each endpoint to create a context.AppContext
-func AccessControl() gin.HandlerFunc {
+var (
+	errAuthModeNotAllowed     = errors.New("error authentication mode not allowed in this route")
+	errAccessTokensDisabled   = errors.New("error access tokens are not configured")
+	errRequestSigningDisabled = errors.New("error request signing is not configured")
+)
+
+// AccessControl middleware use by each endpoint to check that the request has a valid access token. The scopes
+// granted by the token are checked by RequireScope
+func (ns *NotificationsSender) AccessControl() gin.HandlerFunc {
+	return ns.Authenticate(AuthAccessToken)
+}
+
+// Authenticate middleware that checks that the request is authenticated with one of the given modes, so each
+// route group can pick the ones it accepts. The requests with the signature header are verified as signed
+// requests and the rest as requests with an access token. The granted scopes are checked by RequireScope
+func (ns *NotificationsSender) Authenticate(modes ...AuthMode) gin.HandlerFunc {
+	allowedModes := make(map[AuthMode]bool, len(modes))
+	for _, mode := range modes {
+		allowedModes[mode] = true
+	}
+
 	return func(c *gin.Context) {
-		err := checkAccessToken(c.Request.Header.Get(jwtHeader))
+		mode := AuthAccessToken
+		if c.GetHeader(signatureHeader) != "" {
+			mode = AuthRequestSigning
+		}
+
+		scopes, err := ns.authenticate(c.Request, mode, allowedModes[mode])
 		if err != nil {
 			logrus.Error(err)
 			errResponse := errorResponse{
@@ -31,51 +58,31 @@ func AccessControl() gin.HandlerFunc {
 			return
 		}
 
+		c.Set(scopesKey, scopes)
 		c.Next()
 	}
 }
 
-// checkAccessToken checks if the JWT is valid and contains the correct access code
-func checkAccessToken(tokenString string) error {
-	if tokenString == "" {
-		return fmt.Errorf("error token is missing")
+// authenticate returns the scopes granted to the request
+func (ns *NotificationsSender) authenticate(request *http.Request, mode AuthMode, allowed bool) (map[string]bool, error) {
+	if !allowed {
+		return nil, errAuthModeNotAllowed
 	}
 
-	secretKey := os.Getenv(secretEnvVar)
-	if secretKey == "" {
-		return fmt.Errorf("error secret key is missing")
+	if mode == AuthRequestSigning {
+		if ns.requestVerifier == nil {
+			return nil, errRequestSigningDisabled
+		}
+		return ns.requestVerifier.verify(request)
 	}
 
-	algorithm := os.Getenv(cryptographicAlgorithmEnvVar)
-	if algorithm == "" {
-		return fmt.Errorf("error algorithm is missing")
+	if ns.tokenVerifier == nil {
+		return nil, errAccessTokensDisabled
 	}
-
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		// Sanity check: the algorithm must be the same as the one in the env var
-		if token.Method.Alg() != algorithm {
-			return nil, fmt.Errorf("error unexpected signing method: %s", token.Method.Alg())
-		}
-
-		return []byte(secretKey), nil
-	})
-
+	token, err := ns.tokenVerifier.verify(request.Header.Get(jwtHeader))
 	if err != nil {
-		return fmt.Errorf("error parsing JWT: %v", err)
-	}
-
-	// Access the claims if the signature is valid
-	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		var accessCode string
-		accessCodeRaw, found := claims["access_code"]
-		if found {
-			accessCode = accessCodeRaw.(string)
-		}
-		if accessCode != os.Getenv(accessCodeEnvVar) {
-			return fmt.Errorf("error invalid access code")
-		}
-		return nil
+		return nil, err
 	}
 
-	return fmt.Errorf("error invalid JWT")
+	return token.scopes(), nil
 }
@@ -0,0 +1,133 @@
+package sender
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAuthenticate(t *testing.T) {
+	setVerifierEnvs(t, "")
+	verifier, err := newTokenVerifierFromEnv()
+	require.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	notificationsSender := &NotificationsSender{
+		tokenVerifier:   verifier,
+		requestVerifier: newTestRequestVerifier(time.Now()),
+	}
+	okHandler := func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	}
+	engine.GET("/telegram/ping", notificationsSender.AccessControl(), okHandler)
+	engine.POST(
+		"/telegram/notifications",
+		notificationsSender.Authenticate(AuthAccessToken, AuthRequestSigning),
+		RequireScope(ScopeNotificationsSend),
+		okHandler,
+	)
+	engine.POST(
+		"/telegram/broadcasts",
+		notificationsSender.Authenticate(AuthRequestSigning),
+		RequireScope(ScopeBroadcastSend),
+		okHandler,
+	)
+
+	validToken := signToken(t, jwt.SigningMethodHS256, "", []byte(testSecret), validClaims(func(claims jwt.MapClaims) {
+		claims[scopeClaim] = ScopeNotificationsSend
+	}))
+	withToken := func(token string) func(request *http.Request) {
+		return func(request *http.Request) {
+			request.Header.Set(jwtHeader, token)
+		}
+	}
+	signed := func(method string, target string, nonce string) *http.Request {
+		return signedRequest{method: method, target: target, timestamp: time.Now(), nonce: nonce}.build(testSigningSecret, nil)
+	}
+
+	testCases := []struct {
+		Name               string
+		Request            *http.Request
+		ExpectedStatusCode int
+	}{
+		{
+			Name:               "Access token in a route that only accepts access tokens",
+			Request:            newRequest(http.MethodGet, "/telegram/ping", withToken(validToken)),
+			ExpectedStatusCode: http.StatusOK,
+		},
+		{
+			Name:               "Missing access token",
+			Request:            newRequest(http.MethodGet, "/telegram/ping", nil),
+			ExpectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			Name: "Token without exp",
+			Request: newRequest(http.MethodGet, "/telegram/ping", withToken(signToken(
+				t,
+				jwt.SigningMethodHS256,
+				"",
+				[]byte(testSecret),
+				validClaims(func(claims jwt.MapClaims) { delete(claims, "exp") }),
+			))),
+			ExpectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			Name:               "Signed request in a route that only accepts access tokens",
+			Request:            signed(http.MethodGet, "/telegram/ping", "nonce-1"),
+			ExpectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			Name:               "Access token in a route that accepts both",
+			Request:            newRequest(http.MethodPost, "/telegram/notifications", withToken(validToken)),
+			ExpectedStatusCode: http.StatusOK,
+		},
+		{
+			Name:               "Signed request in a route that accepts both",
+			Request:            signed(http.MethodPost, "/telegram/notifications", "nonce-2"),
+			ExpectedStatusCode: http.StatusOK,
+		},
+		{
+			Name:               "Access token in a route that only accepts signed requests",
+			Request:            newRequest(http.MethodPost, "/telegram/broadcasts", withToken(validToken)),
+			ExpectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			Name:               "Signed request without the scope of the route",
+			Request:            signed(http.MethodPost, "/telegram/broadcasts", "nonce-3"),
+			ExpectedStatusCode: http.StatusForbidden,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			engine.ServeHTTP(recorder, testCase.Request)
+			assert.Equal(t, testCase.ExpectedStatusCode, recorder.Code)
+		})
+	}
+
+	t.Run("Request signing is not configured", func(t *testing.T) {
+		withoutSigning := &NotificationsSender{tokenVerifier: verifier}
+		engine := gin.New()
+		engine.POST("/telegram/notifications", withoutSigning.Authenticate(AuthAccessToken, AuthRequestSigning), okHandler)
+
+		recorder := httptest.NewRecorder()
+		engine.ServeHTTP(recorder, signed(http.MethodPost, "/telegram/notifications", "nonce-4"))
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	})
+}
+
+func newRequest(method string, target string, modify func(request *http.Request)) *http.Request {
+	request := httptest.NewRequest(method, target, nil)
+	if modify != nil {
+		modify(request)
+	}
+
+	return request
+}
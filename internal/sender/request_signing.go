@@ -0,0 +1,231 @@
+package sender
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	clientIDHeader  = "X-Client-ID"
+	timestampHeader = "X-Timestamp"
+	nonceHeader     = "X-Nonce"
+	signatureHeader = "X-Signature"
+
+	signingKeysPathEnvVar = "HMAC_KEYS_PATH"
+	maxClockSkewEnvVar    = "HMAC_MAX_CLOCK_SKEW"
+
+	defaultMaxClockSkew = 5 * time.Minute
+	// maxSignedBodySize the body is read in memory to verify the signature
+	maxSignedBodySize = 10 << 20
+	// maxNonces bounds the memory of the nonce cache, once it is full the requests are rejected until a nonce expires
+	maxNonces = 100_000
+)
+
+var (
+	errMissingSignatureHeader = errors.New("error request signing header is missing")
+	errUnknownClient          = errors.New("error unknown client")
+	errInvalidTimestamp       = errors.New("error invalid timestamp")
+	errInvalidSignature       = errors.New("error invalid signature")
+	errReplayedRequest        = errors.New("error nonce was already used")
+	errTooManyNonces          = errors.New("error too many requests within the clock skew")
+	errReadingSignedBody      = errors.New("error reading signed body")
+	errInvalidSigningKey      = errors.New("error invalid signing key")
+)
+
+// signingKeyConfig key of the signing keys file, the scopes are the ones granted to the client
+type signingKeyConfig struct {
+	ClientID string   `json:"client_id"`
+	Secret   string   `json:"secret"`
+	Scopes   []string `json:"scopes"`
+}
+
+type signingClient struct {
+	secret []byte
+	scopes map[string]bool
+}
+
+// requestVerifier verifies the requests signed with HMAC-SHA256, for the clients that cannot mint access tokens.
+// The signature covers the method, the path with the query, the timestamp, the nonce and the body, see
+// signingPayload. A request is rejected if its timestamp is off by more than maxClockSkew or if its nonce was
+// already used within that window, so it cannot be replayed
+type requestVerifier struct {
+	clients      map[string]signingClient
+	maxClockSkew time.Duration
+	nonces       *nonceCache
+	now          func() time.Time
+}
+
+// newRequestVerifierFromEnv returns nil if HMAC_KEYS_PATH is not set, so the requests cannot be signed.
+// HMAC_MAX_CLOCK_SKEW changes defaultMaxClockSkew
+func newRequestVerifierFromEnv() (*requestVerifier, error) {
+	keysPath := os.Getenv(signingKeysPathEnvVar)
+	if keysPath == "" {
+		return nil, nil
+	}
+
+	rawKeys, err := os.ReadFile(keysPath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading signing keys file: %v", err)
+	}
+
+	var keyConfigs []signingKeyConfig
+	err = json.Unmarshal(rawKeys, &keyConfigs)
+	if err != nil {
+		return nil, fmt.Errorf("error unmarshalling signing keys file: %v", err)
+	}
+
+	clients := make(map[string]signingClient, len(keyConfigs))
+	for _, config := range keyConfigs {
+		if config.ClientID == "" || config.Secret == "" {
+			return nil, fmt.Errorf("%w: client_id and secret are required", errInvalidSigningKey)
+		}
+		if _, found := clients[config.ClientID]; found {
+			return nil, fmt.Errorf("%w: client_id %s is repeated", errInvalidSigningKey, config.ClientID)
+		}
+
+		scopes := make(map[string]bool, len(config.Scopes))
+		for _, scope := range config.Scopes {
+			scopes[scope] = true
+		}
+		clients[config.ClientID] = signingClient{secret: []byte(config.Secret), scopes: scopes}
+	}
+
+	maxClockSkew := defaultMaxClockSkew
+	if rawSkew := os.Getenv(maxClockSkewEnvVar); rawSkew != "" {
+		maxClockSkew, err = time.ParseDuration(rawSkew)
+		if err != nil {
+			return nil, fmt.Errorf("error invalid %s: %v", maxClockSkewEnvVar, err)
+		}
+	}
+
+	return newRequestVerifier(clients, maxClockSkew), nil
+}
+
+func newRequestVerifier(clients map[string]signingClient, maxClockSkew time.Duration) *requestVerifier {
+	return &requestVerifier{
+		clients:      clients,
+		maxClockSkew: maxClockSkew,
+		// A nonce has to be remembered while its timestamp is within the window, in both directions
+		nonces: newNonceCache(2*maxClockSkew, maxNonces),
+		now:    time.Now,
+	}
+}
+
+// verify returns the scopes of the client that signed the request. The body is read and replaced, so it can
+// be read again by the handlers
+func (rv *requestVerifier) verify(request *http.Request) (map[string]bool, error) {
+	clientID := request.Header.Get(clientIDHeader)
+	rawTimestamp := request.Header.Get(timestampHeader)
+	nonce := request.Header.Get(nonceHeader)
+	signature := request.Header.Get(signatureHeader)
+	if clientID == "" || rawTimestamp == "" || nonce == "" || signature == "" {
+		return nil, errMissingSignatureHeader
+	}
+
+	client, found := rv.clients[clientID]
+	if !found {
+		return nil, fmt.Errorf("%w: %s", errUnknownClient, clientID)
+	}
+
+	unixTimestamp, err := strconv.ParseInt(rawTimestamp, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errInvalidTimestamp, err)
+	}
+	now := rv.now()
+	skew := now.Sub(time.Unix(unixTimestamp, 0))
+	if skew > rv.maxClockSkew || skew < -rv.maxClockSkew {
+		return nil, fmt.Errorf("%w: off by %v", errInvalidTimestamp, skew)
+	}
+
+	var body []byte
+	if request.Body != nil {
+		body, err = io.ReadAll(http.MaxBytesReader(nil, request.Body, maxSignedBodySize))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", errReadingSignedBody, err)
+		}
+		request.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	rawSignature, err := hex.DecodeString(signature)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errInvalidSignature, err)
+	}
+	expectedSignature := signRequest(client.secret, request.Method, request.URL.RequestURI(), rawTimestamp, nonce, body)
+	if !hmac.Equal(rawSignature, expectedSignature) {
+		return nil, errInvalidSignature
+	}
+
+	// The nonce is stored only for valid signatures, otherwise anyone could fill the cache
+	err = rv.nonces.add(clientID+":"+nonce, now)
+	if err != nil {
+		return nil, err
+	}
+
+	return client.scopes, nil
+}
+
+// signRequest returns the HMAC-SHA256 of the signingPayload
+func signRequest(secret []byte, method string, requestURI string, timestamp string, nonce string, body []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(signingPayload(method, requestURI, timestamp, nonce, body)))
+	return mac.Sum(nil)
+}
+
+// signingPayload the method, the path with the query, the timestamp, the nonce and the hex SHA-256 of the body,
+// separated by new lines
+func signingPayload(method string, requestURI string, timestamp string, nonce string, body []byte) string {
+	bodyHash := sha256.Sum256(body)
+	return strings.Join([]string{method, requestURI, timestamp, nonce, hex.EncodeToString(bodyHash[:])}, "\n")
+}
+
+// nonceCache remembers the used nonces for ttl
+type nonceCache struct {
+	mu         sync.Mutex
+	ttl        time.Duration
+	maxEntries int
+	expiries   map[string]time.Time
+}
+
+func newNonceCache(ttl time.Duration, maxEntries int) *nonceCache {
+	return &nonceCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		expiries:   make(map[string]time.Time),
+	}
+}
+
+// add returns an error if the nonce was already used or if the cache is full of nonces that did not expire
+func (nc *nonceCache) add(nonce string, now time.Time) error {
+	nc.mu.Lock()
+	defer nc.mu.Unlock()
+
+	if expiry, found := nc.expiries[nonce]; found && now.Before(expiry) {
+		return errReplayedRequest
+	}
+
+	if len(nc.expiries) >= nc.maxEntries {
+		for storedNonce, expiry := range nc.expiries {
+			if !now.Before(expiry) {
+				delete(nc.expiries, storedNonce)
+			}
+		}
+	}
+	if len(nc.expiries) >= nc.maxEntries {
+		return errTooManyNonces
+	}
+
+	nc.expiries[nonce] = now.Add(nc.ttl)
+	return nil
+}
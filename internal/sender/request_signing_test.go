@@ -0,0 +1,241 @@
+package sender
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const (
+	testClientID      = "scheduler"
+	testSigningSecret = "shh-salchicha"
+)
+
+// signedRequest signed request of testClientID, the modify function changes it after being signed
+type signedRequest struct {
+	method    string
+	target    string
+	body      string
+	timestamp time.Time
+	nonce     string
+}
+
+func (sr signedRequest) build(secret string, modify func(request *http.Request)) *http.Request {
+	timestamp := fmt.Sprint(sr.timestamp.Unix())
+	request := httptest.NewRequest(sr.method, sr.target, bytes.NewBufferString(sr.body))
+	signature := signRequest([]byte(secret), sr.method, request.URL.RequestURI(), timestamp, sr.nonce, []byte(sr.body))
+
+	request.Header.Set(clientIDHeader, testClientID)
+	request.Header.Set(timestampHeader, timestamp)
+	request.Header.Set(nonceHeader, sr.nonce)
+	request.Header.Set(signatureHeader, hex.EncodeToString(signature))
+	if modify != nil {
+		modify(request)
+	}
+
+	return request
+}
+
+func newTestRequestVerifier(now time.Time) *requestVerifier {
+	verifier := newRequestVerifier(map[string]signingClient{
+		testClientID: {secret: []byte(testSigningSecret), scopes: map[string]bool{ScopeNotificationsSend: true}},
+	}, time.Minute)
+	verifier.now = func() time.Time { return now }
+
+	return verifier
+}
+
+func TestRequestVerifier(t *testing.T) {
+	now := time.Date(2024, time.June, 9, 12, 0, 0, 0, time.UTC)
+	request := signedRequest{
+		method:    http.MethodPost,
+		target:    "/telegram/notifications?dry_run=true",
+		body:      `[{"telegram_id": "911", "message": "Give Bachicha its pill"}]`,
+		timestamp: now,
+	}
+
+	testCases := []struct {
+		Name          string
+		Secret        string
+		Timestamp     time.Time
+		Modify        func(request *http.Request)
+		ExpectedError error
+	}{
+		{
+			Name: "Valid signature",
+		},
+		{
+			Name:      "Timestamp within the clock skew",
+			Timestamp: now.Add(-50 * time.Second),
+		},
+		{
+			Name:          "Signed with another secret",
+			Secret:        "another-secret",
+			ExpectedError: errInvalidSignature,
+		},
+		{
+			Name: "Body was modified",
+			Modify: func(request *http.Request) {
+				request.Body = io.NopCloser(bytes.NewBufferString(`[{"telegram_id": "420", "message": "pum"}]`))
+			},
+			ExpectedError: errInvalidSignature,
+		},
+		{
+			Name: "Query was modified",
+			Modify: func(request *http.Request) {
+				request.URL.RawQuery = "dry_run=false"
+			},
+			ExpectedError: errInvalidSignature,
+		},
+		{
+			Name: "Method was modified",
+			Modify: func(request *http.Request) {
+				request.Method = http.MethodPut
+			},
+			ExpectedError: errInvalidSignature,
+		},
+		{
+			Name: "Signature is not hex",
+			Modify: func(request *http.Request) {
+				request.Header.Set(signatureHeader, "salchicha")
+			},
+			ExpectedError: errInvalidSignature,
+		},
+		{
+			Name:          "Timestamp is too old",
+			Timestamp:     now.Add(-2 * time.Minute),
+			ExpectedError: errInvalidTimestamp,
+		},
+		{
+			Name:          "Timestamp is in the future",
+			Timestamp:     now.Add(2 * time.Minute),
+			ExpectedError: errInvalidTimestamp,
+		},
+		{
+			Name: "Unknown client",
+			Modify: func(request *http.Request) {
+				request.Header.Set(clientIDHeader, "hacker")
+			},
+			ExpectedError: errUnknownClient,
+		},
+		{
+			Name: "Nonce is missing",
+			Modify: func(request *http.Request) {
+				request.Header.Del(nonceHeader)
+			},
+			ExpectedError: errMissingSignatureHeader,
+		},
+	}
+
+	for idx, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			verifier := newTestRequestVerifier(now)
+			secret := testSigningSecret
+			if testCase.Secret != "" {
+				secret = testCase.Secret
+			}
+
+			testRequest := request
+			testRequest.nonce = fmt.Sprintf("nonce-%d", idx)
+			if !testCase.Timestamp.IsZero() {
+				testRequest.timestamp = testCase.Timestamp
+			}
+
+			httpRequest := testRequest.build(secret, testCase.Modify)
+			scopes, err := verifier.verify(httpRequest)
+			if testCase.ExpectedError != nil {
+				assert.ErrorIs(t, err, testCase.ExpectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.True(t, scopes[ScopeNotificationsSend])
+
+			body, err := io.ReadAll(httpRequest.Body)
+			require.NoError(t, err)
+			assert.Equal(t, request.body, string(body), "body must be readable after the verification")
+		})
+	}
+
+	t.Run("Replayed request is rejected", func(t *testing.T) {
+		verifier := newTestRequestVerifier(now)
+		replayedRequest := request
+		replayedRequest.nonce = "replayed"
+
+		_, err := verifier.verify(replayedRequest.build(testSigningSecret, nil))
+		require.NoError(t, err)
+
+		_, err = verifier.verify(replayedRequest.build(testSigningSecret, nil))
+		assert.ErrorIs(t, err, errReplayedRequest)
+	})
+}
+
+func TestNonceCache(t *testing.T) {
+	now := time.Date(2024, time.June, 9, 12, 0, 0, 0, time.UTC)
+	cache := newNonceCache(time.Minute, 2)
+
+	require.NoError(t, cache.add("first", now))
+	assert.ErrorIs(t, cache.add("first", now.Add(30*time.Second)), errReplayedRequest)
+	require.NoError(t, cache.add("second", now.Add(30*time.Second)))
+	assert.ErrorIs(t, cache.add("third", now.Add(30*time.Second)), errTooManyNonces)
+
+	// first expired, so its entry is freed and it can be used again
+	assert.NoError(t, cache.add("third", now.Add(time.Minute)))
+	assert.NoError(t, cache.add("first", now.Add(time.Minute+30*time.Second)))
+}
+
+func TestNewRequestVerifierFromEnv(t *testing.T) {
+	testCases := []struct {
+		Name          string
+		KeysFile      string
+		ExpectedError error
+	}{
+		{
+			Name:     "Valid keys",
+			KeysFile: `[{"client_id": "scheduler", "secret": "shh", "scopes": ["notifications:send"]}]`,
+		},
+		{
+			Name:          "Key without secret",
+			KeysFile:      `[{"client_id": "scheduler"}]`,
+			ExpectedError: errInvalidSigningKey,
+		},
+		{
+			Name:          "Repeated client",
+			KeysFile:      `[{"client_id": "scheduler", "secret": "a"}, {"client_id": "scheduler", "secret": "b"}]`,
+			ExpectedError: errInvalidSigningKey,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			keysPath := filepath.Join(t.TempDir(), "signing_keys.json")
+			require.NoError(t, os.WriteFile(keysPath, []byte(testCase.KeysFile), 0o600))
+			t.Setenv(signingKeysPathEnvVar, keysPath)
+
+			verifier, err := newRequestVerifierFromEnv()
+			if testCase.ExpectedError != nil {
+				assert.ErrorIs(t, err, testCase.ExpectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Contains(t, verifier.clients, testClientID)
+		})
+	}
+
+	t.Run("Request signing is not configured", func(t *testing.T) {
+		t.Setenv(signingKeysPathEnvVar, "")
+		verifier, err := newRequestVerifierFromEnv()
+		require.NoError(t, err)
+		assert.Nil(t, verifier)
+	})
+}
@@ -6,11 +6,19 @@ import (
 )
 
 func (ns *NotificationsSender) RegisterRoutes(r *gin.Engine) {
-	group := r.Group("/telegram", AccessControl())
+	group := r.Group("/telegram", ns.Authenticate(AuthAccessToken, AuthRequestSigning))
 
 	group.GET("/ping", func(c *gin.Context) {
 		c.JSON(http.StatusOK, "pong")
 		return
 	})
-	group.POST("/notifications", ns.TriggerNotifications)
+	group.POST("/notifications", RequireScope(ScopeNotificationsSend), ns.TriggerNotifications)
+
+	broadcasts := group.Group("/broadcasts", RequireScope(ScopeBroadcastSend))
+	broadcasts.POST("", ns.CreateBroadcast)
+	broadcasts.GET("/:"+broadcastIDParam, ns.GetBroadcast)
+	broadcasts.DELETE("/:"+broadcastIDParam, ns.CancelBroadcast)
+
+	group.GET("/unreachable-chats", RequireScope(ScopeNotificationsRead), ns.ListUnreachableChats)
+	group.DELETE("/unreachable-chats/:"+telegramIDParam, RequireScope(ScopeNotificationsSend), ns.DeleteUnreachableChat)
 }
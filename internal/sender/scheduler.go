@@ -0,0 +1,214 @@
+package sender
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"sort"
+	"sync"
+	"telegram-bot/internal/sender/internal/notification"
+	"telegram-bot/internal/utils/filestore"
+	"time"
+)
+
+const (
+	// ScheduleFile name of the file, in the data dir, where the scheduled notifications are persisted
+	ScheduleFile = "scheduled_notifications.json"
+	// maxScheduledNotifications and maxScheduleSize pending notifications, and their size once persisted, that the
+	// scheduler accepts. The file is rewritten on each change, so it must stay small
+	maxScheduledNotifications = 10000
+	maxScheduleSize           = 16 << 20
+	// schedulerMaxWait the scheduler checks the pending notifications at least with this frequency
+	schedulerMaxWait = time.Hour
+)
+
+var (
+	errScheduleFull         = errors.New("error too many scheduled notifications")
+	errScheduleNotPersisted = errors.New("error scheduled notifications are not available, DATA_DIR is not set")
+	// errScheduledAttachmentData the scheduled attachments must be sent by URL, their data is too large to persist
+	errScheduledAttachmentData = errors.New("error scheduled attachments must have url instead of data")
+)
+
+// scheduledNotification notification that is sent at SendAt
+type scheduledNotification struct {
+	ID           string                    `json:"id"`
+	SendAt       time.Time                 `json:"send_at"`
+	Notification notification.Notification `json:"notification"`
+}
+
+// sendFunc sends the notification and returns the outcome of the send, see NotificationsSender.sendNotification
+type sendFunc func(ctx context.Context, notificationToSend notification.Notification) (string, error)
+
+// scheduler sends the notifications at their SendAt. The pending notifications are persisted in path, so they
+// are sent after a restart; the ones whose time passed while the sender was down are sent as soon as it starts.
+// Without path, the notifications are not scheduled. A notification is removed once it is sent, if it fails it is
+// not retried
+type scheduler struct {
+	send   sendFunc
+	path   string
+	now    func() time.Time
+	wakeup chan struct{}
+
+	mu sync.Mutex
+	// pending notifications sorted by SendAt
+	pending []scheduledNotification
+	// size of the pending notifications once persisted
+	size int
+}
+
+// newScheduler loads the pending notifications of the file, if the path is empty schedule rejects the notifications
+func newScheduler(path string, send sendFunc) (*scheduler, error) {
+	var pending []scheduledNotification
+	err := filestore.Load(path, &pending)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(pending, func(i, j int) bool {
+		return pending[i].SendAt.Before(pending[j].SendAt)
+	})
+
+	size := 0
+	for _, scheduled := range pending {
+		size += scheduledSize(scheduled)
+	}
+
+	return &scheduler{
+		send:    send,
+		path:    path,
+		now:     time.Now,
+		wakeup:  make(chan struct{}, 1),
+		pending: pending,
+		size:    size,
+	}, nil
+}
+
+// schedule persists the notification to be sent at sendAt and returns its ID
+func (s *scheduler) schedule(notificationToSend notification.Notification, sendAt time.Time) (string, error) {
+	if s.path == "" {
+		return "", errScheduleNotPersisted
+	}
+
+	scheduled := scheduledNotification{
+		ID:           uuid.NewString(),
+		SendAt:       sendAt,
+		Notification: notificationToSend,
+	}
+	size := scheduledSize(scheduled)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.pending) >= maxScheduledNotifications || s.size+size > maxScheduleSize {
+		return "", errScheduleFull
+	}
+
+	position := sort.Search(len(s.pending), func(i int) bool {
+		return s.pending[i].SendAt.After(sendAt)
+	})
+
+	pending := make([]scheduledNotification, 0, len(s.pending)+1)
+	pending = append(pending, s.pending[:position]...)
+	pending = append(pending, scheduled)
+	pending = append(pending, s.pending[position:]...)
+
+	err := filestore.Save(s.path, pending)
+	if err != nil {
+		return "", err
+	}
+	s.pending = pending
+	s.size += size
+
+	select {
+	case s.wakeup <- struct{}{}:
+	default:
+	}
+
+	return scheduled.ID, nil
+}
+
+// run sends the notifications when they are due until the context is done, is a blocking function
+func (s *scheduler) run(ctx context.Context) {
+	for {
+		s.sendDue(ctx)
+
+		timer := time.NewTimer(s.untilNext())
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-s.wakeup:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+// sendDue sends the notifications whose SendAt passed, one at a time
+func (s *scheduler) sendDue(ctx context.Context) {
+	for ctx.Err() == nil {
+		scheduled, found := s.nextDue()
+		if !found {
+			return
+		}
+
+		outcome, err := s.send(ctx, scheduled.Notification)
+		notificationsProcessed.WithLabelValues(outcome).Inc()
+		if err != nil {
+			logrus.Errorf("error sending scheduled notification %s: %v", scheduled.ID, err)
+		}
+
+		s.remove(scheduled.ID)
+	}
+}
+
+func (s *scheduler) nextDue() (scheduledNotification, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.pending) == 0 || s.pending[0].SendAt.After(s.now()) {
+		return scheduledNotification{}, false
+	}
+
+	return s.pending[0], true
+}
+
+func (s *scheduler) remove(scheduledID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pending := make([]scheduledNotification, 0, len(s.pending))
+	for _, scheduled := range s.pending {
+		if scheduled.ID != scheduledID {
+			pending = append(pending, scheduled)
+			continue
+		}
+		s.size -= scheduledSize(scheduled)
+	}
+
+	err := filestore.Save(s.path, pending)
+	if err != nil {
+		logrus.Errorf("error persisting scheduled notifications, %s could be sent again after a restart: %v", scheduledID, err)
+	}
+	s.pending = pending
+}
+
+// untilNext returns how long until the next notification is due
+func (s *scheduler) untilNext() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.pending) == 0 {
+		return schedulerMaxWait
+	}
+
+	return min(s.pending[0].SendAt.Sub(s.now()), schedulerMaxWait)
+}
+
+// scheduledSize returns the size of the notification once persisted
+func scheduledSize(scheduled scheduledNotification) int {
+	rawScheduled, _ := json.Marshal(scheduled)
+	return len(rawScheduled)
+}
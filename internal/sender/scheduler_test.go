@@ -0,0 +1,223 @@
+package sender
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"path/filepath"
+	"sync"
+	"telegram-bot/internal/sender/internal/notification"
+	"testing"
+	"time"
+)
+
+// sentNotifications records the messages of the notifications sent by a scheduler
+type sentNotifications struct {
+	mu       sync.Mutex
+	messages []string
+	err      error
+}
+
+func (sn *sentNotifications) send(_ context.Context, notificationToSend notification.Notification) (string, error) {
+	sn.mu.Lock()
+	defer sn.mu.Unlock()
+
+	sn.messages = append(sn.messages, notificationToSend.Message)
+	if sn.err != nil {
+		return outcomeSendError, sn.err
+	}
+
+	return outcomeSent, nil
+}
+
+func (sn *sentNotifications) sent() []string {
+	sn.mu.Lock()
+	defer sn.mu.Unlock()
+
+	return append([]string(nil), sn.messages...)
+}
+
+// runScheduler runs the scheduler until the test finishes
+func runScheduler(t *testing.T, s *scheduler) {
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		s.run(ctx)
+		close(stopped)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-stopped
+	})
+}
+
+func pendingNotifications(s *scheduler) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.pending)
+}
+
+// newTestScheduler returns a scheduler that persists the notifications in a temporary dir
+func newTestScheduler(t *testing.T, send sendFunc) *scheduler {
+	s, err := newScheduler(filepath.Join(t.TempDir(), ScheduleFile), send)
+	require.NoError(t, err)
+	return s
+}
+
+func newTestNotification(message string) notification.Notification {
+	return notification.Notification{TelegramID: "911", Message: message}
+}
+
+func TestSchedulerSendsInOrder(t *testing.T) {
+	sent := &sentNotifications{}
+	s := newTestScheduler(t, sent.send)
+
+	now := time.Now()
+	for message, delay := range map[string]time.Duration{"third": 60 * time.Millisecond, "first": 0, "second": 30 * time.Millisecond} {
+		_, err := s.schedule(newTestNotification(message), now.Add(delay))
+		require.NoError(t, err)
+	}
+
+	runScheduler(t, s)
+	require.Eventually(t, func() bool {
+		return pendingNotifications(s) == 0
+	}, 2*time.Second, 5*time.Millisecond)
+
+	assert.Equal(t, []string{"first", "second", "third"}, sent.sent())
+}
+
+func TestSchedulerSurvivesRestarts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ScheduleFile)
+	now := time.Now()
+
+	s, err := newScheduler(path, (&sentNotifications{}).send)
+	require.NoError(t, err)
+	_, err = s.schedule(newTestNotification("tomorrow"), now.Add(24*time.Hour))
+	require.NoError(t, err)
+	_, err = s.schedule(newTestNotification("while down"), now.Add(time.Minute))
+	require.NoError(t, err)
+
+	// The sender restarts after the time of the first notification
+	sent := &sentNotifications{}
+	restarted, err := newScheduler(path, sent.send)
+	require.NoError(t, err)
+	restarted.now = func() time.Time {
+		return now.Add(time.Hour)
+	}
+	runScheduler(t, restarted)
+
+	require.Eventually(t, func() bool {
+		return pendingNotifications(restarted) == 1
+	}, 2*time.Second, 5*time.Millisecond)
+	assert.Equal(t, []string{"while down"}, sent.sent())
+
+	pending, err := newScheduler(path, sent.send)
+	require.NoError(t, err)
+	require.Len(t, pending.pending, 1)
+	assert.Equal(t, "tomorrow", pending.pending[0].Notification.Message)
+}
+
+func TestSchedulerWakesUpWithEarlierNotifications(t *testing.T) {
+	sent := &sentNotifications{err: errors.New("bot was blocked by the user")}
+	s := newTestScheduler(t, sent.send)
+	_, err := s.schedule(newTestNotification("later"), time.Now().Add(time.Hour))
+	require.NoError(t, err)
+
+	runScheduler(t, s)
+	_, err = s.schedule(newTestNotification("now"), time.Now())
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		return len(sent.sent()) == 1
+	}, 2*time.Second, 5*time.Millisecond)
+
+	// Failed notifications are not retried
+	assert.Never(t, func() bool {
+		return len(sent.sent()) > 1
+	}, 50*time.Millisecond, 5*time.Millisecond)
+}
+
+func TestSchedulerIsFull(t *testing.T) {
+	t.Run("Too many notifications", func(t *testing.T) {
+		s := newTestScheduler(t, (&sentNotifications{}).send)
+		s.pending = make([]scheduledNotification, maxScheduledNotifications)
+
+		_, err := s.schedule(newTestNotification("one more"), time.Now())
+		assert.ErrorIs(t, err, errScheduleFull)
+	})
+
+	t.Run("Notifications too large", func(t *testing.T) {
+		s := newTestScheduler(t, (&sentNotifications{}).send)
+		s.size = maxScheduleSize
+
+		_, err := s.schedule(newTestNotification("one more"), time.Now())
+		assert.ErrorIs(t, err, errScheduleFull)
+	})
+}
+
+func TestSchedulerTracksTheSize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ScheduleFile)
+	s, err := newScheduler(path, (&sentNotifications{}).send)
+	require.NoError(t, err)
+
+	scheduledID, err := s.schedule(newTestNotification("tomorrow"), time.Now().Add(24*time.Hour))
+	require.NoError(t, err)
+	size := scheduledSize(s.pending[0])
+	assert.Equal(t, size, s.size)
+
+	restarted, err := newScheduler(path, (&sentNotifications{}).send)
+	require.NoError(t, err)
+	assert.Equal(t, size, restarted.size)
+
+	s.remove(scheduledID)
+	assert.Zero(t, s.size)
+}
+
+func TestSchedulerWithoutDataDir(t *testing.T) {
+	s, err := newScheduler("", (&sentNotifications{}).send)
+	require.NoError(t, err)
+
+	_, err = s.schedule(newTestNotification("tomorrow"), time.Now().Add(24*time.Hour))
+	assert.ErrorIs(t, err, errScheduleNotPersisted)
+}
+
+func TestScheduleNotification(t *testing.T) {
+	sendAt := time.Now().Add(time.Hour)
+	testCases := []struct {
+		Name            string
+		Attachment      *notification.Attachment
+		ExpectedOutcome string
+		ExpectedError   error
+	}{
+		{
+			Name:            "Without attachment",
+			ExpectedOutcome: outcomeScheduled,
+		},
+		{
+			Name:            "Attachment by URL",
+			Attachment:      &notification.Attachment{Type: notification.AttachmentPhoto, URL: "https://pet.place/bachicha.jpg"},
+			ExpectedOutcome: outcomeScheduled,
+		},
+		{
+			Name:            "Attachment with data",
+			Attachment:      &notification.Attachment{Type: notification.AttachmentPhoto, Data: "aGk="},
+			ExpectedOutcome: outcomeInvalidAttachment,
+			ExpectedError:   errScheduledAttachmentData,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			ns := &NotificationsSender{scheduler: newTestScheduler(t, (&sentNotifications{}).send)}
+			notificationToSend := newTestNotification("Give Bachicha its pill")
+			notificationToSend.Attachment = testCase.Attachment
+			notificationToSend.SendAt = &sendAt
+
+			outcome, err := ns.scheduleNotification(notificationToSend)
+			assert.Equal(t, testCase.ExpectedOutcome, outcome)
+			assert.ErrorIs(t, err, testCase.ExpectedError)
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package sender
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"strings"
+)
+
+// Scopes that the access tokens can grant, ScopeAdmin grants all of them
+const (
+	ScopeNotificationsSend = "notifications:send"
+	ScopeNotificationsRead = "notifications:read"
+	ScopeBroadcastSend     = "broadcast:send"
+	ScopeAdmin             = "admin"
+)
+
+const (
+	// scopeClaim space separated scopes, as defined in RFC 8693
+	scopeClaim = "scope"
+	// scopesClaim list of scopes, used by the issuers that do not follow RFC 8693
+	scopesClaim = "scopes"
+	scopesKey   = "scopes"
+)
+
+// tokenScopes returns the scopes granted in the scope and scopes claims
+func tokenScopes(claims jwt.MapClaims) map[string]bool {
+	scopes := make(map[string]bool)
+	if scope, ok := claims[scopeClaim].(string); ok {
+		for _, scopeName := range strings.Fields(scope) {
+			scopes[scopeName] = true
+		}
+	}
+
+	if scopeList, ok := claims[scopesClaim].([]any); ok {
+		for _, scopeName := range scopeList {
+			if scopeName, ok := scopeName.(string); ok {
+				scopes[scopeName] = true
+			}
+		}
+	}
+
+	return scopes
+}
+
+// scopes returns the scopes granted by the token. The tokens verified with a configured key that have neither scope
+// nor scopes were issued before the scopes existed, eg: by the notifications scheduler, so they keep sending
+// notifications until their issuers add the scopes
+func (vt verifiedToken) scopes() map[string]bool {
+	scopes := tokenScopes(vt.claims)
+
+	_, hasScope := vt.claims[scopeClaim]
+	_, hasScopes := vt.claims[scopesClaim]
+	if vt.configuredKey && !hasScope && !hasScopes {
+		scopes[ScopeNotificationsSend] = true
+	}
+
+	return scopes
+}
+
+// RequireScope middleware that responds 403 if the access token does not grant the scope. Must be used after
+// AccessControl
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rawScopes, _ := c.Get(scopesKey)
+		scopes, _ := rawScopes.(map[string]bool)
+		if scopes[scope] || scopes[ScopeAdmin] {
+			c.Next()
+			return
+		}
+
+		logrus.Errorf("error access token does not grant %s to %s %s", scope, c.Request.Method, c.FullPath())
+		errResponse := errorResponse{
+			StatusCode: http.StatusForbidden,
+			Message:    fmt.Sprintf("the access token does not grant the %s scope", scope),
+		}
+		c.JSON(errResponse.StatusCode, errResponse)
+		c.Abort()
+	}
+}
@@ -0,0 +1,143 @@
+package sender
+
+import (
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTokenScopes(t *testing.T) {
+	testCases := []struct {
+		Name           string
+		Claims         jwt.MapClaims
+		ExpectedScopes map[string]bool
+	}{
+		{
+			Name:           "Without scopes",
+			Claims:         jwt.MapClaims{},
+			ExpectedScopes: map[string]bool{},
+		},
+		{
+			Name:   "Space separated scopes",
+			Claims: jwt.MapClaims{scopeClaim: "notifications:send  notifications:read"},
+			ExpectedScopes: map[string]bool{
+				ScopeNotificationsSend: true,
+				ScopeNotificationsRead: true,
+			},
+		},
+		{
+			Name:   "List of scopes",
+			Claims: jwt.MapClaims{scopesClaim: []any{ScopeBroadcastSend, 69}},
+			ExpectedScopes: map[string]bool{
+				ScopeBroadcastSend: true,
+			},
+		},
+		{
+			Name:   "Both claims",
+			Claims: jwt.MapClaims{scopeClaim: ScopeAdmin, scopesClaim: []any{ScopeBroadcastSend}},
+			ExpectedScopes: map[string]bool{
+				ScopeAdmin:         true,
+				ScopeBroadcastSend: true,
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			assert.Equal(t, testCase.ExpectedScopes, tokenScopes(testCase.Claims))
+		})
+	}
+}
+
+func TestVerifiedTokenScopes(t *testing.T) {
+	testCases := []struct {
+		Name           string
+		Token          verifiedToken
+		ExpectedScopes map[string]bool
+	}{
+		{
+			Name:           "Configured key without scopes",
+			Token:          verifiedToken{claims: jwt.MapClaims{}, configuredKey: true},
+			ExpectedScopes: map[string]bool{ScopeNotificationsSend: true},
+		},
+		{
+			Name:           "Configured key with scopes",
+			Token:          verifiedToken{claims: jwt.MapClaims{scopeClaim: ScopeNotificationsRead}, configuredKey: true},
+			ExpectedScopes: map[string]bool{ScopeNotificationsRead: true},
+		},
+		{
+			Name:           "Configured key with empty scopes",
+			Token:          verifiedToken{claims: jwt.MapClaims{scopesClaim: []any{}}, configuredKey: true},
+			ExpectedScopes: map[string]bool{},
+		},
+		{
+			Name:           "JWKS without scopes",
+			Token:          verifiedToken{claims: jwt.MapClaims{}},
+			ExpectedScopes: map[string]bool{},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			assert.Equal(t, testCase.ExpectedScopes, testCase.Token.scopes())
+		})
+	}
+}
+
+func TestRequireScope(t *testing.T) {
+	setVerifierEnvs(t, "")
+	verifier, err := newTokenVerifierFromEnv()
+	require.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	notificationsSender := &NotificationsSender{tokenVerifier: verifier}
+	engine.POST(
+		"/telegram/notifications",
+		notificationsSender.AccessControl(),
+		RequireScope(ScopeNotificationsSend),
+		func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		},
+	)
+
+	testCases := []struct {
+		Name               string
+		Scope              string
+		ExpectedStatusCode int
+	}{
+		{Name: "Token grants the scope", Scope: "notifications:read notifications:send", ExpectedStatusCode: http.StatusOK},
+		{Name: "Token grants admin", Scope: ScopeAdmin, ExpectedStatusCode: http.StatusOK},
+		{Name: "Token grants other scopes", Scope: ScopeNotificationsRead, ExpectedStatusCode: http.StatusForbidden},
+		{Name: "Legacy token without scopes", ExpectedStatusCode: http.StatusOK},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			token := signToken(t, jwt.SigningMethodHS256, "", []byte(testSecret), validClaims(func(claims jwt.MapClaims) {
+				if testCase.Scope != "" {
+					claims[scopeClaim] = testCase.Scope
+				}
+			}))
+			request := httptest.NewRequest(http.MethodPost, "/telegram/notifications", nil)
+			request.Header.Set(jwtHeader, token)
+			recorder := httptest.NewRecorder()
+
+			engine.ServeHTTP(recorder, request)
+			require.Equal(t, testCase.ExpectedStatusCode, recorder.Code)
+			if testCase.ExpectedStatusCode != http.StatusForbidden {
+				return
+			}
+
+			var response errorResponse
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+			assert.Equal(t, http.StatusForbidden, response.StatusCode)
+			assert.Contains(t, response.Message, ScopeNotificationsSend)
+		})
+	}
+}
@@ -1,34 +1,139 @@
 package sender
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"net/http"
+	"os"
 	"strconv"
+	"sync"
 	"telegram-bot/internal/bot"
 	"telegram-bot/internal/sender/internal/notification"
+	"telegram-bot/internal/utils/filestore"
+	"time"
+)
+
+const (
+	tracerName = "telegram-bot/internal/sender"
+
+	// maxNotificationsBodySize fits a document of 50 MB, the largest attachment, encoded in base64
+	maxNotificationsBodySize = 100 << 20
+	// maxNotificationsPerRequest the notifications are sent while the request waits, bigger batches must be split
+	maxNotificationsPerRequest = 1000
 )
 
 type NotificationsSender struct {
 	telegramBot *bot.TelegramBot
+	// tokenVerifier if it is nil, the requests cannot be authenticated with access tokens
+	tokenVerifier *tokenVerifier
+	// requestVerifier if it is nil, the requests cannot be authenticated with request signing
+	requestVerifier  *requestVerifier
+	broadcaster      *broadcaster
+	scheduler        *scheduler
+	unreachableChats *unreachableChats
 }
 
-func NewNotificationSender(telegramBot *bot.TelegramBot) *NotificationsSender {
-	return &NotificationsSender{
-		telegramBot: telegramBot,
+// NewNotificationSender returns an error if the config to authenticate the requests is not valid. At least one of
+// the authentication modes must be configured. The broadcasts are sent at BROADCAST_RATE messages per second.
+// The scheduled notifications and the unreachable chats are persisted in the data dir, see filestore
+// The chats that send an update to the bot are reachable again, see bot.TelegramBot.OnUpdateFrom
+func NewNotificationSender(telegramBot *bot.TelegramBot, audienceRequester AudienceRequester) (*NotificationsSender, error) {
+	signedRequestVerifier, err := newRequestVerifierFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	verifier, err := newTokenVerifierFromEnv()
+	if err != nil && !(errors.Is(err, errNoVerificationKeys) && signedRequestVerifier != nil) {
+		return nil, err
+	}
+
+	broadcastRate := defaultBroadcastRate
+	if rawRate := os.Getenv(broadcastRateEnvVar); rawRate != "" {
+		broadcastRate, err = strconv.Atoi(rawRate)
+		if err != nil || broadcastRate <= 0 {
+			return nil, fmt.Errorf("error invalid %s: must be a positive integer", broadcastRateEnvVar)
+		}
+	}
+
+	unreachable, err := newUnreachableChatsFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	ns := &NotificationsSender{
+		telegramBot:      telegramBot,
+		tokenVerifier:    verifier,
+		requestVerifier:  signedRequestVerifier,
+		broadcaster:      newBroadcaster(telegramBot, audienceRequester, unreachable, broadcastRate),
+		unreachableChats: unreachable,
+	}
+	telegramBot.OnUpdateFrom(unreachable.reachable)
+
+	schedulePath := filestore.Path(ScheduleFile)
+	if schedulePath == "" {
+		logrus.Warnf("%s is not set, the notifications with send_at will be rejected", filestore.DataDirEnv)
+	}
+
+	ns.scheduler, err = newScheduler(schedulePath, ns.sendNotification)
+	if err != nil {
+		return nil, fmt.Errorf("error loading scheduled notifications: %w", err)
 	}
+
+	return ns, nil
 }
 
+// Run delivers the broadcasts and the scheduled notifications until the context is done, is a blocking function
+func (ns *NotificationsSender) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ns.scheduler.run(ctx)
+	}()
+
+	ns.broadcaster.run(ctx)
+	wg.Wait()
+}
+
+// summary of a trigger, Errors has the notifications that were neither sent nor scheduled
 type summary struct {
-	OK   int `json:"ok"`
-	Fail int `json:"fail"`
+	OK        int                 `json:"ok"`
+	Scheduled int                 `json:"scheduled,omitempty"`
+	Fail      int                 `json:"fail"`
+	Errors    []notificationError `json:"errors,omitempty"`
 }
 
-// TriggerNotifications sends each notification that receives to the corresponding user. Best effort procedure
+// notificationError why the notification of the request at Index was not sent
+type notificationError struct {
+	Index      int    `json:"index"`
+	TelegramID string `json:"telegram_id"`
+	Error      string `json:"error"`
+}
+
+// TriggerNotifications sends each notification that receives to the corresponding user, the ones with a send_at
+// in the future are scheduled. Best effort procedure. The requests with a body larger than maxNotificationsBodySize
+// or with more than maxNotificationsPerRequest notifications are rejected
 func (ns *NotificationsSender) TriggerNotifications(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxNotificationsBodySize)
+
 	var notifications []notification.Notification
 	err := c.ShouldBindJSON(&notifications)
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		c.JSON(http.StatusRequestEntityTooLarge, errorResponse{
+			StatusCode: http.StatusRequestEntityTooLarge,
+			Message:    fmt.Sprintf("error body is larger than %d bytes", maxBytesErr.Limit),
+		})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusBadRequest, errorResponse{
 			StatusCode: http.StatusBadRequest,
@@ -37,25 +142,144 @@ func (ns *NotificationsSender) TriggerNotifications(c *gin.Context) {
 		return
 	}
 
-	counter := 0
-	for _, notificationToSend := range notifications {
+	if len(notifications) > maxNotificationsPerRequest {
+		c.JSON(http.StatusBadRequest, errorResponse{
+			StatusCode: http.StatusBadRequest,
+			Message:    fmt.Sprintf("error at most %d notifications can be sent per request", maxNotificationsPerRequest),
+		})
+		return
+	}
+
+	notificationBatchSize.Observe(float64(len(notifications)))
+
+	result := summary{}
+	for idx, notificationToSend := range notifications {
 		// Best effort
-		telegramID, err := strconv.Atoi(notificationToSend.TelegramID)
-		if err != nil {
-			logrus.Errorf("error invalid telegramID %s: %v", notificationToSend.TelegramID, err)
-			continue
+		var outcome string
+		if notificationToSend.SendAt != nil && notificationToSend.SendAt.After(ns.scheduler.now()) {
+			outcome, err = ns.scheduleNotification(notificationToSend)
+		} else {
+			outcome, err = ns.sendNotification(c.Request.Context(), notificationToSend)
 		}
+		notificationsProcessed.WithLabelValues(outcome).Inc()
 
-		err = ns.telegramBot.SendNotification(int64(telegramID), notificationToSend.Message)
-		if err != nil {
-			logrus.Errorf("error sending notification, telegram_id: %s: %v", notificationToSend.TelegramID, err)
-			continue
+		switch {
+		case err != nil:
+			result.Errors = append(result.Errors, notificationError{
+				Index:      idx,
+				TelegramID: notificationToSend.TelegramID,
+				Error:      err.Error(),
+			})
+		case outcome == outcomeScheduled:
+			result.Scheduled++
+		default:
+			result.OK++
 		}
-		counter++
+	}
+	result.Fail = len(result.Errors)
+
+	c.JSON(http.StatusOK, result)
+}
+
+// scheduleNotification validates the notification and schedules it to be sent at its SendAt, it returns the
+// outcome and the error if it was not scheduled. The attachments are persisted with the notification, so only the
+// ones sent by URL can be scheduled
+func (ns *NotificationsSender) scheduleNotification(notificationToSend notification.Notification) (string, error) {
+	_, _, outcome, err := parseNotification(notificationToSend)
+	if err != nil {
+		return outcome, err
+	}
+
+	if notificationToSend.Attachment != nil && notificationToSend.Attachment.Data != "" {
+		return outcomeInvalidAttachment, errScheduledAttachmentData
+	}
+
+	scheduledID, err := ns.scheduler.schedule(notificationToSend, *notificationToSend.SendAt)
+	if err != nil {
+		logrus.Errorf("error scheduling notification, telegram_id: %s: %v", notificationToSend.TelegramID, err)
+		return outcomeScheduleError, err
+	}
+
+	logrus.Infof("notification %s scheduled at %s, telegram_id: %s", scheduledID, notificationToSend.SendAt, notificationToSend.TelegramID)
+	return outcomeScheduled, nil
+}
+
+// sendNotification sends the notification to its user within a span, and returns the outcome of the send and
+// the error if it was not sent
+func (ns *NotificationsSender) sendNotification(ctx context.Context, notificationToSend notification.Notification) (string, error) {
+	_, span := otel.Tracer(tracerName).Start(
+		ctx,
+		"SendNotification",
+		trace.WithAttributes(attribute.String("telegram.id", notificationToSend.TelegramID)),
+	)
+	defer span.End()
+
+	telegramID, attachment, outcome, err := parseNotification(notificationToSend)
+	if err != nil {
+		span.SetStatus(codes.Error, outcome)
+		return outcome, err
+	}
+
+	if chat, found := ns.unreachableChats.suppressed(telegramID); found {
+		span.SetStatus(codes.Error, outcomeSuppressed)
+		return outcomeSuppressed, fmt.Errorf(
+			"%w: %s, the notifications are suppressed until %s",
+			errChatUnreachable,
+			chat.Reason,
+			chat.RetryAt.Format(time.RFC3339),
+		)
+	}
+
+	err = ns.telegramBot.SendNotification(telegramID, notificationToSend.Message, attachment)
+	if err != nil {
+		logrus.Errorf("error sending notification, telegram_id: %s: %v", notificationToSend.TelegramID, err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		if reason := bot.UnreachableReason(err); reason != "" {
+			ns.unreachableChats.record(telegramID, reason)
+			return outcomeUnreachable, err
+		}
+		return outcomeSendError, err
+	}
+
+	ns.unreachableChats.remove(telegramID)
+	return outcomeSent, nil
+}
+
+// parseNotification returns the telegram ID and the attachment of the notification. If it is not valid, it
+// returns the outcome of the error
+func parseNotification(notificationToSend notification.Notification) (int64, *bot.NotificationAttachment, string, error) {
+	telegramID, err := strconv.ParseInt(notificationToSend.TelegramID, 10, 64)
+	if err != nil {
+		logrus.Errorf("error invalid telegramID %s: %v", notificationToSend.TelegramID, err)
+		return 0, nil, outcomeInvalidTelegramID, fmt.Errorf("error invalid telegram ID %s", notificationToSend.TelegramID)
+	}
+
+	attachment, err := newNotificationAttachment(notificationToSend.Attachment)
+	if err != nil {
+		logrus.Errorf("error invalid attachment, telegram_id: %s: %v", notificationToSend.TelegramID, err)
+		return 0, nil, outcomeInvalidAttachment, err
+	}
+
+	return telegramID, attachment, "", nil
+}
+
+// newNotificationAttachment validates the attachment of the notification, it returns nil if there is none
+func newNotificationAttachment(attachment *notification.Attachment) (*bot.NotificationAttachment, error) {
+	if attachment == nil {
+		return nil, nil
+	}
+
+	data, err := attachment.Decode()
+	if err != nil {
+		return nil, err
 	}
 
-	c.JSON(http.StatusOK, summary{
-		OK:   counter,
-		Fail: len(notifications) - counter,
-	})
+	return &bot.NotificationAttachment{
+		IsDocument: attachment.Type == notification.AttachmentDocument,
+		URL:        attachment.URL,
+		Data:       data,
+		FileName:   attachment.FileName,
+		Caption:    attachment.Caption,
+	}, nil
 }
@@ -0,0 +1,66 @@
+package sender
+
+import (
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTriggerNotificationsLimits(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	ns := &NotificationsSender{}
+	engine.POST("/telegram/notifications", ns.TriggerNotifications)
+
+	tooManyNotifications := "[" + strings.Repeat(`{"telegram_id": "911", "message": "Hi"},`, maxNotificationsPerRequest) +
+		`{"telegram_id": "911", "message": "Hi"}]`
+
+	testCases := []struct {
+		Name               string
+		Body               string
+		ExpectedStatusCode int
+	}{
+		{
+			Name:               "Body is too large",
+			Body:               `[{"telegram_id": "911", "message": "` + strings.Repeat("a", maxNotificationsBodySize) + `"}]`,
+			ExpectedStatusCode: http.StatusRequestEntityTooLarge,
+		},
+		{
+			Name:               "Too many notifications",
+			Body:               tooManyNotifications,
+			ExpectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			Name:               "Invalid body",
+			Body:               `{"telegram_id": "911"}`,
+			ExpectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			Name:               "Empty list",
+			Body:               `[]`,
+			ExpectedStatusCode: http.StatusOK,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodPost, "/telegram/notifications", strings.NewReader(testCase.Body))
+			recorder := httptest.NewRecorder()
+
+			engine.ServeHTTP(recorder, request)
+			require.Equal(t, testCase.ExpectedStatusCode, recorder.Code)
+			if testCase.ExpectedStatusCode == http.StatusOK {
+				return
+			}
+
+			var response errorResponse
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+			assert.Equal(t, testCase.ExpectedStatusCode, response.StatusCode)
+		})
+	}
+}
@@ -0,0 +1,259 @@
+package sender
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"telegram-bot/internal/utils/filestore"
+	"telegram-bot/internal/utils/urlutils"
+	"time"
+)
+
+const (
+	// UnreachableChatsFile name of the file, in the data dir, where the unreachable chats are persisted
+	UnreachableChatsFile = "unreachable_chats.json"
+
+	unreachableCooldownEnvVar      = "UNREACHABLE_COOLDOWN"
+	unreachableWebhookURLEnvVar    = "UNREACHABLE_WEBHOOK_URL"
+	unreachableWebhookSecretEnvVar = "UNREACHABLE_WEBHOOK_SECRET"
+
+	defaultUnreachableCooldown = 24 * time.Hour
+	unreachableWebhookTimeout  = 5 * time.Second
+	// unreachableWebhookClientID X-Client-ID of the signed webhook requests
+	unreachableWebhookClientID = "telegramer"
+)
+
+var (
+	errInvalidUnreachableConfig = errors.New("error invalid unreachable chats config")
+	errUnreachableChatNotFound  = errors.New("error unreachable chat not found")
+	errChatUnreachable          = errors.New("error chat is unreachable")
+)
+
+// unreachableChat chat to which the messages cannot be delivered, eg: the user blocked the bot
+type unreachableChat struct {
+	TelegramID int64     `json:"telegram_id"`
+	Reason     string    `json:"reason"`
+	DetectedAt time.Time `json:"detected_at"`
+	// RetryAt until then, the notifications to the chat are not sent
+	RetryAt time.Time `json:"retry_at"`
+}
+
+// unreachableChats chats whose sends failed with an error of bot.UnreachableReason. The notifications to them are
+// suppressed for cooldown, after it they are sent again: if the send fails the chat is suppressed for another
+// cooldown, otherwise it is reachable again. A chat is also reachable again when its user sends an update to the
+// bot. If it has a path, the chats are persisted in it. The chats that become
+// unreachable are reported to notify
+type unreachableChats struct {
+	path     string
+	cooldown time.Duration
+	now      func() time.Time
+	notify   func(chat unreachableChat)
+
+	mu    sync.Mutex
+	chats map[int64]unreachableChat
+}
+
+// newUnreachableChatsFromEnv the cooldown is UNREACHABLE_COOLDOWN and, if UNREACHABLE_WEBHOOK_URL is set, the
+// unreachable chats are posted to it. If UNREACHABLE_WEBHOOK_SECRET is set, the webhook requests are signed as the
+// requests of the sender, see signRequest
+func newUnreachableChatsFromEnv() (*unreachableChats, error) {
+	cooldown := defaultUnreachableCooldown
+	if rawCooldown := os.Getenv(unreachableCooldownEnvVar); rawCooldown != "" {
+		var err error
+		cooldown, err = time.ParseDuration(rawCooldown)
+		if err != nil || cooldown <= 0 {
+			return nil, fmt.Errorf("%w: %s must be a positive duration", errInvalidUnreachableConfig, unreachableCooldownEnvVar)
+		}
+	}
+
+	notify := func(unreachableChat) {}
+	if webhookURL := os.Getenv(unreachableWebhookURLEnvVar); webhookURL != "" {
+		if !urlutils.IsHTTPURL(webhookURL) {
+			return nil, fmt.Errorf("%w: %s must be an http or https URL", errInvalidUnreachableConfig, unreachableWebhookURLEnvVar)
+		}
+
+		webhook := newUnreachableWebhook(webhookURL, []byte(os.Getenv(unreachableWebhookSecretEnvVar)))
+		notify = func(chat unreachableChat) {
+			go webhook.notify(chat)
+		}
+	}
+
+	return newUnreachableChats(filestore.Path(UnreachableChatsFile), cooldown, notify)
+}
+
+func newUnreachableChats(path string, cooldown time.Duration, notify func(chat unreachableChat)) (*unreachableChats, error) {
+	var chats []unreachableChat
+	err := filestore.Load(path, &chats)
+	if err != nil {
+		return nil, err
+	}
+
+	uc := &unreachableChats{
+		path:     path,
+		cooldown: cooldown,
+		now:      time.Now,
+		notify:   notify,
+		chats:    make(map[int64]unreachableChat, len(chats)),
+	}
+	for _, chat := range chats {
+		uc.chats[chat.TelegramID] = chat
+	}
+
+	return uc, nil
+}
+
+// suppressed returns the chat if the notifications to it must not be sent yet
+func (uc *unreachableChats) suppressed(telegramID int64) (unreachableChat, bool) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	chat, found := uc.chats[telegramID]
+	if !found || !uc.now().Before(chat.RetryAt) {
+		return unreachableChat{}, false
+	}
+
+	return chat, true
+}
+
+// record suppresses the chat for a cooldown. If it was reachable, it is reported
+func (uc *unreachableChats) record(telegramID int64, reason string) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	now := uc.now()
+	chat, wasUnreachable := uc.chats[telegramID]
+	if !wasUnreachable {
+		chat = unreachableChat{TelegramID: telegramID, DetectedAt: now}
+	}
+	chat.Reason = reason
+	chat.RetryAt = now.Add(uc.cooldown)
+	uc.chats[telegramID] = chat
+	uc.saveLocked()
+
+	if !wasUnreachable {
+		uc.notify(chat)
+	}
+}
+
+// remove makes the chat reachable again, it returns false if it was not unreachable
+func (uc *unreachableChats) remove(telegramID int64) bool {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	if _, found := uc.chats[telegramID]; !found {
+		return false
+	}
+
+	delete(uc.chats, telegramID)
+	uc.saveLocked()
+
+	return true
+}
+
+// reachable removes the chat because its user sent an update to the bot, so the bot can message it again
+func (uc *unreachableChats) reachable(telegramID int64) {
+	if uc.remove(telegramID) {
+		logrus.Infof("chat %d is reachable again, it sent an update", telegramID)
+	}
+}
+
+// list returns the unreachable chats sorted by telegram ID
+func (uc *unreachableChats) list() []unreachableChat {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	return uc.sortedLocked()
+}
+
+func (uc *unreachableChats) sortedLocked() []unreachableChat {
+	chats := make([]unreachableChat, 0, len(uc.chats))
+	for _, chat := range uc.chats {
+		chats = append(chats, chat)
+	}
+	sort.Slice(chats, func(i, j int) bool {
+		return chats[i].TelegramID < chats[j].TelegramID
+	})
+
+	return chats
+}
+
+func (uc *unreachableChats) saveLocked() {
+	err := filestore.Save(uc.path, uc.sortedLocked())
+	if err != nil {
+		logrus.Errorf("error persisting unreachable chats: %v", err)
+	}
+}
+
+// unreachableWebhook posts the unreachable chats to the URL, so the notifications service can disable the
+// reminders of their users
+type unreachableWebhook struct {
+	url    string
+	secret []byte
+	client *http.Client
+}
+
+func newUnreachableWebhook(webhookURL string, secret []byte) *unreachableWebhook {
+	return &unreachableWebhook{
+		url:    webhookURL,
+		secret: secret,
+		client: &http.Client{Timeout: unreachableWebhookTimeout},
+	}
+}
+
+// notify posts the chat to the webhook, best effort: if it fails the error is logged
+func (uw *unreachableWebhook) notify(chat unreachableChat) {
+	err := uw.post(chat)
+	if err != nil {
+		logrus.Errorf("error reporting unreachable chat %d to the webhook: %v", chat.TelegramID, err)
+	}
+}
+
+func (uw *unreachableWebhook) post(chat unreachableChat) error {
+	body, err := json.Marshal(chat)
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequest(http.MethodPost, uw.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	if len(uw.secret) > 0 {
+		parsedURL, err := url.Parse(uw.url)
+		if err != nil {
+			return err
+		}
+
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		nonce := uuid.NewString()
+		signature := signRequest(uw.secret, http.MethodPost, parsedURL.RequestURI(), timestamp, nonce, body)
+		request.Header.Set(clientIDHeader, unreachableWebhookClientID)
+		request.Header.Set(timestampHeader, timestamp)
+		request.Header.Set(nonceHeader, nonce)
+		request.Header.Set(signatureHeader, hex.EncodeToString(signature))
+	}
+
+	response, err := uw.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("webhook responded %d", response.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,38 @@
+package sender
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+const telegramIDParam = "telegramID"
+
+// ListUnreachableChats responds the chats to which the notifications cannot be delivered
+func (ns *NotificationsSender) ListUnreachableChats(c *gin.Context) {
+	c.JSON(http.StatusOK, ns.unreachableChats.list())
+}
+
+// DeleteUnreachableChat makes the chat reachable, so the notifications to it are sent before its cooldown ends.
+// Eg: the user unblocked the bot
+func (ns *NotificationsSender) DeleteUnreachableChat(c *gin.Context) {
+	telegramID, err := strconv.ParseInt(c.Param(telegramIDParam), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse{
+			StatusCode: http.StatusBadRequest,
+			Message:    fmt.Sprintf("error invalid telegram ID %s", c.Param(telegramIDParam)),
+		})
+		return
+	}
+
+	if !ns.unreachableChats.remove(telegramID) {
+		c.JSON(http.StatusNotFound, errorResponse{
+			StatusCode: http.StatusNotFound,
+			Message:    errUnreachableChatNotFound.Error(),
+		})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
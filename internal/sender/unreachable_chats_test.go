@@ -0,0 +1,169 @@
+package sender
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tele "gopkg.in/telebot.v3"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"telegram-bot/internal/bot"
+	"telegram-bot/internal/sender/internal/notification"
+	"telegram-bot/internal/telegramsim"
+	"testing"
+	"time"
+)
+
+func TestUnreachableChats(t *testing.T) {
+	path := filepath.Join(t.TempDir(), UnreachableChatsFile)
+	var notified []unreachableChat
+	chats, err := newUnreachableChats(path, time.Hour, func(chat unreachableChat) {
+		notified = append(notified, chat)
+	})
+	require.NoError(t, err)
+
+	now := time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC)
+	chats.now = func() time.Time {
+		return now
+	}
+
+	_, suppressed := chats.suppressed(911)
+	assert.False(t, suppressed)
+
+	chats.record(911, bot.ReasonBlocked)
+	chat, suppressed := chats.suppressed(911)
+	require.True(t, suppressed)
+	assert.Equal(t, unreachableChat{TelegramID: 911, Reason: bot.ReasonBlocked, DetectedAt: now, RetryAt: now.Add(time.Hour)}, chat)
+	require.Len(t, notified, 1)
+	assert.Equal(t, chat, notified[0])
+
+	// After the cooldown the chat is retried, if it fails again it is suppressed without being reported again
+	now = now.Add(time.Hour)
+	_, suppressed = chats.suppressed(911)
+	assert.False(t, suppressed)
+
+	chats.record(911, bot.ReasonBlocked)
+	chat, suppressed = chats.suppressed(911)
+	require.True(t, suppressed)
+	assert.Equal(t, now.Add(-time.Hour), chat.DetectedAt)
+	assert.Equal(t, now.Add(time.Hour), chat.RetryAt)
+	assert.Len(t, notified, 1)
+
+	chats.record(420, bot.ReasonChatNotFound)
+	restarted, err := newUnreachableChats(path, time.Hour, func(unreachableChat) {})
+	require.NoError(t, err)
+	assert.Equal(t, chats.list(), restarted.list())
+
+	assert.True(t, restarted.remove(911))
+	assert.False(t, restarted.remove(911))
+	require.Len(t, restarted.list(), 1)
+	assert.Equal(t, int64(420), restarted.list()[0].TelegramID)
+}
+
+func TestUnreachableWebhook(t *testing.T) {
+	secret := []byte("webhook-secret")
+	verifier := newRequestVerifier(map[string]signingClient{
+		unreachableWebhookClientID: {secret: secret},
+	}, time.Minute)
+
+	received := make(chan unreachableChat, 1)
+	statusCode := http.StatusNoContent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := verifier.verify(r)
+		assert.NoError(t, err)
+
+		var chat unreachableChat
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&chat))
+		received <- chat
+		w.WriteHeader(statusCode)
+	}))
+	defer server.Close()
+
+	chat := unreachableChat{TelegramID: 911, Reason: bot.ReasonBlocked, DetectedAt: time.Now().UTC().Truncate(time.Second)}
+	webhook := newUnreachableWebhook(server.URL+"/notifications/unreachable?source=telegramer", secret)
+
+	require.NoError(t, webhook.post(chat))
+	assert.Equal(t, chat, <-received)
+
+	statusCode = http.StatusInternalServerError
+	assert.Error(t, webhook.post(chat))
+	<-received
+}
+
+func TestSendNotificationToUnreachableChat(t *testing.T) {
+	telegram := telegramsim.NewServer()
+	t.Cleanup(telegram.Close)
+
+	botInstance, err := tele.NewBot(telegram.Settings())
+	require.NoError(t, err)
+
+	chats, err := newUnreachableChats("", time.Hour, func(unreachableChat) {})
+	require.NoError(t, err)
+	now := time.Now()
+	chats.now = func() time.Time {
+		return now
+	}
+
+	ns := &NotificationsSender{
+		telegramBot:      bot.NewTelegramBot(botInstance, bot.Requesters{}),
+		unreachableChats: chats,
+	}
+	user := tele.User{ID: 911, FirstName: "Lionel"}
+	notificationToSend := notification.Notification{TelegramID: "911", Message: "Give Bachicha its pill"}
+
+	telegram.BlockBot(user)
+	outcome, err := ns.sendNotification(context.Background(), notificationToSend)
+	assert.Equal(t, outcomeUnreachable, outcome)
+	assert.ErrorIs(t, err, tele.ErrBlockedByUser)
+
+	outcome, err = ns.sendNotification(context.Background(), notificationToSend)
+	assert.Equal(t, outcomeSuppressed, outcome)
+	assert.ErrorIs(t, err, errChatUnreachable)
+
+	// Once the cooldown ends, the chat is reachable again if the notification is sent
+	telegram.UnblockBot(user)
+	now = now.Add(time.Hour)
+	outcome, err = ns.sendNotification(context.Background(), notificationToSend)
+	require.NoError(t, err)
+	assert.Equal(t, outcomeSent, outcome)
+	assert.Empty(t, chats.list())
+	assert.Len(t, telegram.SentMessages(), 1)
+}
+
+func TestChatIsReachableAfterSendingAnUpdate(t *testing.T) {
+	setVerifierEnvs(t, "")
+	telegram := telegramsim.NewServer()
+	t.Cleanup(telegram.Close)
+
+	botInstance, err := tele.NewBot(telegram.Settings())
+	require.NoError(t, err)
+
+	telegramBot := bot.NewTelegramBot(botInstance, bot.Requesters{})
+	ns, err := NewNotificationSender(telegramBot, nil)
+	require.NoError(t, err)
+	ns.unreachableChats.record(911, bot.ReasonBlocked)
+	ns.unreachableChats.record(69, bot.ReasonChatNotFound)
+
+	telegramBot.DefineHandlers()
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		telegramBot.StartBot(ctx)
+		close(stopped)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-stopped
+	})
+	require.NoError(t, telegram.WaitForPolling(time.Second))
+
+	telegram.SendText(tele.User{ID: 911, FirstName: "Lionel"}, "/help")
+	_, err = telegram.NextMessage(time.Second)
+	require.NoError(t, err)
+
+	chats := ns.unreachableChats.list()
+	require.Len(t, chats, 1)
+	assert.Equal(t, int64(69), chats[0].TelegramID)
+}
@@ -0,0 +1,70 @@
+package telegramsim
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// readParams reads the parameters of a Bot API call. They are sent as a JSON object or, when files are uploaded,
+// as a multipart form. Non-string values are kept as their raw JSON
+func readParams(r *http.Request) (map[string]string, error) {
+	params := make(map[string]string)
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		err := r.ParseMultipartForm(32 << 20)
+		if err != nil {
+			return nil, err
+		}
+
+		for key, values := range r.MultipartForm.Value {
+			params[key] = values[0]
+		}
+		for key, files := range r.MultipartForm.File {
+			params[key] = files[0].Filename
+		}
+
+		return params, nil
+	}
+
+	var rawParams map[string]json.RawMessage
+	err := json.NewDecoder(r.Body).Decode(&rawParams)
+	if err != nil {
+		// Calls without parameters, eg: getMe, send null
+		return params, nil
+	}
+
+	for key, rawValue := range rawParams {
+		var value string
+		if json.Unmarshal(rawValue, &value) != nil {
+			value = string(rawValue)
+		}
+		params[key] = value
+	}
+
+	return params, nil
+}
+
+// parseCallbackData splits the data of a button created with tele.ReplyMarkup.Data, which has the format
+// \f<unique>|<data>
+func parseCallbackData(data string) (string, string) {
+	unique, payload, _ := strings.Cut(strings.TrimPrefix(data, "\f"), "|")
+	return unique, payload
+}
+
+func writeResult(w http.ResponseWriter, result any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"ok":     true,
+		"result": result,
+	})
+}
+
+func writeError(w http.ResponseWriter, code int, description string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"ok":          false,
+		"error_code":  code,
+		"description": description,
+	})
+}
@@ -0,0 +1,382 @@
+// Package telegramsim simulates the Telegram Bot API, so the bot can be tested end-to-end without Telegram.
+// Updates are injected as if they were sent by users, and every message that the bot sends is recorded
+package telegramsim
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	tele "gopkg.in/telebot.v3"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// Token token of the simulated bot
+	Token = "123456:simulated-token"
+	// maxPollTimeout caps the long polling, so the bot notices soon that the server is closed
+	maxPollTimeout = time.Second
+)
+
+var errTimeout = errors.New("error timeout waiting for the bot")
+
+// SentMessage message sent by the bot
+type SentMessage struct {
+	// Method of the Bot API used to send it, eg: sendMessage, sendPhoto
+	Method    string
+	MessageID int
+	ChatID    int64
+	Text      string
+	// File URL, ID or name of the file sent with the message, if any
+	File        string
+	ReplyMarkup *tele.ReplyMarkup
+}
+
+// Buttons returns all the inline buttons of the message
+func (sm SentMessage) Buttons() []tele.InlineButton {
+	if sm.ReplyMarkup == nil {
+		return nil
+	}
+
+	var buttons []tele.InlineButton
+	for _, row := range sm.ReplyMarkup.InlineKeyboard {
+		buttons = append(buttons, row...)
+	}
+
+	return buttons
+}
+
+// Button returns the first inline button of the message with the given unique
+func (sm SentMessage) Button(unique string) (tele.InlineButton, bool) {
+	for _, button := range sm.Buttons() {
+		if buttonUnique, _ := parseCallbackData(button.Data); buttonUnique == unique {
+			return button, true
+		}
+	}
+
+	return tele.InlineButton{}, false
+}
+
+// Server fake of the Telegram Bot API. The bot must be created with Settings
+type Server struct {
+	*httptest.Server
+
+	mu            sync.Mutex
+	updates       []tele.Update
+	updatesSignal chan struct{}
+	nextUpdateID  int
+	sent          []SentMessage
+	sentSignal    chan struct{}
+	read          int
+	nextMessageID int
+	me            tele.User
+	// webhookURL URL set with setWebhook, empty if the updates are polled
+	webhookURL string
+	// blocked users that blocked the bot, the messages sent to them fail
+	blocked map[int64]bool
+	// polling is closed when the bot polls the updates for the first time
+	polling     chan struct{}
+	pollingOnce sync.Once
+}
+
+func NewServer() *Server {
+	server := &Server{
+		updatesSignal: make(chan struct{}),
+		sentSignal:    make(chan struct{}),
+		nextUpdateID:  1,
+		nextMessageID: 1,
+		blocked:       make(map[int64]bool),
+		polling:       make(chan struct{}),
+		me: tele.User{
+			ID:        123456,
+			IsBot:     true,
+			FirstName: "Ringot",
+			Username:  "pet_place_bot",
+		},
+	}
+	server.Server = httptest.NewServer(http.HandlerFunc(server.handle))
+
+	return server
+}
+
+// Settings returns the settings of a bot that polls the updates from the simulator
+func (s *Server) Settings() tele.Settings {
+	return tele.Settings{
+		URL:       s.URL,
+		Token:     Token,
+		Poller:    &tele.LongPoller{Timeout: maxPollTimeout},
+		ParseMode: tele.ModeMarkdown,
+	}
+}
+
+// BlockBot makes the messages sent to the user fail as if the user blocked the bot
+func (s *Server) BlockBot(user tele.User) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.blocked[user.ID] = true
+}
+
+// UnblockBot undoes BlockBot
+func (s *Server) UnblockBot(user tele.User) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.blocked, user.ID)
+}
+
+// SendText injects a text message of the user, commands are text messages too, eg: /start
+func (s *Server) SendText(user tele.User, text string) {
+	s.pushUpdate(tele.Update{Message: s.newUserMessage(user, func(message *tele.Message) {
+		message.Text = text
+	})})
+}
+
+// SendLocation injects a message of the user sharing its location
+func (s *Server) SendLocation(user tele.User, latitude float32, longitude float32) {
+	s.pushUpdate(tele.Update{Message: s.newUserMessage(user, func(message *tele.Message) {
+		message.Location = &tele.Location{Lat: latitude, Lng: longitude}
+	})})
+}
+
+// PressButton injects the callback of the user pressing an inline button of a message sent by the bot
+func (s *Server) PressButton(user tele.User, message SentMessage, button tele.InlineButton) {
+	s.mu.Lock()
+	callbackID := strconv.Itoa(s.nextUpdateID)
+	s.mu.Unlock()
+
+	s.pushUpdate(tele.Update{Callback: &tele.Callback{
+		ID:     callbackID,
+		Sender: &user,
+		Message: &tele.Message{
+			ID:       message.MessageID,
+			Chat:     &tele.Chat{ID: message.ChatID, Type: tele.ChatPrivate},
+			Text:     message.Text,
+			Unixtime: time.Now().Unix(),
+		},
+		Data: button.Data,
+	}})
+}
+
+// NextMessage returns the oldest message sent by the bot that was not returned yet. If there is none,
+// it waits until the bot sends one or the timeout elapses
+func (s *Server) NextMessage(timeout time.Duration) (SentMessage, error) {
+	deadline := time.After(timeout)
+	for {
+		s.mu.Lock()
+		if s.read < len(s.sent) {
+			message := s.sent[s.read]
+			s.read++
+			s.mu.Unlock()
+			return message, nil
+		}
+		signal := s.sentSignal
+		s.mu.Unlock()
+
+		select {
+		case <-signal:
+		case <-deadline:
+			return SentMessage{}, errTimeout
+		}
+	}
+}
+
+// WaitForPolling waits until the bot polls the updates for the first time or the timeout elapses. Once it polls,
+// the bot has started and it can be stopped
+func (s *Server) WaitForPolling(timeout time.Duration) error {
+	select {
+	case <-s.polling:
+		return nil
+	case <-time.After(timeout):
+		return errTimeout
+	}
+}
+
+// SentMessages returns all the messages sent by the bot
+func (s *Server) SentMessages() []SentMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]SentMessage(nil), s.sent...)
+}
+
+func (s *Server) newUserMessage(user tele.User, fill func(message *tele.Message)) *tele.Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	message := &tele.Message{
+		ID:       s.nextMessageID,
+		Sender:   &user,
+		Chat:     &tele.Chat{ID: user.ID, Type: tele.ChatPrivate, FirstName: user.FirstName, Username: user.Username},
+		Unixtime: time.Now().Unix(),
+	}
+	s.nextMessageID++
+	fill(message)
+
+	return message
+}
+
+func (s *Server) pushUpdate(update tele.Update) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	update.ID = s.nextUpdateID
+	s.nextUpdateID++
+	s.updates = append(s.updates, update)
+
+	close(s.updatesSignal)
+	s.updatesSignal = make(chan struct{})
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	if path.Dir(r.URL.Path) != "/bot"+Token {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	params, err := readParams(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("Bad Request: %v", err))
+		return
+	}
+
+	method := path.Base(r.URL.Path)
+	if strings.HasPrefix(method, "send") && s.isBlocked(params["chat_id"]) {
+		writeError(w, http.StatusForbidden, "Forbidden: bot was blocked by the user")
+		return
+	}
+
+	switch method {
+	case "getMe":
+		writeResult(w, s.me)
+	case "getUpdates":
+		s.pollingOnce.Do(func() { close(s.polling) })
+		writeResult(w, s.getUpdates(r, params))
+	case "getWebhookInfo":
+		s.mu.Lock()
+		webhookURL := s.webhookURL
+		s.mu.Unlock()
+		writeResult(w, map[string]any{"url": webhookURL, "pending_update_count": 0})
+	case "setWebhook", "deleteWebhook":
+		s.mu.Lock()
+		s.webhookURL = params["url"]
+		s.mu.Unlock()
+		writeResult(w, true)
+	case "getChat":
+		chatID, _ := strconv.ParseInt(params["chat_id"], 10, 64)
+		writeResult(w, tele.Chat{ID: chatID, Type: tele.ChatPrivate})
+	case "sendMessage", "editMessageText":
+		writeResult(w, s.recordMessage(method, params, params["text"], ""))
+	case "sendPhoto", "sendDocument", "sendVideo", "sendAudio":
+		file := params["photo"] + params["document"] + params["video"] + params["audio"]
+		writeResult(w, s.recordMessage(method, params, params["caption"], file))
+	default:
+		writeResult(w, true)
+	}
+}
+
+func (s *Server) isBlocked(rawChatID string) bool {
+	chatID, _ := strconv.ParseInt(rawChatID, 10, 64)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.blocked[chatID]
+}
+
+// getUpdates returns the updates from the offset, waiting for new ones if there are none
+func (s *Server) getUpdates(r *http.Request, params map[string]string) []tele.Update {
+	offset, _ := strconv.Atoi(params["offset"])
+	timeout := maxPollTimeout
+	if seconds, err := strconv.Atoi(params["timeout"]); err == nil && time.Duration(seconds)*time.Second < timeout {
+		timeout = time.Duration(seconds) * time.Second
+	}
+
+	deadline := time.After(timeout)
+	for {
+		s.mu.Lock()
+		var updates []tele.Update
+		for _, update := range s.updates {
+			if update.ID >= offset {
+				updates = append(updates, update)
+			}
+		}
+		signal := s.updatesSignal
+		s.mu.Unlock()
+
+		if len(updates) > 0 {
+			return updates
+		}
+
+		select {
+		case <-signal:
+		case <-deadline:
+			return []tele.Update{}
+		case <-r.Context().Done():
+			return []tele.Update{}
+		}
+	}
+}
+
+func (s *Server) recordMessage(method string, params map[string]string, text string, file string) tele.Message {
+	chatID, _ := strconv.ParseInt(params["chat_id"], 10, 64)
+
+	var markup *tele.ReplyMarkup
+	if rawMarkup := params["reply_markup"]; rawMarkup != "" {
+		markup = &tele.ReplyMarkup{}
+		if err := json.Unmarshal([]byte(rawMarkup), markup); err != nil {
+			markup = nil
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	messageID, err := strconv.Atoi(params["message_id"])
+	if err != nil {
+		messageID = s.nextMessageID
+		s.nextMessageID++
+	}
+
+	s.sent = append(s.sent, SentMessage{
+		Method:      method,
+		MessageID:   messageID,
+		ChatID:      chatID,
+		Text:        text,
+		File:        file,
+		ReplyMarkup: markup,
+	})
+	close(s.sentSignal)
+	s.sentSignal = make(chan struct{})
+
+	message := tele.Message{
+		ID:       messageID,
+		Chat:     &tele.Chat{ID: chatID, Type: tele.ChatPrivate},
+		Text:     text,
+		Unixtime: time.Now().Unix(),
+	}
+
+	// telebot reads the sent media from the response, so it must have the file
+	sentFile := tele.File{FileID: fmt.Sprintf("file-%d", messageID)}
+	switch method {
+	case "sendPhoto":
+		message.Photo = &tele.Photo{File: sentFile}
+	case "sendDocument":
+		message.Document = &tele.Document{File: sentFile, FileName: file}
+	case "sendVideo":
+		message.Video = &tele.Video{File: sentFile}
+	case "sendAudio":
+		message.Audio = &tele.Audio{File: sentFile}
+	}
+	if file != "" {
+		message.Text = ""
+		message.Caption = text
+	}
+
+	return message
+}
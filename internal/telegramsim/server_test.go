@@ -0,0 +1,92 @@
+package telegramsim
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tele "gopkg.in/telebot.v3"
+	"testing"
+	"time"
+)
+
+func TestServer(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	bot, err := tele.NewBot(server.Settings())
+	require.NoError(t, err)
+	assert.Equal(t, "pet_place_bot", bot.Me.Username)
+
+	user := tele.User{ID: 911, FirstName: "Lionel"}
+
+	t.Run("Sent messages are recorded with their buttons", func(t *testing.T) {
+		markup := bot.NewMarkup()
+		markup.Inline(markup.Row(markup.Data("Bachicha", "pet-info", "1")))
+
+		_, err := bot.Send(&user, "Select a pet", markup)
+		require.NoError(t, err)
+
+		message, err := server.NextMessage(time.Second)
+		require.NoError(t, err)
+		assert.Equal(t, "sendMessage", message.Method)
+		assert.Equal(t, user.ID, message.ChatID)
+		assert.Equal(t, "Select a pet", message.Text)
+
+		petButton, found := message.Button("pet-info")
+		require.True(t, found)
+		assert.Equal(t, "Bachicha", petButton.Text)
+
+		_, found = message.Button("vaccines")
+		assert.False(t, found)
+	})
+
+	t.Run("Messages to users that blocked the bot fail", func(t *testing.T) {
+		server.BlockBot(user)
+		_, err := bot.Send(&user, "Are you there?")
+		assert.ErrorIs(t, err, tele.ErrBlockedByUser)
+
+		server.UnblockBot(user)
+		_, err = bot.Send(&user, "Welcome back")
+		require.NoError(t, err)
+
+		message, err := server.NextMessage(time.Second)
+		require.NoError(t, err)
+		assert.Equal(t, "Welcome back", message.Text)
+	})
+
+	t.Run("Waiting for a message times out", func(t *testing.T) {
+		_, err := server.NextMessage(10 * time.Millisecond)
+		assert.ErrorIs(t, err, errTimeout)
+	})
+
+	t.Run("Injected updates are handled by the bot", func(t *testing.T) {
+		bot.Handle("/start", func(c tele.Context) error {
+			return c.Send("Hi " + c.Sender().FirstName)
+		})
+		bot.Handle(tele.OnLocation, func(c tele.Context) error {
+			return c.Send("Searching vets")
+		})
+		bot.Handle(&tele.Btn{Unique: "pet-info"}, func(c tele.Context) error {
+			return c.Send("Pet " + c.Data())
+		})
+		go bot.Start()
+		require.NoError(t, server.WaitForPolling(time.Second))
+		defer bot.Stop()
+
+		server.SendText(user, "/start")
+		message, err := server.NextMessage(time.Second)
+		require.NoError(t, err)
+		assert.Equal(t, "Hi Lionel", message.Text)
+
+		server.SendLocation(user, -34.6, -58.4)
+		message, err = server.NextMessage(time.Second)
+		require.NoError(t, err)
+		assert.Equal(t, "Searching vets", message.Text)
+
+		sentMessages := server.SentMessages()
+		petButton, _ := sentMessages[0].Button("pet-info")
+		server.PressButton(user, sentMessages[0], petButton)
+		message, err = server.NextMessage(time.Second)
+		require.NoError(t, err)
+		assert.Equal(t, "Pet 1", message.Text)
+	})
+}
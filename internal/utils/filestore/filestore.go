@@ -0,0 +1,81 @@
+// Package filestore persists the local state of telegramer as JSON files, so it survives restarts
+package filestore
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// DataDirEnv env var with the directory of the local state. If it is not set, the state is only kept in memory
+const DataDirEnv = "DATA_DIR"
+
+// Path returns the path of the file in the DataDirEnv directory, or an empty path if it is not set
+func Path(fileName string) string {
+	dataDir := os.Getenv(DataDirEnv)
+	if dataDir == "" {
+		return ""
+	}
+
+	return filepath.Join(dataDir, fileName)
+}
+
+// Load unmarshals the file into value. If the path is empty or the file does not exist, value is not modified
+func Load(path string, value any) error {
+	if path == "" {
+		return nil
+	}
+
+	rawValue, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	err = json.Unmarshal(rawValue, value)
+	if err != nil {
+		return fmt.Errorf("error unmarshalling %s: %v", path, err)
+	}
+
+	return nil
+}
+
+// Save marshals the value into the file. It is written to a temporary file that replaces the previous one, so a
+// crash in the middle of the write does not corrupt it. If the path is empty, nothing is saved
+func Save(path string, value any) error {
+	if path == "" {
+		return nil
+	}
+
+	rawValue, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), 0o755)
+	if err != nil {
+		return err
+	}
+
+	tempFile, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = os.Remove(tempFile.Name())
+	}()
+
+	_, err = tempFile.Write(rawValue)
+	if closeErr := tempFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tempFile.Name(), path)
+}
@@ -0,0 +1,53 @@
+package filestore
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type testState struct {
+	Users []int64 `json:"users"`
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "users.json")
+
+	t.Run("File does not exist", func(t *testing.T) {
+		state := testState{Users: []int64{420}}
+		require.NoError(t, Load(path, &state))
+		assert.Equal(t, []int64{420}, state.Users)
+	})
+
+	t.Run("Saved state is loaded", func(t *testing.T) {
+		require.NoError(t, Save(path, testState{Users: []int64{911, 69}}))
+
+		var state testState
+		require.NoError(t, Load(path, &state))
+		assert.Equal(t, []int64{911, 69}, state.Users)
+
+		entries, err := os.ReadDir(filepath.Dir(path))
+		require.NoError(t, err)
+		assert.Len(t, entries, 1, "temporary files must be removed")
+	})
+
+	t.Run("Invalid file", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(path, []byte("salchicha"), 0o600))
+		assert.Error(t, Load(path, &testState{}))
+	})
+
+	t.Run("Empty path", func(t *testing.T) {
+		assert.NoError(t, Save("", testState{}))
+		assert.NoError(t, Load("", &testState{}))
+	})
+}
+
+func TestPath(t *testing.T) {
+	t.Setenv(DataDirEnv, "")
+	assert.Empty(t, Path("users.json"))
+
+	t.Setenv(DataDirEnv, "/var/lib/telegramer")
+	assert.Equal(t, "/var/lib/telegramer/users.json", Path("users.json"))
+}
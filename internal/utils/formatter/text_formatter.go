@@ -1,10 +1,14 @@
 package formatter
 
 import (
+	"errors"
 	"fmt"
 	"strings"
+	"unicode/utf8"
 )
 
+var errInvalidMarkdown = errors.New("error invalid Markdown")
+
 func Bold(text string) string {
 	return fmt.Sprintf("**%s**", text)
 }
@@ -56,3 +60,60 @@ func UnderlineText(text string) string {
 func SpoilerText(text string) string {
 	return fmt.Sprintf("||%s||", text)
 }
+
+// EscapeMarkdown escapes the characters that have a meaning in Markdown, so the text is sent as is.
+// Eg: commands or emails with underscores
+func EscapeMarkdown(text string) string {
+	return markdownEscaper.Replace(text)
+}
+
+var markdownEscaper = strings.NewReplacer("_", `\_`, "*", `\*`, "`", "\\`", "[", `\[`)
+
+// ValidateMarkdown returns an error if the text has an entity that is not closed, Telegram rejects the messages
+// with them. Outside the entities, the characters can be escaped with a backslash, eg: \_
+func ValidateMarkdown(text string) error {
+	for i := 0; i < len(text); i++ {
+		var end int
+		switch {
+		case text[i] == '\\':
+			i++
+			continue
+		case strings.HasPrefix(text[i:], "```"):
+			end = closingIndex(text, i+3, "```")
+			if end != -1 {
+				end += 2
+			}
+		case text[i] == '*' || text[i] == '_' || text[i] == '`':
+			end = closingIndex(text, i+1, text[i:i+1])
+		case text[i] == '[':
+			end = closingIndex(text, i+1, "]")
+			if end != -1 && strings.HasPrefix(text[end+1:], "(") {
+				end = closingIndex(text, end+2, ")")
+			}
+		default:
+			continue
+		}
+
+		if end == -1 {
+			return fmt.Errorf(
+				"%w: %c at character %d is not closed",
+				errInvalidMarkdown,
+				text[i],
+				utf8.RuneCountInString(text[:i])+1,
+			)
+		}
+		i = end
+	}
+
+	return nil
+}
+
+// closingIndex returns the index of the first delimiter of the text from start, or -1 if there is none
+func closingIndex(text string, start int, delimiter string) int {
+	end := strings.Index(text[start:], delimiter)
+	if end == -1 {
+		return -1
+	}
+
+	return start + end
+}
@@ -122,3 +122,72 @@ func TestEllipseText(t *testing.T) {
 		})
 	}
 }
+
+func TestEscapeMarkdown(t *testing.T) {
+	text := "/admin_user sent *bold* `code` [link](url) to lionel_messi@pet.place"
+	expectedResult := `/admin\_user sent \*bold\* ` + "\\`code\\`" + ` \[link](url) to lionel\_messi@pet.place`
+	assert.Equal(t, expectedResult, EscapeMarkdown(text))
+}
+
+func TestValidateMarkdown(t *testing.T) {
+	testCases := []struct {
+		Name          string
+		Text          string
+		ExpectedError string
+	}{
+		{
+			Name: "Plain text",
+			Text: "Rabies vaccination week",
+		},
+		{
+			Name: "Closed entities",
+			Text: "*Pet Place* is _turning_ 1! `code` ```pre *not bold``` [Celebrate](https://pet.place/birthday) [info]",
+		},
+		{
+			Name: "Escaped characters",
+			Text: `/admin\_user \*not bold \[not a link \` + "`",
+		},
+		{
+			Name: "Text formatted by the bot",
+			Text: Bold("Bachicha") + " " + Italic("dog") + " " + Link("Vet", "https://pet.place"),
+		},
+		{
+			Name:          "Bold is not closed",
+			Text:          "Vacunas para *todos",
+			ExpectedError: "* at character 14 is not closed",
+		},
+		{
+			Name:          "Underscore of a word",
+			Text:          "Ñandú lionel_messi",
+			ExpectedError: "_ at character 13 is not closed",
+		},
+		{
+			Name:          "Pre is not closed",
+			Text:          "```pre",
+			ExpectedError: "` at character 1 is not closed",
+		},
+		{
+			Name:          "Link is not closed",
+			Text:          "[Celebrate](https://pet.place",
+			ExpectedError: "[ at character 1 is not closed",
+		},
+		{
+			Name:          "Code is not closed",
+			Text:          "`code",
+			ExpectedError: "` at character 1 is not closed",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			err := ValidateMarkdown(testCase.Text)
+			if testCase.ExpectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.ErrorIs(t, err, errInvalidMarkdown)
+			assert.ErrorContains(t, err, testCase.ExpectedError)
+		})
+	}
+}
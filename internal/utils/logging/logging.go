@@ -0,0 +1,82 @@
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"strings"
+)
+
+const (
+	// RequestIDHeader header used to propagate the request ID to the services
+	RequestIDHeader = "X-Request-ID"
+
+	// Field names of the log entries
+	RequestIDField = "request_id"
+	UpdateIDField  = "update_id"
+	ChatIDField    = "chat_id"
+	CommandField   = "command"
+	TraceIDField   = "trace_id"
+
+	FormatText = "text"
+	FormatJSON = "json"
+
+	timestampFormat = "2006-01-02 15:04:05"
+)
+
+type contextKey int
+
+const (
+	loggerKey contextKey = iota
+	requestIDKey
+)
+
+// NewRequestID generates a random ID to correlate the logs and the requests of an operation
+func NewRequestID() string {
+	id := make([]byte, 8)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// WithRequestID returns a copy of the context that carries the request ID
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// RequestID returns the request ID of the context, or an empty string if it does not have one
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey).(string)
+	return requestID
+}
+
+// WithLogger returns a copy of the context that carries the logger
+func WithLogger(ctx context.Context, logger *logrus.Entry) context.Context {
+	return context.WithValue(ctx, loggerKey, logger)
+}
+
+// FromContext returns the logger of the context. If it does not have one, the standard logger is returned
+func FromContext(ctx context.Context) *logrus.Entry {
+	logger, ok := ctx.Value(loggerKey).(*logrus.Entry)
+	if !ok {
+		return logrus.NewEntry(logrus.StandardLogger())
+	}
+
+	return logger
+}
+
+// NewFormatter returns the logrus formatter for the given format: text or json
+func NewFormatter(format string) (logrus.Formatter, error) {
+	switch strings.ToLower(format) {
+	case "", FormatText:
+		return &logrus.TextFormatter{
+			TimestampFormat: timestampFormat,
+			FullTimestamp:   false,
+		}, nil
+	case FormatJSON:
+		return &logrus.JSONFormatter{TimestampFormat: timestampFormat}, nil
+	default:
+		return nil, fmt.Errorf("invalid log format %s, must be %s or %s", format, FormatText, FormatJSON)
+	}
+}
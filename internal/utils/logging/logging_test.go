@@ -0,0 +1,50 @@
+package logging
+
+import (
+	"context"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestContextLogger(t *testing.T) {
+	ctx := context.Background()
+	assert.Empty(t, RequestID(ctx))
+	assert.Equal(t, logrus.StandardLogger(), FromContext(ctx).Logger)
+
+	requestID := NewRequestID()
+	assert.Len(t, requestID, 16)
+	assert.NotEqual(t, requestID, NewRequestID())
+
+	logger := logrus.WithField(RequestIDField, requestID)
+	ctx = WithLogger(WithRequestID(ctx, requestID), logger)
+	assert.Equal(t, requestID, RequestID(ctx))
+	assert.Equal(t, logger, FromContext(ctx))
+}
+
+func TestNewFormatter(t *testing.T) {
+	testCases := []struct {
+		Format            string
+		ExpectedFormatter logrus.Formatter
+		ExpectsError      bool
+	}{
+		{Format: "", ExpectedFormatter: &logrus.TextFormatter{}},
+		{Format: FormatText, ExpectedFormatter: &logrus.TextFormatter{}},
+		{Format: "JSON", ExpectedFormatter: &logrus.JSONFormatter{}},
+		{Format: "xml", ExpectsError: true},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Format, func(t *testing.T) {
+			formatter, err := NewFormatter(testCase.Format)
+			if testCase.ExpectsError {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.IsType(t, testCase.ExpectedFormatter, formatter)
+		})
+	}
+}
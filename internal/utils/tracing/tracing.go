@@ -0,0 +1,82 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"os"
+	"strings"
+)
+
+const (
+	// ExporterEnv env var that selects where the spans are exported: otlp, stdout or none
+	ExporterEnv = "OTEL_TRACES_EXPORTER"
+
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+	ExporterNone   = "none"
+
+	serviceName = "telegramer"
+)
+
+// Init configures the global tracer provider with the exporter selected in ExporterEnv, by default spans are not
+// exported. The OTLP exporter sends the spans over HTTP to the collector configured with the standard
+// OTEL_EXPORTER_OTLP_* env vars, eg: OTEL_EXPORTER_OTLP_ENDPOINT. The trace context is propagated with the
+// W3C headers. Returns a function that flushes the pending spans and stops the exporter
+func Init(ctx context.Context) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	exporter, err := newExporter(ctx, os.Getenv(ExporterEnv))
+	if err != nil {
+		return nil, err
+	}
+
+	if exporter == nil {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	serviceResource, err := resource.Merge(
+		resource.Default(),
+		resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(serviceName)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error creating tracing resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(serviceResource),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+// newExporter returns nil if the spans must not be exported
+func newExporter(ctx context.Context, exporterName string) (sdktrace.SpanExporter, error) {
+	switch strings.ToLower(exporterName) {
+	case "", ExporterNone:
+		return nil, nil
+	case ExporterOTLP:
+		return otlptracehttp.New(ctx)
+	case ExporterStdout:
+		return stdouttrace.New(stdouttrace.WithPrettyPrint())
+	default:
+		return nil, fmt.Errorf(
+			"invalid traces exporter %s, must be %s, %s or %s",
+			exporterName,
+			ExporterOTLP,
+			ExporterStdout,
+			ExporterNone,
+		)
+	}
+}
@@ -0,0 +1,74 @@
+package tracing
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestInit(t *testing.T) {
+	testCases := []struct {
+		Exporter     string
+		ExpectsError bool
+	}{
+		{Exporter: ""},
+		{Exporter: ExporterNone},
+		{Exporter: ExporterStdout},
+		{Exporter: "jaeger", ExpectsError: true},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Exporter, func(t *testing.T) {
+			t.Setenv(ExporterEnv, testCase.Exporter)
+
+			shutdown, err := Init(context.Background())
+			if testCase.ExpectsError {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.NoError(t, shutdown(context.Background()))
+		})
+	}
+}
+
+func TestGinMiddleware(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(GinMiddleware())
+	engine.POST("/telegram/notifications", func(c *gin.Context) {
+		c.Status(http.StatusInternalServerError)
+	})
+
+	parentTraceID := "4bf92f3577b34da6a3ce929d0e0e4736"
+	request := httptest.NewRequest(http.MethodPost, "/telegram/notifications", nil)
+	request.Header.Set("traceparent", "00-"+parentTraceID+"-00f067aa0ba902b7-01")
+	engine.ServeHTTP(httptest.NewRecorder(), request)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "POST /telegram/notifications", spans[0].Name())
+	assert.Equal(t, parentTraceID, spans[0].SpanContext().TraceID().String())
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.Contains(t, spans[0].Attributes(), semconv.HTTPResponseStatusCode(http.StatusInternalServerError))
+}
@@ -3,6 +3,7 @@ package urlutils
 import (
 	"fmt"
 	"net/http"
+	"net/url"
 	"regexp"
 )
 
@@ -25,3 +26,9 @@ func AddQueryParams(request *http.Request, queryParams map[string]string) {
 	}
 	request.URL.RawQuery = queryParamsValues.Encode()
 }
+
+// IsHTTPURL returns true if the given URL is absolute and its scheme is http or https
+func IsHTTPURL(rawURL string) bool {
+	parsedURL, err := url.Parse(rawURL)
+	return err == nil && (parsedURL.Scheme == "http" || parsedURL.Scheme == "https") && parsedURL.Host != ""
+}
@@ -50,3 +50,11 @@ func TestSarasa(t *testing.T) {
 
 	fmt.Printf("%s", request.URL.String())
 }
+
+func TestIsHTTPURL(t *testing.T) {
+	assert.True(t, IsHTTPURL("https://pet.place/cake.jpg"))
+	assert.True(t, IsHTTPURL("http://localhost:8080"))
+	assert.False(t, IsHTTPURL("ftp://pet.place/cake.jpg"))
+	assert.False(t, IsHTTPURL("/cake.jpg"))
+	assert.False(t, IsHTTPURL("https://"))
+}
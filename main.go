@@ -1,14 +1,24 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
 	"os"
+	"os/signal"
+	"syscall"
+	"telegram-bot/internal/utils/logging"
+	"telegram-bot/internal/utils/tracing"
 	"telegram-bot/src/app"
+	"time"
 )
 
-const logLevelEnv = "LOG_LEVEL"
+const (
+	logLevelEnv            = "LOG_LEVEL"
+	logFormatEnv           = "LOG_FORMAT"
+	tracingShutdownTimeout = 5 * time.Second
+)
 
 func main() {
 	logLevel := os.Getenv(logLevelEnv)
@@ -16,11 +26,24 @@ func main() {
 		logLevel = "DEBUG"
 	}
 
-	err := initLogger(logLevel)
+	err := initLogger(logLevel, os.Getenv(logFormatEnv))
 	if err != nil {
 		fmt.Printf("error initializing logger: %v", err)
 	}
 
+	shutdownTracing, err := tracing.Init(context.Background())
+	if err != nil {
+		logrus.Errorf("error initializing tracing: %v", err)
+		return
+	}
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), tracingShutdownTimeout)
+		defer cancel()
+		if err := shutdownTracing(shutdownCtx); err != nil {
+			logrus.Errorf("error flushing spans: %v", err)
+		}
+	}()
+
 	telegramer, err := app.NewApp()
 	if err != nil {
 		logrus.Errorf("error creating app: %v", err)
@@ -33,22 +56,29 @@ func main() {
 
 	logrus.Infoln("telegramer initialized correctly, lets get ready to rumble")
 
-	err = telegramer.Run(defaultEngine)
-	logrus.Errorf("I'm gonna die %v", err)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	err = telegramer.Run(ctx, defaultEngine)
+	if err != nil {
+		logrus.Errorf("I'm gonna die %v", err)
+		return
+	}
+
+	logrus.Info("telegramer stopped, see you later alligator")
 }
 
-// initLogger Receives the log level to be set in logrus as a string. This method
-// parses the string and set the level to the logger. If the level string is not
-// valid an error is returned
-func initLogger(logLevel string) error {
+// initLogger Receives the log level and the log format (text or json) to be set in logrus as strings. This method
+// parses the strings and set them to the logger. If the level or the format are not valid an error is returned
+func initLogger(logLevel string, logFormat string) error {
 	level, err := logrus.ParseLevel(logLevel)
 	if err != nil {
 		return err
 	}
 
-	customFormatter := &logrus.TextFormatter{
-		TimestampFormat: "2006-01-02 15:04:05",
-		FullTimestamp:   false,
+	customFormatter, err := logging.NewFormatter(logFormat)
+	if err != nil {
+		return err
 	}
 	logrus.SetFormatter(customFormatter)
 	logrus.SetLevel(level)
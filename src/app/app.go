@@ -1,31 +1,41 @@
 package app
 
 import (
+	"context"
 	"fmt"
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/sirupsen/logrus"
 	tele "gopkg.in/telebot.v3"
 	"net/http"
 	"os"
 	"telegram-bot/internal/bot"
+	"telegram-bot/internal/health"
 	"telegram-bot/internal/requester"
 	"telegram-bot/internal/sender"
+	"telegram-bot/internal/utils/filestore"
+	"telegram-bot/internal/utils/tracing"
 	"time"
 )
 
 const (
-	tokenKey      = "TELEGRAM_BOT_TOKEN"
-	senderPortKey = "SENDER_PORT"
+	tokenKey         = "TELEGRAM_BOT_TOKEN"
+	senderPortKey    = "SENDER_PORT"
+	shutdownTimeout  = 10 * time.Second
+	readinessTimeout = 3 * time.Second
 )
 
 type notificationSender interface {
 	RegisterRoutes(r *gin.Engine)
 	TriggerNotifications(c *gin.Context)
+	Run(ctx context.Context)
 }
 
 type App struct {
 	telegramBot         *bot.TelegramBot
 	notificationsSender notificationSender
+	serviceRequester    *requester.Requester
+	healthChecker       *health.Checker
 }
 
 func NewApp() (*App, error) {
@@ -38,6 +48,7 @@ func NewApp() (*App, error) {
 		Token:     botToken,
 		Poller:    &tele.LongPoller{Timeout: 10 * time.Second},
 		ParseMode: tele.ModeMarkdown,
+		Client:    bot.InstrumentClient(&http.Client{Timeout: time.Minute}),
 	}
 
 	botInstance, err := tele.NewBot(botSettings)
@@ -51,24 +62,83 @@ func NewApp() (*App, error) {
 		return nil, err
 	}
 
-	telegramBot := bot.NewTelegramBot(botInstance, serviceRequester)
+	telegramBot := bot.NewTelegramBot(botInstance, bot.NewRequesters(serviceRequester))
+	err = telegramBot.PersistKnownUsers(filestore.Path(bot.KnownUsersFile))
+	if err != nil {
+		return nil, err
+	}
+
+	err = telegramBot.PersistNextDoseReminders(filestore.Path(bot.NextDoseRemindersFile))
+	if err != nil {
+		return nil, err
+	}
+
+	notificationsSender, err := sender.NewNotificationSender(telegramBot, serviceRequester)
+	if err != nil {
+		return nil, err
+	}
+
+	adminIDs, err := bot.AdminIDsFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	healthChecker := newHealthChecker(telegramBot, serviceRequester)
+	telegramBot.SetAdmin(bot.AdminConfig{
+		TelegramIDs: adminIDs,
+		Broadcaster: notificationsSender,
+		Health:      healthChecker,
+	})
 
 	return &App{
 		telegramBot:         telegramBot,
-		notificationsSender: sender.NewNotificationSender(telegramBot),
+		notificationsSender: notificationsSender,
+		serviceRequester:    serviceRequester,
+		healthChecker:       healthChecker,
 	}, nil
 }
 
+// newHealthChecker checks that Telegram can be reached, that the bot is polling updates and that each service
+// of the requester config can be reached
+func newHealthChecker(telegramBot *bot.TelegramBot, serviceRequester *requester.Requester) *health.Checker {
+	checker := health.NewChecker(readinessTimeout)
+	checker.AddCheck("telegram", telegramBot.CheckTelegram)
+	checker.AddCheck("poller", telegramBot.CheckPoller)
+	for _, serviceName := range serviceRequester.ServiceNames() {
+		checker.AddCheck(serviceName, func(ctx context.Context) error {
+			return serviceRequester.PingService(ctx, serviceName)
+		})
+	}
+
+	return checker
+}
+
 func (a *App) RegisterRoutes(r *gin.Engine) {
+	// The probes are registered before the tracing middleware, so they are not traced
+	a.healthChecker.RegisterRoutes(r)
+	r.Use(tracing.GinMiddleware())
 	a.telegramBot.DefineHandlers()
 	a.notificationsSender.RegisterRoutes(r)
+	r.GET("/metrics", gin.WrapH(promhttp.Handler()))
 }
 
-func (a *App) Run(r *gin.Engine) error {
-	errChannel := make(chan error, 1)
+// Run starts the bot and the notifications sender. When the given context is done, both are shut down gracefully.
+// While running, the config of the requester is reloaded when it changes
+func (a *App) Run(ctx context.Context, r *gin.Engine) error {
+	go func() {
+		err := a.serviceRequester.WatchConfig(ctx)
+		if err != nil {
+			logrus.Errorf("error watching requester config, it will not be reloaded: %v", err)
+		}
+	}()
+
+	go a.notificationsSender.Run(ctx)
+
+	botStopped := make(chan struct{})
 	go func() {
 		logrus.Info("Starting bot")
-		a.telegramBot.StartBot()
+		a.telegramBot.StartBot(ctx)
+		close(botStopped)
 	}()
 
 	port := os.Getenv(senderPortKey)
@@ -76,11 +146,37 @@ func (a *App) Run(r *gin.Engine) error {
 		logrus.Info("Using default port (8080) for notification sender")
 		port = "8080"
 	}
+
+	server := &http.Server{
+		Addr:    fmt.Sprintf(":%s", port),
+		Handler: r,
+	}
+
+	errChannel := make(chan error, 1)
 	go func() {
 		logrus.Info("Starting notification sender")
-		errChannel <- r.Run(fmt.Sprintf(":%s", port))
+		errChannel <- server.ListenAndServe()
 	}()
 
-	err := <-errChannel
-	return err
+	select {
+	case err := <-errChannel:
+		return err
+	case <-ctx.Done():
+	}
+
+	logrus.Info("Shutting down telegramer")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	err := server.Shutdown(shutdownCtx)
+	if err != nil {
+		return err
+	}
+
+	select {
+	case <-botStopped:
+		return nil
+	case <-shutdownCtx.Done():
+		return shutdownCtx.Err()
+	}
 }